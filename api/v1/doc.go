@@ -0,0 +1,5 @@
+// Package apiv1 holds the gRPC services mirroring the routes of the API,
+// generated from the protobuf files in this directory.
+package apiv1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative finance.proto programming.proto
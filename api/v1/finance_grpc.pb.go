@@ -0,0 +1,109 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.4
+// source: finance.proto
+
+package apiv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// FinanceServiceClient is the client API for FinanceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FinanceServiceClient interface {
+	// ConvertCurrency converts an amount between currencies, like
+	// GET /v1/finance/currconv
+	ConvertCurrency(ctx context.Context, in *ConvertCurrencyRequest, opts ...grpc.CallOption) (*ConvertCurrencyResponse, error)
+}
+
+type financeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFinanceServiceClient(cc grpc.ClientConnInterface) FinanceServiceClient {
+	return &financeServiceClient{cc}
+}
+
+func (c *financeServiceClient) ConvertCurrency(ctx context.Context, in *ConvertCurrencyRequest, opts ...grpc.CallOption) (*ConvertCurrencyResponse, error) {
+	out := new(ConvertCurrencyResponse)
+	err := c.cc.Invoke(ctx, "/learninggoapi.v1.FinanceService/ConvertCurrency", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FinanceServiceServer is the server API for FinanceService service.
+// All implementations must embed UnimplementedFinanceServiceServer
+// for forward compatibility
+type FinanceServiceServer interface {
+	// ConvertCurrency converts an amount between currencies, like
+	// GET /v1/finance/currconv
+	ConvertCurrency(context.Context, *ConvertCurrencyRequest) (*ConvertCurrencyResponse, error)
+	mustEmbedUnimplementedFinanceServiceServer()
+}
+
+// UnimplementedFinanceServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedFinanceServiceServer struct {
+}
+
+func (UnimplementedFinanceServiceServer) ConvertCurrency(context.Context, *ConvertCurrencyRequest) (*ConvertCurrencyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConvertCurrency not implemented")
+}
+func (UnimplementedFinanceServiceServer) mustEmbedUnimplementedFinanceServiceServer() {}
+
+// UnsafeFinanceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FinanceServiceServer will
+// result in compilation errors.
+type UnsafeFinanceServiceServer interface {
+	mustEmbedUnimplementedFinanceServiceServer()
+}
+
+func RegisterFinanceServiceServer(s grpc.ServiceRegistrar, srv FinanceServiceServer) {
+	s.RegisterService(&FinanceService_ServiceDesc, srv)
+}
+
+func _FinanceService_ConvertCurrency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertCurrencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FinanceServiceServer).ConvertCurrency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/learninggoapi.v1.FinanceService/ConvertCurrency",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FinanceServiceServer).ConvertCurrency(ctx, req.(*ConvertCurrencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FinanceService_ServiceDesc is the grpc.ServiceDesc for FinanceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FinanceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "learninggoapi.v1.FinanceService",
+	HandlerType: (*FinanceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ConvertCurrency",
+			Handler:    _FinanceService_ConvertCurrency_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "finance.proto",
+}
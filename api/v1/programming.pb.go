@@ -0,0 +1,361 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.4
+// source: programming.proto
+
+package apiv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type NewUuidRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Removes the hyphens from the UUID
+	NoHyphens bool `protobuf:"varint,1,opt,name=no_hyphens,json=noHyphens,proto3" json:"no_hyphens,omitempty"`
+}
+
+func (x *NewUuidRequest) Reset() {
+	*x = NewUuidRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_programming_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NewUuidRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewUuidRequest) ProtoMessage() {}
+
+func (x *NewUuidRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_programming_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewUuidRequest.ProtoReflect.Descriptor instead.
+func (*NewUuidRequest) Descriptor() ([]byte, []int) {
+	return file_programming_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *NewUuidRequest) GetNoHyphens() bool {
+	if x != nil {
+		return x.NoHyphens
+	}
+	return false
+}
+
+type NewUuidResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uuid string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+}
+
+func (x *NewUuidResponse) Reset() {
+	*x = NewUuidResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_programming_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NewUuidResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewUuidResponse) ProtoMessage() {}
+
+func (x *NewUuidResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_programming_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewUuidResponse.ProtoReflect.Descriptor instead.
+func (*NewUuidResponse) Descriptor() ([]byte, []int) {
+	return file_programming_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NewUuidResponse) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+type DebugJwtRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The encoded JWT
+	Token string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+}
+
+func (x *DebugJwtRequest) Reset() {
+	*x = DebugJwtRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_programming_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DebugJwtRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DebugJwtRequest) ProtoMessage() {}
+
+func (x *DebugJwtRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_programming_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DebugJwtRequest.ProtoReflect.Descriptor instead.
+func (*DebugJwtRequest) Descriptor() ([]byte, []int) {
+	return file_programming_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DebugJwtRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type DebugJwtResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Header  string `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	Payload string `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (x *DebugJwtResponse) Reset() {
+	*x = DebugJwtResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_programming_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DebugJwtResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DebugJwtResponse) ProtoMessage() {}
+
+func (x *DebugJwtResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_programming_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DebugJwtResponse.ProtoReflect.Descriptor instead.
+func (*DebugJwtResponse) Descriptor() ([]byte, []int) {
+	return file_programming_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DebugJwtResponse) GetHeader() string {
+	if x != nil {
+		return x.Header
+	}
+	return ""
+}
+
+func (x *DebugJwtResponse) GetPayload() string {
+	if x != nil {
+		return x.Payload
+	}
+	return ""
+}
+
+var File_programming_proto protoreflect.FileDescriptor
+
+var file_programming_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x10, 0x6c, 0x65, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x67, 0x6f, 0x61,
+	0x70, 0x69, 0x2e, 0x76, 0x31, 0x22, 0x2f, 0x0a, 0x0e, 0x4e, 0x65, 0x77, 0x55, 0x75, 0x69, 0x64,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x6f, 0x5f, 0x68, 0x79,
+	0x70, 0x68, 0x65, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x6e, 0x6f, 0x48,
+	0x79, 0x70, 0x68, 0x65, 0x6e, 0x73, 0x22, 0x25, 0x0a, 0x0f, 0x4e, 0x65, 0x77, 0x55, 0x75, 0x69,
+	0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x75, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x75, 0x69, 0x64, 0x22, 0x27, 0x0a,
+	0x0f, 0x44, 0x65, 0x62, 0x75, 0x67, 0x4a, 0x77, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x44, 0x0a, 0x10, 0x44, 0x65, 0x62, 0x75, 0x67, 0x4a,
+	0x77, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x32, 0xb7, 0x01, 0x0a,
+	0x12, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x61, 0x6d, 0x6d, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x4e, 0x0a, 0x07, 0x4e, 0x65, 0x77, 0x55, 0x75, 0x69, 0x64, 0x12, 0x20,
+	0x2e, 0x6c, 0x65, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x67, 0x6f, 0x61, 0x70, 0x69, 0x2e, 0x76,
+	0x31, 0x2e, 0x4e, 0x65, 0x77, 0x55, 0x75, 0x69, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x21, 0x2e, 0x6c, 0x65, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x67, 0x6f, 0x61, 0x70, 0x69,
+	0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x65, 0x77, 0x55, 0x75, 0x69, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x51, 0x0a, 0x08, 0x44, 0x65, 0x62, 0x75, 0x67, 0x4a, 0x77, 0x74, 0x12,
+	0x21, 0x2e, 0x6c, 0x65, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x67, 0x6f, 0x61, 0x70, 0x69, 0x2e,
+	0x76, 0x31, 0x2e, 0x44, 0x65, 0x62, 0x75, 0x67, 0x4a, 0x77, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x22, 0x2e, 0x6c, 0x65, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x67, 0x6f, 0x61,
+	0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x62, 0x75, 0x67, 0x4a, 0x77, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x34, 0x5a, 0x32, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x65, 0x6e, 0x61, 0x74, 0x6f, 0x30, 0x33, 0x30, 0x37, 0x2f,
+	0x6c, 0x65, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x2d, 0x67, 0x6f, 0x2d, 0x61, 0x70, 0x69, 0x2f,
+	0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x3b, 0x61, 0x70, 0x69, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_programming_proto_rawDescOnce sync.Once
+	file_programming_proto_rawDescData = file_programming_proto_rawDesc
+)
+
+func file_programming_proto_rawDescGZIP() []byte {
+	file_programming_proto_rawDescOnce.Do(func() {
+		file_programming_proto_rawDescData = protoimpl.X.CompressGZIP(file_programming_proto_rawDescData)
+	})
+	return file_programming_proto_rawDescData
+}
+
+var file_programming_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_programming_proto_goTypes = []interface{}{
+	(*NewUuidRequest)(nil),   // 0: learninggoapi.v1.NewUuidRequest
+	(*NewUuidResponse)(nil),  // 1: learninggoapi.v1.NewUuidResponse
+	(*DebugJwtRequest)(nil),  // 2: learninggoapi.v1.DebugJwtRequest
+	(*DebugJwtResponse)(nil), // 3: learninggoapi.v1.DebugJwtResponse
+}
+var file_programming_proto_depIdxs = []int32{
+	0, // 0: learninggoapi.v1.ProgrammingService.NewUuid:input_type -> learninggoapi.v1.NewUuidRequest
+	2, // 1: learninggoapi.v1.ProgrammingService.DebugJwt:input_type -> learninggoapi.v1.DebugJwtRequest
+	1, // 2: learninggoapi.v1.ProgrammingService.NewUuid:output_type -> learninggoapi.v1.NewUuidResponse
+	3, // 3: learninggoapi.v1.ProgrammingService.DebugJwt:output_type -> learninggoapi.v1.DebugJwtResponse
+	2, // [2:4] is the sub-list for method output_type
+	0, // [0:2] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_programming_proto_init() }
+func file_programming_proto_init() {
+	if File_programming_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_programming_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NewUuidRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_programming_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NewUuidResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_programming_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DebugJwtRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_programming_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DebugJwtResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_programming_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_programming_proto_goTypes,
+		DependencyIndexes: file_programming_proto_depIdxs,
+		MessageInfos:      file_programming_proto_msgTypes,
+	}.Build()
+	File_programming_proto = out.File
+	file_programming_proto_rawDesc = nil
+	file_programming_proto_goTypes = nil
+	file_programming_proto_depIdxs = nil
+}
@@ -0,0 +1,147 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.4
+// source: programming.proto
+
+package apiv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ProgrammingServiceClient is the client API for ProgrammingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ProgrammingServiceClient interface {
+	// NewUuid generates a random UUID, like POST /v1/programming/uuid
+	NewUuid(ctx context.Context, in *NewUuidRequest, opts ...grpc.CallOption) (*NewUuidResponse, error)
+	// DebugJwt decodes a JWT without validating its signature, like
+	// POST /v1/programming/jwt
+	DebugJwt(ctx context.Context, in *DebugJwtRequest, opts ...grpc.CallOption) (*DebugJwtResponse, error)
+}
+
+type programmingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProgrammingServiceClient(cc grpc.ClientConnInterface) ProgrammingServiceClient {
+	return &programmingServiceClient{cc}
+}
+
+func (c *programmingServiceClient) NewUuid(ctx context.Context, in *NewUuidRequest, opts ...grpc.CallOption) (*NewUuidResponse, error) {
+	out := new(NewUuidResponse)
+	err := c.cc.Invoke(ctx, "/learninggoapi.v1.ProgrammingService/NewUuid", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *programmingServiceClient) DebugJwt(ctx context.Context, in *DebugJwtRequest, opts ...grpc.CallOption) (*DebugJwtResponse, error) {
+	out := new(DebugJwtResponse)
+	err := c.cc.Invoke(ctx, "/learninggoapi.v1.ProgrammingService/DebugJwt", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProgrammingServiceServer is the server API for ProgrammingService service.
+// All implementations must embed UnimplementedProgrammingServiceServer
+// for forward compatibility
+type ProgrammingServiceServer interface {
+	// NewUuid generates a random UUID, like POST /v1/programming/uuid
+	NewUuid(context.Context, *NewUuidRequest) (*NewUuidResponse, error)
+	// DebugJwt decodes a JWT without validating its signature, like
+	// POST /v1/programming/jwt
+	DebugJwt(context.Context, *DebugJwtRequest) (*DebugJwtResponse, error)
+	mustEmbedUnimplementedProgrammingServiceServer()
+}
+
+// UnimplementedProgrammingServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedProgrammingServiceServer struct {
+}
+
+func (UnimplementedProgrammingServiceServer) NewUuid(context.Context, *NewUuidRequest) (*NewUuidResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NewUuid not implemented")
+}
+func (UnimplementedProgrammingServiceServer) DebugJwt(context.Context, *DebugJwtRequest) (*DebugJwtResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DebugJwt not implemented")
+}
+func (UnimplementedProgrammingServiceServer) mustEmbedUnimplementedProgrammingServiceServer() {}
+
+// UnsafeProgrammingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProgrammingServiceServer will
+// result in compilation errors.
+type UnsafeProgrammingServiceServer interface {
+	mustEmbedUnimplementedProgrammingServiceServer()
+}
+
+func RegisterProgrammingServiceServer(s grpc.ServiceRegistrar, srv ProgrammingServiceServer) {
+	s.RegisterService(&ProgrammingService_ServiceDesc, srv)
+}
+
+func _ProgrammingService_NewUuid_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewUuidRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProgrammingServiceServer).NewUuid(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/learninggoapi.v1.ProgrammingService/NewUuid",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProgrammingServiceServer).NewUuid(ctx, req.(*NewUuidRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProgrammingService_DebugJwt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DebugJwtRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProgrammingServiceServer).DebugJwt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/learninggoapi.v1.ProgrammingService/DebugJwt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProgrammingServiceServer).DebugJwt(ctx, req.(*DebugJwtRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ProgrammingService_ServiceDesc is the grpc.ServiceDesc for ProgrammingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ProgrammingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "learninggoapi.v1.ProgrammingService",
+	HandlerType: (*ProgrammingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "NewUuid",
+			Handler:    _ProgrammingService_NewUuid_Handler,
+		},
+		{
+			MethodName: "DebugJwt",
+			Handler:    _ProgrammingService_DebugJwt_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "programming.proto",
+}
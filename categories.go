@@ -0,0 +1,8 @@
+package main
+
+// The function categories register themselves when imported. Import a new
+// category here to make it available.
+import (
+	_ "github.com/renato0307/learning-go-api/pkg/finance"
+	_ "github.com/renato0307/learning-go-api/pkg/programming"
+)
@@ -1,24 +1,104 @@
 package apierror
 
 import (
-	"encoding/json"
-	"testing"
+	"fmt"
 
-	"github.com/stretchr/testify/assert"
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/requestid"
 )
 
+// ContentType is the media type of the error responses
+const ContentType = "application/problem+json"
+
+// IncludeMessage keeps the "message" field of the previous error model, set
+// to the detail of the problem, for the clients not yet reading the problem
+// details.
+var IncludeMessage = true
+
+// ApiError is an RFC 7807 problem details error.
 type ApiError struct {
-	Message string `json:"message"`
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	RequestId string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	Message   string       `json:"message,omitempty"`
+}
+
+// FieldError explains why a request parameter or field is not valid.
+// Suggestions lists the valid values the invalid one may have been meant to
+// be, as in typos.
+type FieldError struct {
+	Field       string   `json:"field"`
+	Code        string   `json:"code"`
+	Detail      string   `json:"detail"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// RequiredField creates the error of a missing parameter.
+func RequiredField(field string) FieldError {
+	return FieldError{
+		Field:  field,
+		Code:   FieldRequired,
+		Detail: fmt.Sprintf("error: '%s' parameter is required", field),
+	}
+}
+
+// InvalidField creates the error of a parameter with an invalid value.
+func InvalidField(field, detail string) FieldError {
+	return FieldError{Field: field, Code: FieldInvalid, Detail: detail}
 }
 
-func New(message string) ApiError {
-	return ApiError{Message: message}
+// New creates the error of the code for the request, including its request
+// ID so a failing call can be looked up in the logs.
+func New(c *gin.Context, code Code, detail string) ApiError {
+	apiError := ApiError{
+		Type:      code.Type(),
+		Title:     code.Title(),
+		Status:    code.Status(),
+		Detail:    detail,
+		Code:      code,
+		RequestId: requestid.Get(c),
+	}
+
+	if c.Request != nil {
+		apiError.Instance = c.Request.URL.Path
+	}
+
+	if IncludeMessage {
+		apiError.Message = detail
+		if apiError.Message == "" {
+			apiError.Message = apiError.Title
+		}
+	}
+
+	return apiError
+}
+
+// Invalid creates an invalid parameters error listing the field errors.
+func Invalid(c *gin.Context, fieldErrors ...FieldError) ApiError {
+	detail := fmt.Sprintf("error: %d parameters are not valid", len(fieldErrors))
+	if len(fieldErrors) == 1 {
+		detail = fieldErrors[0].Detail
+	}
+
+	apiError := New(c, CodeInvalidParameters, detail)
+	apiError.Errors = fieldErrors
+
+	return apiError
 }
 
-func AssertIsValid(t *testing.T, jsonData []byte) {
-	apiError := ApiError{}
-	err := json.Unmarshal(jsonData, &apiError)
+// Respond writes the error as the response.
+func Respond(c *gin.Context, apiError ApiError) {
+	c.Header("Content-Type", ContentType)
+	c.JSON(apiError.Status, apiError)
+}
 
-	assert.Nil(t, err)
-	assert.NotEmpty(t, apiError.Message)
+// Abort writes the error as the response and stops the pending handlers.
+func Abort(c *gin.Context, apiError ApiError) {
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(apiError.Status, apiError)
 }
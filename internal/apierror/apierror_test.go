@@ -2,22 +2,161 @@ package apierror
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/requestid"
 	"github.com/stretchr/testify/assert"
 )
 
+func newTestContext(path string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", path, nil)
+
+	return c, w
+}
+
 func TestNew(t *testing.T) {
+	// arrange
+	c, _ := newTestContext("/v1/finance/currconv")
+	c.Set(requestid.Key, "fake-request-id")
 
+	// act
 	errorMessage := "this is a fake error message"
-	err := New(errorMessage)
+	err := New(c, CodeConversionFailed, errorMessage)
 
+	// assert
+	assert.Equal(t, TypeBaseURI+"conversion_failed", err.Type)
+	assert.Equal(t, "Currency conversion failed", err.Title)
+	assert.Equal(t, http.StatusInternalServerError, err.Status)
+	assert.Equal(t, errorMessage, err.Detail)
+	assert.Equal(t, "/v1/finance/currconv", err.Instance)
+	assert.Equal(t, CodeConversionFailed, err.Code)
+	assert.Equal(t, "fake-request-id", err.RequestId)
 	assert.Equal(t, errorMessage, err.Message)
 }
 
+func TestNewWithoutRequestId(t *testing.T) {
+	// arrange
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	// act
+	err := New(c, CodeForbidden, "")
+	data, _ := json.Marshal(err)
+
+	// assert
+	assert.Empty(t, err.RequestId)
+	assert.Empty(t, err.Instance)
+	assert.NotContains(t, string(data), requestid.Key)
+	assert.Equal(t, "Forbidden", err.Message)
+}
+
+func TestNewWithoutMessage(t *testing.T) {
+	// arrange
+	IncludeMessage = false
+	defer func() { IncludeMessage = true }()
+	c, _ := newTestContext("/")
+
+	// act
+	err := New(c, CodeBadRequest, "fake detail")
+	data, _ := json.Marshal(err)
+
+	// assert
+	assert.NotContains(t, string(data), `"message"`)
+}
+
+func TestNewWithUnknownCode(t *testing.T) {
+	// arrange
+	c, _ := newTestContext("/")
+
+	// act
+	err := New(c, Code("unknown"), "")
+
+	// assert
+	assert.Equal(t, http.StatusInternalServerError, err.Status)
+	assert.Equal(t, Code("unknown"), err.Code)
+}
+
+func TestInvalid(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		FieldErrors []FieldError
+		Detail      string
+	}{
+		{
+			Name:        "one field",
+			FieldErrors: []FieldError{RequiredField("from")},
+			Detail:      "error: 'from' parameter is required",
+		},
+		{
+			Name: "many fields",
+			FieldErrors: []FieldError{
+				RequiredField("from"),
+				InvalidField("amount", "error: 'amount' is not a valid number"),
+			},
+			Detail: "error: 2 parameters are not valid",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			c, _ := newTestContext("/")
+
+			// act
+			err := Invalid(c, tc.FieldErrors...)
+
+			// assert
+			assert.Equal(t, CodeInvalidParameters, err.Code)
+			assert.Equal(t, http.StatusBadRequest, err.Status)
+			assert.Equal(t, tc.Detail, err.Detail)
+			assert.Equal(t, tc.FieldErrors, err.Errors)
+		})
+	}
+}
+
+func TestRespond(t *testing.T) {
+	// arrange
+	c, w := newTestContext("/")
+
+	// act
+	Respond(c, New(c, CodeNotFound, ""))
+
+	// assert
+	AssertIsProblem(t, w, CodeNotFound)
+}
+
+func TestAbort(t *testing.T) {
+	// arrange
+	c, w := newTestContext("/")
+
+	// act
+	Abort(c, New(c, CodeQuotaExceeded, ""))
+
+	// assert
+	assert.True(t, c.IsAborted())
+	AssertIsProblem(t, w, CodeQuotaExceeded)
+}
+
+func TestCatalog(t *testing.T) {
+	// act
+	entries := Catalog()
+
+	// assert
+	for code, entry := range entries {
+		assert.NotZero(t, entry.Status, code)
+		assert.NotEmpty(t, entry.Title, code)
+	}
+	assert.Contains(t, entries, CodeInvalidParameters)
+}
+
 func TestAssertIsValid(t *testing.T) {
 	// arrange
-	err := New("this is a fake error message")
+	c, _ := newTestContext("/")
+	err := New(c, CodeBadRequest, "this is a fake error message")
 	data, _ := json.Marshal(err)
 	tt := testing.T{}
 
@@ -39,3 +178,47 @@ func TestAssertIsValidWithInvalidJson(t *testing.T) {
 	// assert
 	assert.True(t, tt.Failed())
 }
+
+func TestAssertIsValidWithoutCode(t *testing.T) {
+	// arrange
+	data := []byte(`{"message": "the previous error model"}`)
+	tt := testing.T{}
+
+	// act
+	AssertIsValid(&tt, data)
+
+	// assert
+	assert.True(t, tt.Failed())
+}
+
+func TestAssertHasCode(t *testing.T) {
+	// arrange
+	c, _ := newTestContext("/")
+	data, _ := json.Marshal(New(c, CodeForbidden, ""))
+	valid := testing.T{}
+	invalid := testing.T{}
+
+	// act
+	AssertHasCode(&valid, data, CodeForbidden)
+	AssertHasCode(&invalid, data, CodeUnauthorized)
+
+	// assert
+	assert.False(t, valid.Failed())
+	assert.True(t, invalid.Failed())
+}
+
+func TestAssertHasFieldError(t *testing.T) {
+	// arrange
+	c, _ := newTestContext("/")
+	data, _ := json.Marshal(Invalid(c, RequiredField("from")))
+	valid := testing.T{}
+	invalid := testing.T{}
+
+	// act
+	AssertHasFieldError(&valid, data, "from")
+	AssertHasFieldError(&invalid, data, "to")
+
+	// assert
+	assert.False(t, valid.Failed())
+	assert.True(t, invalid.Failed())
+}
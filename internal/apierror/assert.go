@@ -0,0 +1,55 @@
+package apierror
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// AssertIsValid asserts the data is a problem details error with a code.
+func AssertIsValid(t *testing.T, jsonData []byte) {
+	apiError := ApiError{}
+	err := json.Unmarshal(jsonData, &apiError)
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, apiError.Type)
+	assert.NotEmpty(t, apiError.Title)
+	assert.NotZero(t, apiError.Status)
+	assert.NotEmpty(t, apiError.Code)
+}
+
+// AssertHasCode asserts the data is a problem details error with the code
+// and its status.
+func AssertHasCode(t *testing.T, jsonData []byte, code Code) {
+	AssertIsValid(t, jsonData)
+
+	apiError := ApiError{}
+	json.Unmarshal(jsonData, &apiError)
+	assert.Equal(t, code, apiError.Code)
+	assert.Equal(t, code.Status(), apiError.Status)
+	assert.Equal(t, code.Type(), apiError.Type)
+}
+
+// AssertHasFieldError asserts the data is a problem details error reporting
+// the field as not valid.
+func AssertHasFieldError(t *testing.T, jsonData []byte, field string) {
+	apiError := ApiError{}
+	err := json.Unmarshal(jsonData, &apiError)
+	assert.Nil(t, err)
+
+	fields := []string{}
+	for _, fieldError := range apiError.Errors {
+		fields = append(fields, fieldError.Field)
+	}
+	assert.Contains(t, fields, field)
+}
+
+// AssertIsProblem asserts the response is a problem details error with the
+// code, including the HTTP status and the content type.
+func AssertIsProblem(t *testing.T, w *httptest.ResponseRecorder, code Code) {
+	assert.Equal(t, code.Status(), w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	AssertHasCode(t, w.Body.Bytes(), code)
+}
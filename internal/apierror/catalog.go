@@ -0,0 +1,86 @@
+package apierror
+
+import "net/http"
+
+// Code identifies an error in a machine-readable way. Clients should rely on
+// it instead of the human-readable title and detail.
+type Code string
+
+// The catalog of the error codes returned by the API
+const (
+	CodeBadRequest        Code = "bad_request"
+	CodeInvalidParameters Code = "invalid_parameters"
+	CodeInvalidBody       Code = "invalid_body"
+	CodeUnauthorized      Code = "unauthorized"
+	CodeForbidden         Code = "forbidden"
+	CodeNotFound          Code = "not_found"
+	CodeRateLimited       Code = "rate_limited"
+	CodeQuotaExceeded     Code = "quota_exceeded"
+	CodeInternal          Code = "internal_error"
+	CodeConversionFailed  Code = "conversion_failed"
+	CodeRateNotFound      Code = "rate_not_found"
+)
+
+// Field error codes, explaining why a parameter is not valid
+const (
+	FieldRequired = "required"
+	FieldInvalid  = "invalid"
+)
+
+// TypeBaseURI prefixes the codes to build the problem type URIs
+const TypeBaseURI = "https://learninggolang.com/problems/"
+
+// CatalogEntry describes the HTTP status and title of an error code
+type CatalogEntry struct {
+	Status int
+	Title  string
+}
+
+var catalog = map[Code]CatalogEntry{
+	CodeBadRequest:        {http.StatusBadRequest, "Bad request"},
+	CodeInvalidParameters: {http.StatusBadRequest, "Invalid parameters"},
+	CodeInvalidBody:       {http.StatusBadRequest, "Invalid request body"},
+	CodeUnauthorized:      {http.StatusUnauthorized, "Not authorized"},
+	CodeForbidden:         {http.StatusForbidden, "Forbidden"},
+	CodeNotFound:          {http.StatusNotFound, "Not found"},
+	CodeRateLimited:       {http.StatusTooManyRequests, "Too many requests"},
+	CodeQuotaExceeded:     {http.StatusTooManyRequests, "Quota exceeded"},
+	CodeInternal:          {http.StatusInternalServerError, "Internal server error"},
+	CodeConversionFailed:  {http.StatusInternalServerError, "Currency conversion failed"},
+	CodeRateNotFound:      {http.StatusNotFound, "Exchange rate not found"},
+}
+
+// Catalog returns the entries of all the error codes.
+func Catalog() map[Code]CatalogEntry {
+	entries := make(map[Code]CatalogEntry, len(catalog))
+	for code, entry := range catalog {
+		entries[code] = entry
+	}
+
+	return entries
+}
+
+// entry returns the catalog entry of the code. Codes missing from the
+// catalog are reported as internal errors.
+func (code Code) entry() CatalogEntry {
+	if entry, found := catalog[code]; found {
+		return entry
+	}
+
+	return catalog[CodeInternal]
+}
+
+// Status returns the HTTP status of the code.
+func (code Code) Status() int {
+	return code.entry().Status
+}
+
+// Title returns the short human-readable summary of the code.
+func (code Code) Title() string {
+	return code.entry().Title
+}
+
+// Type returns the URI identifying the problem type of the code.
+func (code Code) Type() string {
+	return TypeBaseURI + string(code)
+}
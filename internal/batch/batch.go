@@ -0,0 +1,296 @@
+// Package batch runs several function calls in one request.
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/dispatch"
+	"github.com/renato0307/learning-go-api/internal/logging"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/openapi"
+	"github.com/renato0307/learning-go-api/internal/registry"
+	"github.com/renato0307/learning-go-api/internal/requestid"
+)
+
+const (
+	// DefaultMaxOperations limits the operations of a batch if the Config
+	// sets no limit
+	DefaultMaxOperations = 500
+
+	// DefaultConcurrency limits the operations running at the same time if
+	// the Config sets no limit
+	DefaultConcurrency = 8
+)
+
+// Config limits the size of the batches and the operations running at the
+// same time for each batch
+type Config struct {
+	MaxOperations int
+	Concurrency   int
+}
+
+// operation is a function call of the "POST /batch" action
+type operation struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	// Params are sent in the query string, like the route parameters
+	Params map[string]interface{} `json:"params,omitempty"`
+
+	// Body is sent as plain text if it is a string, or as JSON otherwise
+	Body interface{} `json:"body,omitempty"`
+}
+
+// postBatchInput is the input of the "POST /batch" action
+type postBatchInput struct {
+	Operations []operation `json:"operations"`
+}
+
+// result is the result of an operation, with either the output of the
+// function or its error
+type result struct {
+	Status int                `json:"status"`
+	Output interface{}        `json:"output,omitempty"`
+	Error  *apierror.ApiError `json:"error,omitempty"`
+}
+
+// postBatchOutput is the output of the "POST /batch" action
+type postBatchOutput struct {
+	Results []result `json:"results"`
+}
+
+// RoutePolicies defines the scopes required by the batch route, with paths
+// relative to the base router group. Every client can send batches, each
+// operation is authorized with the scopes of its function.
+var RoutePolicies = []middleware.RoutePolicy{
+	{Method: "POST", Path: "/batch", Public: true},
+}
+
+// RouteDocs documents the batch route in the OpenAPI document, with paths
+// relative to the base router group
+var RouteDocs = []openapi.Route{
+	{
+		Method:      "POST",
+		Path:        "/batch",
+		Summary:     "Calls several functions in one request",
+		Description: "Returns the output or the error of each operation, in order. Each operation requires the scopes of its function, and counts for the rate limits and quotas.",
+		Tags:        []string{"batch"},
+		RequestBody: openapi.JSONBody("The function calls", postBatchInput{}),
+		Output:      postBatchOutput{},
+		Errors:      []apierror.Code{apierror.CodeInvalidBody, apierror.CodeInvalidParameters},
+	},
+}
+
+// SetRouterGroup defines the batch route for the functions of the mounted
+// categories, called through the handler.
+func SetRouterGroup(
+	mounted []registry.Mounted,
+	handler http.Handler,
+	config Config,
+	base *gin.RouterGroup) *gin.RouterGroup {
+
+	if config.MaxOperations <= 0 {
+		config.MaxOperations = DefaultMaxOperations
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+
+	functions := map[string]bool{}
+	for _, m := range mounted {
+		for _, route := range m.Docs {
+			functions[functionKey(route.Method, path.Join(m.Group.BasePath(), route.Path))] = true
+		}
+	}
+
+	base.POST("/batch", postBatch(functions, handler, config))
+
+	return base
+}
+
+// postBatch handles the batch request.
+//
+// The operations run through the handler with the credentials of the caller,
+// at most config.Concurrency at the same time, so each one is authorized,
+// validated and metered like a single call.
+//
+// It returns HTTP 200 with the results in the order of the operations, even
+// if some failed.
+// Returns HTTP 400 if the body is not valid or has too many operations.
+func postBatch(functions map[string]bool, handler http.Handler, config Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := logging.FromContext(c.Request.Context())
+
+		input := postBatchInput{}
+		if err := json.NewDecoder(c.Request.Body).Decode(&input); err != nil {
+			msg := fmt.Sprintf("error: the body is not a valid batch: %s", err.Error())
+			apierror.Respond(c, apierror.New(c, apierror.CodeInvalidBody, msg))
+			return
+		}
+
+		if len(input.Operations) == 0 {
+			msg := "error: 'operations' must have at least one operation"
+			apierror.Respond(c, apierror.Invalid(c, apierror.InvalidField("operations", msg)))
+			return
+		}
+		if len(input.Operations) > config.MaxOperations {
+			msg := fmt.Sprintf("error: 'operations' must have at most %d operations", config.MaxOperations)
+			apierror.Respond(c, apierror.Invalid(c, apierror.InvalidField("operations", msg)))
+			return
+		}
+
+		logger.Debug().Int("operations", len(input.Operations)).Msg("running batch")
+
+		batchId := requestid.Get(c)
+		results := make([]result, len(input.Operations))
+		semaphore := make(chan struct{}, config.Concurrency)
+		wg := sync.WaitGroup{}
+		for i, op := range input.Operations {
+			wg.Add(1)
+			semaphore <- struct{}{}
+			go func(i int, op operation) {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+				results[i] = run(c, functions, handler, requestid.Sub(batchId, i), op)
+			}(i, op)
+		}
+		wg.Wait()
+
+		c.JSON(http.StatusOK, postBatchOutput{Results: results})
+	}
+}
+
+// run calls the function of the operation with the request ID, which is the
+// one of the batch with the operation index, as in "<request id>.3" (see
+// requestid.Sub).
+func run(
+	c *gin.Context,
+	functions map[string]bool,
+	handler http.Handler,
+	requestId string,
+	op operation) result {
+
+	method := strings.ToUpper(op.Method)
+	if !isFunction(functions, method, op.Path) {
+		msg := fmt.Sprintf("error: '%s %s' is not a function", method, op.Path)
+		return errorResult(c, op, apierror.CodeNotFound, msg)
+	}
+
+	req, err := newRequest(c.Request.Context(), method, op)
+	if err != nil {
+		return errorResult(c, op, apierror.CodeInvalidBody, err.Error())
+	}
+	dispatch.Forward(c.Request.Header, req)
+	req.Header.Set(requestid.Header, requestId)
+
+	w := dispatch.Do(handler, req)
+
+	r := result{Status: w.Status}
+	if w.IsError() {
+		apiError := apierror.ApiError{}
+		if err := json.Unmarshal(w.Body, &apiError); err != nil {
+			return errorResult(c, op, apierror.CodeInternal, "")
+		}
+		r.Error = &apiError
+		return r
+	}
+
+	if err := json.Unmarshal(w.Body, &r.Output); err != nil {
+		r.Output = string(w.Body)
+	}
+
+	return r
+}
+
+// newRequest creates the request of the operation.
+func newRequest(ctx context.Context, method string, op operation) (*http.Request, error) {
+	query := url.Values{}
+	for name, value := range op.Params {
+		query.Set(name, dispatch.FormatValue(value))
+	}
+
+	target := op.Path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	body := new(bytes.Buffer)
+	contentType := ""
+	switch b := op.Body.(type) {
+	case nil:
+	case string:
+		body.WriteString(b)
+		contentType = "text/plain"
+	default:
+		if err := json.NewEncoder(body).Encode(b); err != nil {
+			return nil, fmt.Errorf("error: cannot encode the body: %s", err)
+		}
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return req, nil
+}
+
+// errorResult creates the result of an operation failing before its function
+// is called.
+func errorResult(c *gin.Context, op operation, code apierror.Code, detail string) result {
+	apiError := apierror.New(c, code, detail)
+	apiError.Instance = op.Path
+
+	return result{Status: apiError.Status, Error: &apiError}
+}
+
+func functionKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// isFunction checks if the path is a function, matching the path parameters
+// of the function routes, as in "/v1/finance/rates/EUR/history" for
+// "/v1/finance/rates/:base/history".
+func isFunction(functions map[string]bool, method, path string) bool {
+	if functions[functionKey(method, path)] {
+		return true
+	}
+
+	segments := strings.Split(functionKey(method, path), "/")
+	for function := range functions {
+		if matchSegments(strings.Split(function, "/"), segments) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+
+	for i := range pattern {
+		isParameter := strings.HasPrefix(pattern[i], ":") && segments[i] != ""
+		if pattern[i] != segments[i] && !isParameter {
+			return false
+		}
+	}
+
+	return true
+}
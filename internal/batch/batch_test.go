@@ -0,0 +1,262 @@
+package batch
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/openapi"
+	"github.com/renato0307/learning-go-api/internal/registry"
+	"github.com/renato0307/learning-go-api/internal/requestid"
+	"github.com/stretchr/testify/assert"
+)
+
+const testScopePrefix = "https://example.com/"
+
+var testPolicies = []middleware.RoutePolicy{
+	{Method: "GET", Path: "/echo", Scopes: []string{"text-echo"}},
+	{Method: "POST", Path: "/reverse", Scopes: []string{"text-reverse"}},
+	{Method: "GET", Path: "/slow", Scopes: []string{"text-echo"}},
+}
+
+var testDocs = []openapi.Route{
+	{Method: "GET", Path: "/echo", Summary: "Echoes a value"},
+	{Method: "POST", Path: "/reverse", Summary: "Reverses a text"},
+	{Method: "GET", Path: "/slow", Summary: "Waits a bit"},
+}
+
+// concurrency tracks the calls to "/slow" running at the same time
+type concurrency struct {
+	mu      sync.Mutex
+	current int
+	max     int
+}
+
+// setupGin creates a router with the "text" category and the batch route.
+// The fake authenticator reads the client scopes from the Authorization
+// header.
+func setupGin(config Config, tracker *concurrency) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.RequestID())
+	r.Use(func(c *gin.Context) { // fake Authenticator
+		c.Set(middleware.ScopeKey, c.GetHeader("Authorization"))
+	})
+	policy := middleware.NewAuthorizationPolicy(testScopePrefix)
+	r.Use(middleware.Authorizer(policy))
+
+	base := r.Group("/v1")
+	group := base.Group("/text")
+	group.GET("/echo", func(c *gin.Context) {
+		if c.Query("value") == "" {
+			apierror.Respond(c, apierror.Invalid(c, apierror.RequiredField("value")))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"value": c.Query("value"), "request_id": requestid.Get(c)})
+	})
+	group.POST("/reverse", func(c *gin.Context) {
+		data, _ := ioutil.ReadAll(c.Request.Body)
+		for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+			data[i], data[j] = data[j], data[i]
+		}
+		c.JSON(http.StatusOK, gin.H{"value": string(data)})
+	})
+	group.GET("/slow", func(c *gin.Context) {
+		tracker.mu.Lock()
+		tracker.current++
+		if tracker.current > tracker.max {
+			tracker.max = tracker.current
+		}
+		tracker.mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		tracker.mu.Lock()
+		tracker.current--
+		tracker.mu.Unlock()
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	policy.Register(group.BasePath(), testPolicies...)
+
+	mounted := []registry.Mounted{{
+		Category: registry.Category{Name: "text", Policies: testPolicies, Docs: testDocs},
+		Group:    group,
+	}}
+	SetRouterGroup(mounted, r, config, base)
+	policy.Register(base.BasePath(), RoutePolicies...)
+
+	return r
+}
+
+func performBatch(r http.Handler, scopes, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/v1/batch", strings.NewReader(body))
+	req.Header.Set("Authorization", scopes)
+	req.Header.Set(requestid.Header, "batch-1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestPostBatchWithLongRequestId(t *testing.T) {
+	// arrange - the longest request ID accepted
+	r := setupGin(Config{}, &concurrency{})
+	batchId := strings.Repeat("a", 128)
+	body := `{"operations":[{"method":"GET","path":"/v1/text/echo","params":{"value":1}}]}`
+	req := httptest.NewRequest("POST", "/v1/batch", strings.NewReader(body))
+	req.Header.Set("Authorization", testScopePrefix+"text-echo")
+	req.Header.Set(requestid.Header, batchId)
+	w := httptest.NewRecorder()
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert - the operation keeps a request ID derived from the batch one
+	assert.Equal(t, http.StatusOK, w.Code)
+	output := postBatchOutput{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &output))
+	assert.Equal(t, requestid.Sub(batchId, 0), output.Results[0].Output.(map[string]interface{})["request_id"])
+}
+
+func TestPostBatch(t *testing.T) {
+	// arrange
+	r := setupGin(Config{}, &concurrency{})
+	body := `{"operations":[
+		{"method":"GET","path":"/v1/text/echo","params":{"value":10.5}},
+		{"method":"GET","path":"/v1/text/echo"},
+		{"method":"post","path":"/v1/text/reverse","body":"abc"},
+		{"method":"GET","path":"/v1/unknown"},
+		{"method":"POST","path":"/v1/batch"}
+	]}`
+
+	// act
+	w := performBatch(r, testScopePrefix+"text-echo", body)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	output := postBatchOutput{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &output))
+	assert.Len(t, output.Results, 5)
+
+	assert.Equal(t, http.StatusOK, output.Results[0].Status)
+	assert.Equal(t, map[string]interface{}{"value": "10.5", "request_id": "batch-1.0"},
+		output.Results[0].Output)
+	assert.Nil(t, output.Results[0].Error)
+
+	assert.Equal(t, http.StatusBadRequest, output.Results[1].Status)
+	assert.Equal(t, apierror.CodeInvalidParameters, output.Results[1].Error.Code)
+	assert.Equal(t, "batch-1.1", output.Results[1].Error.RequestId)
+	assert.Equal(t, "value", output.Results[1].Error.Errors[0].Field)
+
+	assert.Equal(t, http.StatusForbidden, output.Results[2].Status)
+	assert.Equal(t, apierror.CodeForbidden, output.Results[2].Error.Code)
+
+	assert.Equal(t, http.StatusNotFound, output.Results[3].Status)
+	assert.Equal(t, apierror.CodeNotFound, output.Results[3].Error.Code)
+	assert.Equal(t, "/v1/unknown", output.Results[3].Error.Instance)
+
+	assert.Equal(t, apierror.CodeNotFound, output.Results[4].Error.Code)
+}
+
+func TestPostBatchWithBody(t *testing.T) {
+	// arrange
+	r := setupGin(Config{}, &concurrency{})
+	body := `{"operations":[{"method":"POST","path":"/v1/text/reverse","body":"abc"}]}`
+
+	// act
+	w := performBatch(r, testScopePrefix+"text-reverse", body)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"results":[{"status":200,"output":{"value":"cba"}}]}`, w.Body.String())
+}
+
+func TestPostBatchWithInvalidBody(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Body string
+		Code apierror.Code
+	}{
+		{Name: "not json", Body: `{"operations":`, Code: apierror.CodeInvalidBody},
+		{Name: "no operations", Body: `{"operations":[]}`, Code: apierror.CodeInvalidParameters},
+		{Name: "too many operations", Body: `{"operations":[{},{},{}]}`, Code: apierror.CodeInvalidParameters},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			r := setupGin(Config{MaxOperations: 2}, &concurrency{})
+
+			// act
+			w := performBatch(r, testScopePrefix+"text-echo", tc.Body)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			apierror.AssertIsProblem(t, w, tc.Code)
+		})
+	}
+}
+
+func TestPostBatchConcurrency(t *testing.T) {
+	// arrange
+	tracker := &concurrency{}
+	r := setupGin(Config{Concurrency: 2}, tracker)
+	operations := []string{}
+	for i := 0; i < 10; i++ {
+		operations = append(operations, `{"method":"GET","path":"/v1/text/slow"}`)
+	}
+
+	// act
+	w := performBatch(r, testScopePrefix+"text-echo",
+		`{"operations":[`+strings.Join(operations, ",")+`]}`)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, tracker.max)
+}
+
+func TestRouteDocs(t *testing.T) {
+	// arrange
+	r := gin.New()
+	base := r.Group("/v1")
+	SetRouterGroup(nil, r, Config{}, base)
+	doc := openapi.NewDocument(openapi.Info{Title: "test", Version: "1"})
+
+	// act
+	doc.Register(base.BasePath(), RouteDocs...)
+
+	// assert
+	assert.NoError(t, doc.CheckRoutes(r.Routes()))
+}
+
+func TestIsFunction(t *testing.T) {
+	functions := map[string]bool{
+		"GET /v1/text/echo":          true,
+		"GET /v1/text/items/:id/tag": true,
+	}
+
+	testCases := []struct {
+		Method string
+		Path   string
+		Found  bool
+	}{
+		{Method: "GET", Path: "/v1/text/echo", Found: true},
+		{Method: "GET", Path: "/v1/text/items/12/tag", Found: true},
+		{Method: "GET", Path: "/v1/text/items//tag", Found: false},
+		{Method: "POST", Path: "/v1/text/items/12/tag", Found: false},
+		{Method: "GET", Path: "/v1/text/items/12", Found: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Method+" "+tc.Path, func(t *testing.T) {
+			assert.Equal(t, tc.Found, isFunction(functions, tc.Method, tc.Path))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Package dispatch calls the routes of the API in process, so other entry
+// points like MCP or batches share their validation, authorization and
+// errors.
+package dispatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// ForwardedHeaders are copied from the caller request to the dispatched
+// requests, so they share its token and request ID. The dispatched requests
+// are authenticated as the caller by the identity in the caller context, as
+// the token may come from the query string or the form.
+var ForwardedHeaders = []string{"Authorization", "Authentication", "X-Request-ID"}
+
+// Response is the response of a dispatched request
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// IsError tells if the response is an error, with problem details in the
+// body.
+func (r *Response) IsError() bool {
+	return r.Status >= http.StatusBadRequest
+}
+
+// Forward copies the forwarded headers of the caller to the request.
+func Forward(caller http.Header, req *http.Request) {
+	for _, key := range ForwardedHeaders {
+		if value := caller.Get(key); value != "" {
+			req.Header.Set(key, value)
+		}
+	}
+}
+
+// Do serves the request with the handler, keeping the response in memory.
+func Do(handler http.Handler, req *http.Request) *Response {
+	w := &responseBuffer{header: http.Header{}, status: http.StatusOK}
+	handler.ServeHTTP(w, req)
+
+	return &Response{Status: w.status, Header: w.header, Body: w.body.Bytes()}
+}
+
+// FormatValue converts a JSON value into a query string value. Numbers keep
+// their shortest representation, arrays and objects are sent as JSON.
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// responseBuffer keeps the response of a request in memory
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseBuffer) Header() http.Header {
+	return w.header
+}
+
+func (w *responseBuffer) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *responseBuffer) WriteHeader(status int) {
+	w.status = status
+}
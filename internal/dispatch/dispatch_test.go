@@ -0,0 +1,58 @@
+package dispatch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDo(t *testing.T) {
+	// arrange
+	r := gin.New()
+	r.GET("/example", func(c *gin.Context) {
+		c.JSON(http.StatusTeapot, gin.H{"authorization": c.GetHeader("Authorization")})
+	})
+
+	caller := http.Header{}
+	caller.Set("Authorization", "Bearer abc")
+	caller.Set("Cookie", "not-forwarded")
+	req := httptest.NewRequest("GET", "/example", nil)
+	Forward(caller, req)
+
+	// act
+	w := Do(r, req)
+
+	// assert
+	assert.Equal(t, http.StatusTeapot, w.Status)
+	assert.True(t, w.IsError())
+	assert.Equal(t, "application/json; charset=utf-8", w.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"authorization":"Bearer abc"}`, string(w.Body))
+	assert.Empty(t, req.Header.Get("Cookie"))
+}
+
+func TestFormatValue(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Value    interface{}
+		Expected string
+	}{
+		{Name: "string", Value: "EUR", Expected: "EUR"},
+		{Name: "integer", Value: float64(10), Expected: "10"},
+		{Name: "decimal", Value: 10.25, Expected: "10.25"},
+		{Name: "boolean", Value: false, Expected: "false"},
+		{Name: "array", Value: []interface{}{"a", "b"}, Expected: `["a","b"]`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// act
+			value := FormatValue(tc.Value)
+
+			// assert
+			assert.Equal(t, tc.Expected, value)
+		})
+	}
+}
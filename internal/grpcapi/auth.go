@@ -0,0 +1,146 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/logging"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/registry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// publicServices do not require a token, like the health route
+var publicServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+type identityKey struct{}
+
+// IdentityFromContext returns the client authenticated by the
+// UnaryAuthenticator, false for public methods.
+func IdentityFromContext(ctx context.Context) (*middleware.Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*middleware.Identity)
+	return identity, ok
+}
+
+// route is the HTTP route mirrored by a gRPC method
+type route struct {
+	method string
+	path   string
+}
+
+// Routes maps the gRPC methods to the routes they mirror, so the calls are
+// authorized with the policy of the routes, including the overrides of the
+// policy file.
+type Routes map[string]route
+
+// Add maps the methods, with paths relative to the base path, like
+// AuthorizationPolicy.Register.
+func (r Routes) Add(basePath string, methods ...registry.GrpcMethod) {
+	for _, m := range methods {
+		r[m.FullMethod] = route{method: m.Method, path: basePath + m.Path}
+	}
+}
+
+// UnaryAuthenticator validates the token in the call metadata, read from the
+// same sources and with the same rules as the Authenticator middleware.
+//
+// Returns Unauthenticated if there is no valid token.
+// Returns InvalidArgument if the metadata is not valid, like more than one
+// token.
+func UnaryAuthenticator(ac *middleware.AuthenticatorConfig) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		if isPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		logger := logging.FromContext(ctx)
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		tokenString, err := ac.TokenFromHeaders(func(key string) string {
+			if values := md.Get(key); len(values) > 0 {
+				return values[0]
+			}
+			return ""
+		})
+		if err == middleware.ErrTokenNotFound {
+			logger.Debug().Msg("JWT not found")
+			return nil, Error(apierror.CodeUnauthorized, "")
+		}
+		if err != nil {
+			logger.Debug().Err(err).Msg("JWT request not valid")
+			return nil, Error(apierror.CodeBadRequest, err.Error())
+		}
+
+		identity, err := ac.Authenticate(tokenString)
+		if err != nil {
+			logger.Debug().Err(err).Msg("JWT not valid")
+			return nil, Error(apierror.CodeUnauthorized, "")
+		}
+
+		return handler(context.WithValue(ctx, identityKey{}, identity), req)
+	}
+}
+
+// UnaryAuthorizer checks the scopes and groups of the client against the
+// policy of the route mirrored by the method, like the Authorizer middleware.
+//
+// Returns PermissionDenied if the method mirrors no route, the route has no
+// policy or no valid scope or group is found.
+func UnaryAuthorizer(policy *middleware.AuthorizationPolicy, routes Routes) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		if isPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		logger := logging.FromContext(ctx)
+
+		route, found := routes[info.FullMethod]
+		if !found {
+			logger.Debug().Msgf("no route for method %s", info.FullMethod)
+			return nil, Error(apierror.CodeForbidden, "")
+		}
+
+		routePolicy, found := policy.Find(route.method, route.path)
+		if !found {
+			logger.Debug().Msgf("no authorization policy for route %s", route.path)
+			return nil, Error(apierror.CodeForbidden, "")
+		}
+
+		identity, ok := IdentityFromContext(ctx)
+		if !ok {
+			identity = &middleware.Identity{}
+		}
+		if !policy.Allows(routePolicy, strings.Fields(identity.Scope), identity.Groups) {
+			logger.Debug().Msg("no scope or group found for current method")
+			return nil, Error(apierror.CodeForbidden, "")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// isPublic checks if the method does not require a token.
+func isPublic(fullMethod string) bool {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,206 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	testIssuer      = "https://cognito-idp.eu-west-1.amazonaws.com/eu-west-1_xxxxxxxxxx"
+	testScopePrefix = "https://example.com/"
+	testMethod      = "/learninggoapi.v1.TestService/Echo"
+)
+
+// newTestAuthConfig trusts a Cognito issuer signing with a new key, returned
+// to sign the test tokens.
+func newTestAuthConfig(t *testing.T) (*middleware.AuthenticatorConfig, jwk.Key) {
+	raw, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	key, _ := jwk.New(raw)
+	key.Set(jwk.KeyIDKey, "mykey")
+
+	publicKey, _ := key.(jwk.RSAPrivateKey).PublicKey()
+	publicKey.Set(jwk.AlgorithmKey, "RS256")
+	set := jwk.NewSet()
+	set.Add(publicKey)
+	jwks, _ := json.Marshal(set)
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(jwks)
+	}))
+	t.Cleanup(svr.Close)
+	keySet := middleware.NewKeySetCache(svr.URL, 0)
+	assert.NoError(t, keySet.Refresh())
+
+	return &middleware.AuthenticatorConfig{
+		Issuers: []*middleware.TrustedIssuer{middleware.NewCognitoIssuer(testIssuer, keySet)},
+	}, key
+}
+
+func newTestJWT(t *testing.T, key jwk.Key, scope string) string {
+	token := jwt.New()
+	token.Set("sub", "client_id_1234567890")
+	token.Set("client_id", "client_id_1234567890")
+	token.Set("token_use", "access")
+	token.Set("scope", scope)
+	token.Set("iss", testIssuer)
+	token.Set("exp", time.Now().Unix()+1000)
+
+	signed, err := jwt.Sign(token, jwa.RS256, key)
+	assert.NoError(t, err)
+
+	return string(signed)
+}
+
+// callWith runs the interceptor on a call to the method with the metadata,
+// returning the identity seen by the handler.
+func callWith(
+	interceptor grpc.UnaryServerInterceptor,
+	ctx context.Context,
+	fullMethod string,
+	md metadata.MD) (*middleware.Identity, error) {
+
+	var identity *middleware.Identity
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		identity, _ = IdentityFromContext(ctx)
+		return "ok", nil
+	}
+
+	ctx = metadata.NewIncomingContext(ctx, md)
+	info := &grpc.UnaryServerInfo{FullMethod: fullMethod}
+	_, err := interceptor(ctx, nil, info, handler)
+
+	return identity, err
+}
+
+func TestUnaryAuthenticator(t *testing.T) {
+	// arrange
+	ac, key := newTestAuthConfig(t)
+	_, otherKey := newTestAuthConfig(t)
+
+	testCases := []struct {
+		Name     string
+		Metadata metadata.MD
+		Code     codes.Code
+	}{
+		{
+			Name:     "bearer token",
+			Metadata: metadata.Pairs("authorization", "Bearer "+newTestJWT(t, key, "scope-a")),
+			Code:     codes.OK,
+		},
+		{
+			Name:     "authentication token",
+			Metadata: metadata.Pairs("authentication", newTestJWT(t, key, "scope-a")),
+			Code:     codes.OK,
+		},
+		{
+			Name:     "missing token",
+			Metadata: metadata.MD{},
+			Code:     codes.Unauthenticated,
+		},
+		{
+			Name:     "token signed by another key",
+			Metadata: metadata.Pairs("authorization", "Bearer "+newTestJWT(t, otherKey, "scope-a")),
+			Code:     codes.Unauthenticated,
+		},
+		{
+			Name:     "not a bearer token",
+			Metadata: metadata.Pairs("authorization", "Basic abc"),
+			Code:     codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// act
+			identity, err := callWith(UnaryAuthenticator(ac), context.Background(), testMethod, tc.Metadata)
+
+			// assert
+			assertCode(t, tc.Code, err)
+			if tc.Code == codes.OK {
+				assert.Equal(t, "client_id_1234567890", identity.ClientId)
+				assert.Equal(t, "scope-a", identity.Scope)
+			}
+		})
+	}
+}
+
+func TestUnaryAuthenticatorSkipsPublicMethods(t *testing.T) {
+	// arrange
+	ac, _ := newTestAuthConfig(t)
+
+	// act
+	_, err := callWith(UnaryAuthenticator(ac), context.Background(),
+		"/grpc.health.v1.Health/Check", metadata.MD{})
+
+	// assert
+	assert.NoError(t, err)
+}
+
+func TestUnaryAuthorizer(t *testing.T) {
+	// arrange
+	policy := middleware.NewAuthorizationPolicy(testScopePrefix)
+	policy.Register("/v1/test",
+		middleware.RoutePolicy{Method: "GET", Path: "/echo", Scopes: []string{"test-echo"}})
+	routes := Routes{}
+	routes.Add("/v1/test",
+		registry.GrpcMethod{FullMethod: testMethod, Method: "GET", Path: "/echo"},
+		registry.GrpcMethod{FullMethod: "/learninggoapi.v1.TestService/Other", Method: "GET", Path: "/other"})
+
+	testCases := []struct {
+		Name       string
+		FullMethod string
+		Scope      string
+		Code       codes.Code
+	}{
+		{Name: "granted", FullMethod: testMethod, Scope: testScopePrefix + "test-echo", Code: codes.OK},
+		{Name: "missing scope", FullMethod: testMethod, Scope: testScopePrefix + "test-other", Code: codes.PermissionDenied},
+		{Name: "no identity", FullMethod: testMethod, Code: codes.PermissionDenied},
+		{Name: "no policy", FullMethod: "/learninggoapi.v1.TestService/Other", Scope: testScopePrefix + "test-echo", Code: codes.PermissionDenied},
+		{Name: "no route", FullMethod: "/learninggoapi.v1.TestService/Unknown", Scope: testScopePrefix + "test-echo", Code: codes.PermissionDenied},
+		{Name: "public", FullMethod: "/grpc.health.v1.Health/Check", Code: codes.OK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.Scope != "" {
+				ctx = context.WithValue(ctx, identityKey{}, &middleware.Identity{Scope: tc.Scope})
+			}
+
+			// act
+			_, err := callWith(UnaryAuthorizer(policy, routes), ctx, tc.FullMethod, metadata.MD{})
+
+			// assert
+			assertCode(t, tc.Code, err)
+		})
+	}
+}
+
+func TestUnaryAuthorizerReturnsApiErrorCode(t *testing.T) {
+	// arrange
+	policy := middleware.NewAuthorizationPolicy(testScopePrefix)
+
+	// act
+	_, err := callWith(UnaryAuthorizer(policy, Routes{}), context.Background(), testMethod, metadata.MD{})
+
+	// assert
+	assertApiErrorCode(t, apierror.CodeForbidden, err)
+}
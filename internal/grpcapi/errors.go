@@ -0,0 +1,72 @@
+package grpcapi
+
+import (
+	"fmt"
+
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+)
+
+// ErrorDomain is the domain of the error info details
+const ErrorDomain = "learninggolang.com"
+
+// grpcCodes maps the API error codes to gRPC status codes. Unknown codes are
+// internal errors.
+var grpcCodes = map[apierror.Code]codes.Code{
+	apierror.CodeBadRequest:        codes.InvalidArgument,
+	apierror.CodeInvalidParameters: codes.InvalidArgument,
+	apierror.CodeInvalidBody:       codes.InvalidArgument,
+	apierror.CodeUnauthorized:      codes.Unauthenticated,
+	apierror.CodeForbidden:         codes.PermissionDenied,
+	apierror.CodeNotFound:          codes.NotFound,
+	apierror.CodeRateLimited:       codes.ResourceExhausted,
+	apierror.CodeQuotaExceeded:     codes.ResourceExhausted,
+	apierror.CodeInternal:          codes.Internal,
+	apierror.CodeConversionFailed:  codes.Unavailable,
+	apierror.CodeRateNotFound:      codes.NotFound,
+}
+
+// Error creates the gRPC status of an API error, with the same semantics as
+// the HTTP problem details: the API error code is the reason of the error
+// info and the field errors are the bad request field violations. The
+// message is the detail, or the title of the code if empty.
+func Error(code apierror.Code, detail string, fieldErrors ...apierror.FieldError) error {
+	grpcCode, found := grpcCodes[code]
+	if !found {
+		grpcCode = codes.Internal
+	}
+	if detail == "" {
+		detail = code.Title()
+	}
+
+	details := []protoiface.MessageV1{&errdetails.ErrorInfo{Reason: string(code), Domain: ErrorDomain}}
+	if len(fieldErrors) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, fe := range fieldErrors {
+			badRequest.FieldViolations = append(badRequest.FieldViolations,
+				&errdetails.BadRequest_FieldViolation{Field: fe.Field, Description: fe.Detail})
+		}
+		details = append(details, badRequest)
+	}
+
+	s := status.New(grpcCode, detail)
+	withDetails, err := s.WithDetails(details...)
+	if err != nil {
+		return s.Err()
+	}
+
+	return withDetails.Err()
+}
+
+// Invalid creates the gRPC status of invalid parameters, like apierror.Invalid.
+func Invalid(fieldErrors ...apierror.FieldError) error {
+	detail := fmt.Sprintf("error: %d parameters are not valid", len(fieldErrors))
+	if len(fieldErrors) == 1 {
+		detail = fieldErrors[0].Detail
+	}
+
+	return Error(apierror.CodeInvalidParameters, detail, fieldErrors...)
+}
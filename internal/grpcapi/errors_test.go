@@ -0,0 +1,73 @@
+package grpcapi
+
+import (
+	"testing"
+
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// assertCode checks the gRPC status code of the error.
+func assertCode(t *testing.T, expected codes.Code, err error) {
+	assert.Equal(t, expected, status.Code(err), "error: %v", err)
+}
+
+// assertApiErrorCode checks the API error code in the error info details.
+func assertApiErrorCode(t *testing.T, expected apierror.Code, err error) {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			assert.Equal(t, string(expected), info.Reason)
+			assert.Equal(t, ErrorDomain, info.Domain)
+			return
+		}
+	}
+
+	assert.Fail(t, "error info not found", "error: %v", err)
+}
+
+func TestError(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Code    apierror.Code
+		Detail  string
+		Status  codes.Code
+		Message string
+	}{
+		{Name: "with detail", Code: apierror.CodeInvalidBody, Detail: "invalid token", Status: codes.InvalidArgument, Message: "invalid token"},
+		{Name: "without detail", Code: apierror.CodeForbidden, Status: codes.PermissionDenied, Message: "Forbidden"},
+		{Name: "upstream", Code: apierror.CodeConversionFailed, Detail: "timeout", Status: codes.Unavailable, Message: "timeout"},
+		{Name: "unknown code", Code: apierror.Code("unknown"), Detail: "boom", Status: codes.Internal, Message: "boom"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// act
+			err := Error(tc.Code, tc.Detail)
+
+			// assert
+			assertCode(t, tc.Status, err)
+			assert.Equal(t, tc.Message, status.Convert(err).Message())
+			assertApiErrorCode(t, tc.Code, err)
+		})
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	// act
+	err := Invalid(apierror.RequiredField("from"), apierror.RequiredField("to"))
+
+	// assert
+	assertCode(t, codes.InvalidArgument, err)
+	assert.Equal(t, "error: 2 parameters are not valid", status.Convert(err).Message())
+	assertApiErrorCode(t, apierror.CodeInvalidParameters, err)
+
+	details := status.Convert(err).Details()
+	assert.Len(t, details, 2)
+	badRequest := details[1].(*errdetails.BadRequest)
+	assert.Len(t, badRequest.FieldViolations, 2)
+	assert.Equal(t, "from", badRequest.FieldViolations[0].Field)
+	assert.Equal(t, "error: 'from' parameter is required", badRequest.FieldViolations[0].Description)
+}
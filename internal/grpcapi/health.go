@@ -0,0 +1,90 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/logging"
+	"github.com/renato0307/learning-go-api/internal/registry"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthServer answers the gRPC health checks with the health checks of the
+// categories. The service names are the gRPC services of the categories, like
+// "learninggoapi.v1.FinanceService", or empty for the whole server.
+type HealthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+
+	checks   map[string]registry.HealthCheck
+	services map[string][]string
+}
+
+// NewHealthServer creates the health server of the mounted categories.
+func NewHealthServer(mounted []registry.Mounted) *HealthServer {
+	s := HealthServer{
+		checks:   map[string]registry.HealthCheck{},
+		services: map[string][]string{"": {}},
+	}
+
+	for _, m := range mounted {
+		s.checks[m.Name] = m.HealthCheck
+		s.services[""] = append(s.services[""], m.Name)
+		for _, method := range m.GrpcMethods {
+			service := strings.Split(strings.TrimPrefix(method.FullMethod, "/"), "/")[0]
+			if !contains(s.services[service], m.Name) {
+				s.services[service] = append(s.services[service], m.Name)
+			}
+		}
+	}
+
+	return &s
+}
+
+// Check reports the service as not serving if any of its categories is
+// unavailable. Degraded categories are still serving.
+//
+// Returns NotFound if the service is unknown.
+func (s *HealthServer) Check(
+	ctx context.Context,
+	req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+
+	categories, found := s.services[req.Service]
+	if !found {
+		return nil, Error(apierror.CodeNotFound, "unknown service "+req.Service)
+	}
+
+	status := grpc_health_v1.HealthCheckResponse_SERVING
+	for _, name := range categories {
+		check := s.checks[name]
+		if check == nil {
+			continue
+		}
+		err := check(ctx)
+		if errors.Is(err, registry.ErrDegraded) {
+			logging.FromContext(ctx).Warn().
+				Err(err).
+				Str("category", name).
+				Msg("category degraded")
+		} else if err != nil {
+			logging.FromContext(ctx).Warn().
+				Err(err).
+				Str("category", name).
+				Msg("category unavailable")
+			status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+	}
+
+	return &grpc_health_v1.HealthCheckResponse{Status: status}, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
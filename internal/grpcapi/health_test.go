@@ -0,0 +1,80 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/renato0307/learning-go-api/internal/registry"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func newTestHealthServer() *HealthServer {
+	failing := func(ctx context.Context) error { return errors.New("upstream down") }
+	degraded := func(ctx context.Context) error { return registry.Degraded(errors.New("upstream down")) }
+
+	return NewHealthServer([]registry.Mounted{
+		{
+			Category: registry.Category{
+				Name:        "finance",
+				GrpcMethods: []registry.GrpcMethod{{FullMethod: "/learninggoapi.v1.FinanceService/ConvertCurrency"}},
+			},
+			HealthCheck: failing,
+		},
+		{
+			Category: registry.Category{
+				Name: "programming",
+				GrpcMethods: []registry.GrpcMethod{
+					{FullMethod: "/learninggoapi.v1.ProgrammingService/NewUuid"},
+					{FullMethod: "/learninggoapi.v1.ProgrammingService/DebugJwt"},
+				},
+			},
+		},
+		{
+			Category: registry.Category{
+				Name:        "text",
+				GrpcMethods: []registry.GrpcMethod{{FullMethod: "/learninggoapi.v1.TextService/Echo"}},
+			},
+			HealthCheck: degraded,
+		},
+	})
+}
+
+func TestHealthServerCheck(t *testing.T) {
+	testCases := []struct {
+		Service string
+		Status  grpc_health_v1.HealthCheckResponse_ServingStatus
+	}{
+		{Service: "", Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING},
+		{Service: "learninggoapi.v1.FinanceService", Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING},
+		{Service: "learninggoapi.v1.ProgrammingService", Status: grpc_health_v1.HealthCheckResponse_SERVING},
+		{Service: "learninggoapi.v1.TextService", Status: grpc_health_v1.HealthCheckResponse_SERVING},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Service, func(t *testing.T) {
+			// arrange
+			s := newTestHealthServer()
+
+			// act
+			resp, err := s.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: tc.Service})
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Status, resp.Status)
+		})
+	}
+}
+
+func TestHealthServerCheckWithUnknownService(t *testing.T) {
+	// arrange
+	s := newTestHealthServer()
+
+	// act
+	_, err := s.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "unknown"})
+
+	// assert
+	assertCode(t, codes.NotFound, err)
+}
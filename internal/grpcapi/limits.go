@@ -0,0 +1,140 @@
+package grpcapi
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/logging"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/usage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryRateLimiter limits the calls per client with the rules and the token
+// buckets of the RateLimiter middleware, keyed by the route mirrored by the
+// method, so HTTP and gRPC calls share the same limits.
+//
+// Responses include the ratelimit-limit, ratelimit-remaining and
+// ratelimit-reset headers, and retry-after when limited. Calls without
+// client (public methods) or not mirroring any route are not limited.
+//
+// Returns ResourceExhausted if the bucket is empty.
+func UnaryRateLimiter(
+	config *middleware.RateLimiterConfig,
+	store middleware.RateLimitStore,
+	routes Routes) grpc.UnaryServerInterceptor {
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		clientId, route, found := clientRoute(ctx, routes, info.FullMethod)
+		if !found {
+			return handler(ctx, req)
+		}
+
+		limit, key := config.Find(clientId, route.path)
+		if limit.Unlimited() {
+			return handler(ctx, req)
+		}
+
+		logger := logging.FromContext(ctx)
+
+		result, err := store.Take(key, limit)
+		if err != nil {
+			// fails open, the store being down should not stop the API
+			logger.Error().Err(err).Str("key", key).Msg("rate limit store error")
+			return handler(ctx, req)
+		}
+
+		header := metadata.Pairs(
+			"ratelimit-limit", strconv.Itoa(limit.Requests),
+			"ratelimit-remaining", strconv.Itoa(result.Remaining),
+			"ratelimit-reset", seconds(result.Reset))
+
+		if !result.Allowed {
+			logger.Debug().Str("key", key).Msg("rate limit exceeded")
+			header.Set("retry-after", seconds(result.RetryAfter))
+			grpc.SetHeader(ctx, header)
+			return nil, Error(apierror.CodeRateLimited, "")
+		}
+
+		grpc.SetHeader(ctx, header)
+		return handler(ctx, req)
+	}
+}
+
+// UnaryMeter counts the successful calls of each client per function and
+// enforces the quotas, like the usage Meter middleware and in the same
+// store, keyed by the route mirrored by the method, so HTTP and gRPC calls
+// share the same quotas.
+//
+// Calls without client (public methods) or not mirroring any route are not
+// counted.
+//
+// Returns ResourceExhausted if the quota is exceeded.
+func UnaryMeter(qc *usage.QuotaConfig, s usage.Store, routes Routes) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		clientId, route, found := clientRoute(ctx, routes, info.FullMethod)
+		if !found {
+			return handler(ctx, req)
+		}
+
+		logger := logging.FromContext(ctx)
+
+		now := time.Now()
+		reserved, err := s.Reserve(clientId, route.path, qc.Find(clientId, route.path), now)
+		if err != nil {
+			// fails open, the store being down should not stop the API
+			logger.Error().Err(err).Msg("usage store error")
+			return handler(ctx, req)
+		}
+
+		if !reserved {
+			logger.Debug().
+				Str("client_id", clientId).
+				Str("function", route.path).
+				Msg("quota exceeded")
+			return nil, Error(apierror.CodeQuotaExceeded, "")
+		}
+
+		resp, err := handler(ctx, req)
+
+		// counts only the successful calls
+		if err != nil {
+			if releaseErr := s.Release(clientId, route.path, now); releaseErr != nil {
+				logger.Error().Err(releaseErr).Msg("usage store error")
+			}
+		}
+
+		return resp, err
+	}
+}
+
+// clientRoute returns the authenticated client and the route mirrored by the
+// method, false if the call has no client or the method mirrors no route.
+func clientRoute(ctx context.Context, routes Routes, fullMethod string) (string, route, bool) {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok || identity.ClientId == "" {
+		return "", route{}, false
+	}
+
+	r, found := routes[fullMethod]
+	return identity.ClientId, r, found
+}
+
+// seconds formats a duration as whole seconds, rounding up.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,97 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/registry"
+	"github.com/renato0307/learning-go-api/internal/usage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// newTestRoutes maps the testMethod to the "GET /v1/test/echo" route.
+func newTestRoutes() Routes {
+	routes := Routes{}
+	routes.Add("/v1/test", registry.GrpcMethod{FullMethod: testMethod, Method: "GET", Path: "/echo"})
+	return routes
+}
+
+func newTestUsageStore(t *testing.T) usage.Store {
+	s, err := usage.NewBoltStore(filepath.Join(t.TempDir(), "usage.db"))
+	assert.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+// callAs runs the interceptor on a call to the testMethod by the client,
+// with a handler returning the handlerErr.
+func callAs(interceptor grpc.UnaryServerInterceptor, clientId string, handlerErr error) error {
+	ctx := context.WithValue(context.Background(), identityKey{}, &middleware.Identity{ClientId: clientId})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", handlerErr
+	}
+
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, handler)
+	return err
+}
+
+func TestUnaryRateLimiter(t *testing.T) {
+	// arrange - one call per hour in the test group
+	config := &middleware.RateLimiterConfig{Rules: []middleware.RateLimitRule{
+		{Group: "/v1/test", RateLimit: middleware.RateLimit{Requests: 1, Period: middleware.Duration(time.Hour)}},
+	}}
+	interceptor := UnaryRateLimiter(config, middleware.NewMemoryRateLimitStore(), newTestRoutes())
+
+	// act & assert
+	assert.NoError(t, callAs(interceptor, "client-a", nil))
+
+	err := callAs(interceptor, "client-a", nil)
+	assertCode(t, codes.ResourceExhausted, err)
+	assertApiErrorCode(t, apierror.CodeRateLimited, err)
+
+	assert.NoError(t, callAs(interceptor, "client-b", nil), "other clients have their own bucket")
+	assert.NoError(t, callAs(interceptor, "", nil), "calls without client are not limited")
+}
+
+func TestUnaryMeter(t *testing.T) {
+	// arrange - one call per day to the echo route
+	s := newTestUsageStore(t)
+	qc := &usage.QuotaConfig{Rules: []usage.QuotaRule{
+		{Function: "/v1/test/echo", Quota: usage.Quota{Daily: 1}},
+	}}
+	interceptor := UnaryMeter(qc, s, newTestRoutes())
+
+	// act & assert - failed calls are not counted
+	assertCode(t, codes.Internal, callAs(interceptor, "client-a", Error(apierror.CodeInternal, "")))
+	assert.NoError(t, callAs(interceptor, "client-a", nil))
+
+	err := callAs(interceptor, "client-a", nil)
+	assertCode(t, codes.ResourceExhausted, err)
+	assertApiErrorCode(t, apierror.CodeQuotaExceeded, err)
+
+	count, _ := s.Get("client-a", "/v1/test/echo", usage.Day, time.Now())
+	assert.Equal(t, uint64(1), count)
+}
+
+func TestUnaryRecovery(t *testing.T) {
+	// arrange
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic(errors.New("fake panic"))
+	}
+
+	// act
+	resp, err := UnaryRecovery()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, handler)
+
+	// assert
+	assert.Nil(t, resp)
+	assertCode(t, codes.Internal, err)
+	assertApiErrorCode(t, apierror.CodeInternal, err)
+}
@@ -0,0 +1,64 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/renato0307/learning-go-api/internal/logging"
+	"github.com/renato0307/learning-go-api/internal/requestid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIdMetadata is the metadata key of the request ID, the lower case
+// form of its HTTP header
+const requestIdMetadata = "x-request-id"
+
+// UnaryLogger gives each call a request ID and logs it in JSON format, like
+// the RequestID and StructuredLogger middlewares. The request ID is read from
+// the call metadata if valid and sent back in the response header.
+func UnaryLogger(logger *zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		start := time.Now()
+
+		requestId := ""
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get(requestIdMetadata); len(values) > 0 && requestid.IsValid(values[0]) {
+			requestId = values[0]
+		} else {
+			requestId = requestid.New()
+		}
+		grpc.SetHeader(ctx, metadata.Pairs(requestIdMetadata, requestId))
+
+		callLogger := logger.With().Str(requestid.Key, requestId).Logger()
+		ctx = logging.NewContext(ctx, callLogger)
+
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		logEvent := callLogger.Info()
+		if code == codes.Internal || code == codes.Unknown {
+			logEvent = callLogger.Error()
+		}
+		logEvent.Str("method", info.FullMethod).
+			Str("code", code.String()).
+			Str("latency", time.Since(start).String()).
+			Msg("")
+
+		return resp, err
+	}
+}
+
+// DefaultUnaryLogger logs the calls with the default logger from rs/zerolog.
+func DefaultUnaryLogger() grpc.UnaryServerInterceptor {
+	return UnaryLogger(&log.Logger)
+}
@@ -0,0 +1,56 @@
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/logging"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryLogger(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Metadata  metadata.MD
+		RequestId string
+	}{
+		{Name: "client request id", Metadata: metadata.Pairs("x-request-id", "client-request-1"), RequestId: "client-request-1"},
+		{Name: "invalid request id", Metadata: metadata.Pairs("x-request-id", "forged\tid")},
+		{Name: "missing request id", Metadata: metadata.MD{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			buffer := new(bytes.Buffer)
+			logger := zerolog.New(buffer)
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				logging.FromContext(ctx).Info().Msg("handler line")
+				return nil, Error(apierror.CodeForbidden, "")
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), tc.Metadata)
+			info := &grpc.UnaryServerInfo{FullMethod: testMethod}
+
+			// act
+			UnaryLogger(&logger)(ctx, nil, info, handler)
+
+			// assert
+			lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+			assert.Len(t, lines, 2)
+			assert.Contains(t, string(lines[0]), "handler line")
+			assert.Contains(t, string(lines[1]), `"method":"`+testMethod+`"`)
+			assert.Contains(t, string(lines[1]), `"code":"PermissionDenied"`)
+			if tc.RequestId != "" {
+				assert.Contains(t, string(lines[0]), `"request_id":"`+tc.RequestId+`"`)
+			} else {
+				assert.Contains(t, string(lines[0]), `"request_id":"`)
+				assert.NotContains(t, string(lines[0]), "forged")
+			}
+		})
+	}
+}
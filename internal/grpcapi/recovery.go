@@ -0,0 +1,35 @@
+package grpcapi
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/logging"
+	"google.golang.org/grpc"
+)
+
+// UnaryRecovery recovers from panics in the handlers, like the Recovery
+// middleware, so a failing call does not stop the server.
+//
+// Returns Internal if the handler panics.
+func UnaryRecovery() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (resp interface{}, err error) {
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logging.FromContext(ctx).Error().
+					Interface("panic", recovered).
+					Bytes("stack", debug.Stack()).
+					Msg("recovered from panic")
+				resp, err = nil, Error(apierror.CodeInternal, "")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
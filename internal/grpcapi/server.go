@@ -0,0 +1,32 @@
+// Package grpcapi serves the functions over gRPC, authenticating and
+// authorizing the calls like the HTTP API.
+package grpcapi
+
+import (
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/usage"
+	"google.golang.org/grpc"
+)
+
+// NewServer creates a gRPC server logging the calls, checking their token and
+// scopes, and enforcing the rate limits and quotas with the rules and stores
+// of the HTTP API. The category services are registered when the categories
+// are mounted, and their methods added to the routes before serving.
+func NewServer(
+	ac *middleware.AuthenticatorConfig,
+	policy *middleware.AuthorizationPolicy,
+	rateLimits *middleware.RateLimiterConfig,
+	rateLimitStore middleware.RateLimitStore,
+	quotas *usage.QuotaConfig,
+	usageStore usage.Store,
+	routes Routes) *grpc.Server {
+
+	return grpc.NewServer(grpc.ChainUnaryInterceptor(
+		DefaultUnaryLogger(),
+		UnaryAuthenticator(ac),
+		UnaryAuthorizer(policy, routes),
+		UnaryRateLimiter(rateLimits, rateLimitStore, routes),
+		UnaryMeter(quotas, usageStore, routes),
+		UnaryRecovery(),
+	))
+}
@@ -0,0 +1,114 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/usage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// testServiceDesc describes the TestService, with an Echo method calling the
+// function served
+var testServiceDesc = grpc.ServiceDesc{
+	ServiceName: "learninggoapi.v1.TestService",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Echo",
+		Handler: func(
+			srv interface{},
+			ctx context.Context,
+			dec func(interface{}) error,
+			interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+
+			in := &emptypb.Empty{}
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				srv.(func())()
+				return &emptypb.Empty{}, nil
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: testMethod}, handler)
+		},
+	}},
+}
+
+// newTestServer serves the TestService with the echo function through a
+// NewServer with the quotas, returning a connection to it.
+func newTestServer(
+	t *testing.T,
+	ac *middleware.AuthenticatorConfig,
+	quotas *usage.QuotaConfig,
+	echo func()) *grpc.ClientConn {
+
+	policy := middleware.NewAuthorizationPolicy(testScopePrefix)
+	policy.Register("/v1/test",
+		middleware.RoutePolicy{Method: "GET", Path: "/echo", Scopes: []string{"test-echo"}})
+
+	server := NewServer(ac, policy,
+		&middleware.RateLimiterConfig{}, middleware.NewMemoryRateLimitStore(),
+		quotas, newTestUsageStore(t), newTestRoutes())
+	server.RegisterService(&testServiceDesc, echo)
+
+	listener := bufconn.Listen(1024 * 1024)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestNewServerEnforcesQuotas(t *testing.T) {
+	// arrange - one call per day
+	ac, key := newTestAuthConfig(t)
+	quotas := &usage.QuotaConfig{Rules: []usage.QuotaRule{
+		{Function: "/v1/test/echo", Quota: usage.Quota{Daily: 1}},
+	}}
+	conn := newTestServer(t, ac, quotas, func() {})
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"authorization", "Bearer "+newTestJWT(t, key, testScopePrefix+"test-echo"))
+
+	// act
+	firstErr := conn.Invoke(ctx, testMethod, &emptypb.Empty{}, &emptypb.Empty{})
+	secondErr := conn.Invoke(ctx, testMethod, &emptypb.Empty{}, &emptypb.Empty{})
+
+	// assert
+	assert.NoError(t, firstErr)
+	assertCode(t, codes.ResourceExhausted, secondErr)
+	assertApiErrorCode(t, apierror.CodeQuotaExceeded, secondErr)
+}
+
+func TestNewServerRecoversFromPanics(t *testing.T) {
+	// arrange
+	ac, key := newTestAuthConfig(t)
+	conn := newTestServer(t, ac, &usage.QuotaConfig{}, func() { panic("fake panic") })
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"authorization", "Bearer "+newTestJWT(t, key, testScopePrefix+"test-echo"))
+
+	// act
+	firstErr := conn.Invoke(ctx, testMethod, &emptypb.Empty{}, &emptypb.Empty{})
+	secondErr := conn.Invoke(ctx, testMethod, &emptypb.Empty{}, &emptypb.Empty{})
+
+	// assert - the server keeps serving
+	assertCode(t, codes.Internal, firstErr)
+	assertApiErrorCode(t, apierror.CodeInternal, firstErr)
+	assertCode(t, codes.Internal, secondErr)
+}
@@ -0,0 +1,36 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// FromContext returns the request-scoped logger stored in the context, or the
+// default logger if there is none. The trace and span identifiers of the
+// context, if any, are added so log lines can be correlated with the traces.
+func FromContext(ctx context.Context) *zerolog.Logger {
+	logger := zerolog.Ctx(ctx)
+	if logger.GetLevel() == zerolog.Disabled {
+		logger = &log.Logger
+	}
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return logger
+	}
+
+	spanLogger := logger.With().
+		Str("trace_id", spanContext.TraceID().String()).
+		Str("span_id", spanContext.SpanID().String()).
+		Logger()
+
+	return &spanLogger
+}
+
+// NewContext returns a copy of the context storing the logger.
+func NewContext(ctx context.Context, logger zerolog.Logger) context.Context {
+	return logger.WithContext(ctx)
+}
@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFromContext(t *testing.T) {
+	// arrange
+	buffer := new(bytes.Buffer)
+	logger := zerolog.New(buffer).With().Str("request_id", "fake-id").Logger()
+	ctx := NewContext(context.Background(), logger)
+
+	// act
+	FromContext(ctx).Info().Msg("request scoped")
+
+	// assert
+	assert.Contains(t, buffer.String(), `"request_id":"fake-id"`)
+	assert.NotContains(t, buffer.String(), "trace_id")
+}
+
+func TestFromContextWithoutLogger(t *testing.T) {
+	// arrange
+	buffer := new(bytes.Buffer)
+	defaultLogger := log.Logger
+	log.Logger = zerolog.New(buffer)
+	defer func() { log.Logger = defaultLogger }()
+
+	// act
+	FromContext(context.Background()).Info().Msg("default")
+
+	// assert
+	assert.Contains(t, buffer.String(), "default")
+}
+
+func TestFromContextWithSpan(t *testing.T) {
+	// arrange
+	buffer := new(bytes.Buffer)
+	ctx := NewContext(context.Background(), zerolog.New(buffer))
+
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx = trace.ContextWithSpanContext(
+		ctx,
+		trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceId,
+			SpanID:  spanId,
+		}))
+
+	// act
+	FromContext(ctx).Info().Msg("with span")
+
+	// assert
+	assert.Contains(t, buffer.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+	assert.Contains(t, buffer.String(), `"span_id":"00f067aa0ba902b7"`)
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/openapi"
+	"github.com/renato0307/learning-go-api/internal/requestid"
+)
+
+// Path is the path of the streamable HTTP transport
+const Path = "/mcp"
+
+// RoutePolicies lets any authenticated client use the transport, each tool
+// call is authorized with the scopes of its function
+var RoutePolicies = []middleware.RoutePolicy{
+	{Method: "POST", Path: Path, Public: true},
+}
+
+// RouteDocs documents the streamable HTTP transport in the OpenAPI document
+var RouteDocs = []openapi.Route{
+	{
+		Method:      "POST",
+		Path:        Path,
+		Summary:     "Serves the functions as Model Context Protocol tools",
+		Description: "Streamable HTTP transport of the Model Context Protocol, taking JSON-RPC 2.0 messages. Each tool call requires the scopes of its function.",
+		Tags:        []string{"mcp"},
+		RequestBody: &openapi.RequestBody{
+			Description: "A JSON-RPC 2.0 request, notification or batch",
+			Required:    true,
+			Content: map[string]openapi.MediaType{
+				"application/json": {Schema: &openapi.Schema{Type: "object"}},
+			},
+		},
+		Output: map[string]interface{}{},
+	},
+}
+
+// HttpHandler handles the streamable HTTP transport, with the messages posted
+// by the client and the responses returned as JSON.
+//
+// The tools are called with the credentials of the MCP request, so they are
+// authorized with the scopes of the functions.
+// Returns HTTP 202 if the message holds only notifications.
+// Returns HTTP 400 if the message is not valid JSON.
+func (s *Server) HttpHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		message, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, newError(nil, errorParse, "error reading body"))
+			return
+		}
+
+		header := c.Request.Header.Clone()
+		header.Set(requestid.Header, requestid.Get(c))
+
+		r := s.handleMessage(c.Request.Context(), header, message)
+		if r == nil {
+			c.Status(http.StatusAccepted)
+			return
+		}
+
+		if single, ok := r.(*response); ok && single.Error != nil && single.Error.Code == errorParse {
+			c.JSON(http.StatusBadRequest, r)
+			return
+		}
+
+		c.JSON(http.StatusOK, r)
+	}
+}
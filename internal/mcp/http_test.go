@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpHandler(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Scopes   string
+		Message  string
+		Status   int
+		Contains string
+	}{
+		{
+			Name:     "request",
+			Message:  `{"jsonrpc":"2.0","id":1,"method":"ping"}`,
+			Status:   http.StatusOK,
+			Contains: `"result":{}`,
+		},
+		{
+			Name:     "granted tool",
+			Scopes:   "text-echo",
+			Message:  `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"text_echo","arguments":{"value":"hi"}}}`,
+			Status:   http.StatusOK,
+			Contains: `"isError":false`,
+		},
+		{
+			Name:     "forbidden tool",
+			Scopes:   "text-reverse",
+			Message:  `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"text_echo","arguments":{"value":"hi"}}}`,
+			Status:   http.StatusOK,
+			Contains: `"isError":true`,
+		},
+		{
+			Name:    "notification",
+			Message: `{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+			Status:  http.StatusAccepted,
+		},
+		{
+			Name:     "invalid json",
+			Message:  `{"jsonrpc"`,
+			Status:   http.StatusBadRequest,
+			Contains: `"code":-32700`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			r, mounted := setupTestGin()
+			s := NewServer("test", "1.0.0", ToolsFor(mounted), r)
+			r.POST(Path, s.HttpHandler())
+
+			req := httptest.NewRequest("POST", Path, strings.NewReader(tc.Message))
+			req.Header.Set("Authorization", withPrefix(tc.Scopes))
+			w := httptest.NewRecorder()
+
+			// act
+			r.ServeHTTP(w, req)
+
+			// assert
+			assert.Equal(t, tc.Status, w.Code)
+			assert.Contains(t, w.Body.String(), tc.Contains)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package mcp
+
+import "encoding/json"
+
+// jsonRpcVersion is the only JSON-RPC version supported
+const jsonRpcVersion = "2.0"
+
+// JSON-RPC error codes
+const (
+	errorParse          = -32700
+	errorInvalidRequest = -32600
+	errorMethodNotFound = -32601
+	errorInvalidParams  = -32602
+	errorInternal       = -32603
+)
+
+// request is a JSON-RPC request, or a notification if it has no ID
+type request struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// isNotification tells if the sender expects no response
+func (r request) isNotification() bool {
+	return len(r.Id) == 0
+}
+
+// response is a JSON-RPC response, with either a result or an error
+type response struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC error
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func newResult(id json.RawMessage, result interface{}) *response {
+	return &response{JsonRpc: jsonRpcVersion, Id: id, Result: result}
+}
+
+func newError(id json.RawMessage, code int, message string) *response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+
+	return &response{
+		JsonRpc: jsonRpcVersion,
+		Id:      id,
+		Error:   &rpcError{Code: code, Message: message},
+	}
+}
@@ -0,0 +1,191 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/renato0307/learning-go-api/internal/dispatch"
+	"github.com/renato0307/learning-go-api/internal/logging"
+)
+
+// ProtocolVersions are the MCP protocol versions supported, the latest first
+var ProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// Server answers the MCP requests, calling the functions through an HTTP
+// handler so the tools share the validation and the errors of the routes
+type Server struct {
+	name    string
+	version string
+	tools   []Tool
+	handler http.Handler
+}
+
+// NewServer creates a server with the tools, calling the functions on the
+// handler.
+func NewServer(name, version string, tools []Tool, handler http.Handler) *Server {
+	return &Server{name: name, version: version, tools: tools, handler: handler}
+}
+
+type initializeParams struct {
+	ProtocolVersion string `json:"protocolVersion"`
+}
+
+type implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type initializeResult struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ServerInfo      implementation         `json:"serverInfo"`
+}
+
+type listToolsResult struct {
+	Tools []Tool `json:"tools"`
+}
+
+type callToolParams struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type callToolResult struct {
+	Content           []content   `json:"content"`
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError"`
+}
+
+// handleMessage handles a message holding a request or a batch of requests.
+// Returns the responses to write, nil if there are none.
+func (s *Server) handleMessage(ctx context.Context, header http.Header, message []byte) interface{} {
+	message = bytes.TrimSpace(message)
+	if len(message) > 0 && message[0] == '[' {
+		batch := []json.RawMessage{}
+		if err := json.Unmarshal(message, &batch); err != nil {
+			return newError(nil, errorParse, "error: the message is not valid JSON")
+		}
+
+		responses := []*response{}
+		for _, item := range batch {
+			if r := s.handleRequest(ctx, header, item); r != nil {
+				responses = append(responses, r)
+			}
+		}
+		if len(responses) == 0 {
+			return nil
+		}
+		return responses
+	}
+
+	if r := s.handleRequest(ctx, header, message); r != nil {
+		return r
+	}
+	return nil
+}
+
+// handleRequest handles a JSON-RPC request. Returns nil for notifications.
+func (s *Server) handleRequest(ctx context.Context, header http.Header, message []byte) *response {
+	req := request{}
+	if err := json.Unmarshal(message, &req); err != nil {
+		return newError(nil, errorParse, "error: the message is not valid JSON")
+	}
+
+	if req.JsonRpc != jsonRpcVersion || req.Method == "" {
+		return newError(req.Id, errorInvalidRequest, "error: the message is not a valid JSON-RPC 2.0 request")
+	}
+
+	logger := logging.FromContext(ctx)
+	logger.Debug().Str("method", req.Method).Msg("handling mcp request")
+
+	if req.isNotification() {
+		return nil
+	}
+
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req)
+	case "ping":
+		return newResult(req.Id, struct{}{})
+	case "tools/list":
+		return newResult(req.Id, listToolsResult{Tools: s.tools})
+	case "tools/call":
+		return s.callTool(ctx, header, req)
+	}
+
+	return newError(req.Id, errorMethodNotFound, fmt.Sprintf("error: method '%s' not found", req.Method))
+}
+
+// initialize agrees on the protocol version, which is the one requested by the
+// client if supported or the latest one otherwise.
+func (s *Server) initialize(req request) *response {
+	params := initializeParams{}
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return newError(req.Id, errorInvalidParams, "error: invalid initialize parameters")
+		}
+	}
+
+	version := ProtocolVersions[0]
+	for _, v := range ProtocolVersions {
+		if v == params.ProtocolVersion {
+			version = v
+		}
+	}
+
+	return newResult(req.Id, initializeResult{
+		ProtocolVersion: version,
+		Capabilities: map[string]interface{}{
+			"tools": map[string]interface{}{"listChanged": false},
+		},
+		ServerInfo: implementation{Name: s.name, Version: s.version},
+	})
+}
+
+// callTool calls the function of the tool. The errors returned by the function
+// are tool errors holding the problem details, so the model can read them.
+func (s *Server) callTool(ctx context.Context, header http.Header, req request) *response {
+	params := callToolParams{}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return newError(req.Id, errorInvalidParams, "error: invalid tool call parameters")
+	}
+
+	var tool *Tool
+	for i := range s.tools {
+		if s.tools[i].Name == params.Name {
+			tool = &s.tools[i]
+		}
+	}
+	if tool == nil {
+		return newError(req.Id, errorInvalidParams, fmt.Sprintf("error: tool '%s' not found", params.Name))
+	}
+
+	functionReq, err := tool.newRequest(ctx, params.Arguments)
+	if err != nil {
+		return newError(req.Id, errorInvalidParams, fmt.Sprintf("error: %s", err.Error()))
+	}
+	dispatch.Forward(header, functionReq)
+	w := dispatch.Do(s.handler, functionReq)
+
+	result := callToolResult{
+		Content: []content{{Type: "text", Text: string(w.Body)}},
+		IsError: w.IsError(),
+	}
+	if !result.IsError && strings.HasPrefix(w.Header.Get("Content-Type"), "application/json") {
+		structured := map[string]interface{}{}
+		if err := json.Unmarshal(w.Body, &structured); err == nil {
+			result.StructuredContent = structured
+		}
+	}
+
+	return newResult(req.Id, result)
+}
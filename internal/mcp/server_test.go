@@ -0,0 +1,235 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/stretchr/testify/assert"
+)
+
+// rpcResponse is a JSON-RPC response as read by the clients
+type rpcResponse struct {
+	JsonRpc string                 `json:"jsonrpc"`
+	Id      interface{}            `json:"id"`
+	Result  map[string]interface{} `json:"result"`
+	Error   *rpcError              `json:"error"`
+}
+
+func newTestServer() *Server {
+	r, mounted := setupTestGin()
+	return NewServer("test", "1.0.0", ToolsFor(mounted), r)
+}
+
+// handle sends the message to the server with the scopes in the
+// Authorization header.
+func handle(t *testing.T, s *Server, scopes, message string) rpcResponse {
+	header := http.Header{}
+	header.Set("Authorization", withPrefix(scopes))
+	r := s.handleMessage(context.Background(), header, []byte(message))
+
+	data, err := json.Marshal(r)
+	assert.NoError(t, err)
+	response := rpcResponse{}
+	assert.NoError(t, json.Unmarshal(data, &response))
+
+	return response
+}
+
+// withPrefix adds the scope prefix of the test policy to the scopes.
+func withPrefix(scopes string) string {
+	if scopes == "" {
+		return ""
+	}
+	return testScopePrefix + scopes
+}
+
+func TestInitialize(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Requested string
+		Expected  string
+	}{
+		{Name: "latest", Requested: "2025-06-18", Expected: "2025-06-18"},
+		{Name: "older", Requested: "2024-11-05", Expected: "2024-11-05"},
+		{Name: "unsupported", Requested: "2000-01-01", Expected: ProtocolVersions[0]},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			s := newTestServer()
+			message := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"` +
+				tc.Requested + `","capabilities":{},"clientInfo":{"name":"client","version":"1"}}}`
+
+			// act
+			response := handle(t, s, "", message)
+
+			// assert
+			assert.Nil(t, response.Error)
+			assert.Equal(t, float64(1), response.Id)
+			assert.Equal(t, tc.Expected, response.Result["protocolVersion"])
+			assert.Contains(t, response.Result["capabilities"], "tools")
+			assert.Equal(t, map[string]interface{}{"name": "test", "version": "1.0.0"},
+				response.Result["serverInfo"])
+		})
+	}
+}
+
+func TestInvalidMessages(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Message string
+		Code    int
+	}{
+		{Name: "not json", Message: `{"jsonrpc":`, Code: errorParse},
+		{Name: "no version", Message: `{"id":1,"method":"ping"}`, Code: errorInvalidRequest},
+		{Name: "unknown method", Message: `{"jsonrpc":"2.0","id":1,"method":"resources/list"}`, Code: errorMethodNotFound},
+		{Name: "unknown tool", Message: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"text_unknown"}}`, Code: errorInvalidParams},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			s := newTestServer()
+
+			// act
+			response := handle(t, s, "", tc.Message)
+
+			// assert
+			assert.NotNil(t, response.Error)
+			assert.Equal(t, tc.Code, response.Error.Code)
+		})
+	}
+}
+
+func TestNotification(t *testing.T) {
+	// arrange
+	s := newTestServer()
+
+	// act
+	r := s.handleMessage(context.Background(), http.Header{},
+		[]byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+
+	// assert
+	assert.Nil(t, r)
+}
+
+func TestListTools(t *testing.T) {
+	// arrange
+	s := newTestServer()
+
+	// act
+	response := handle(t, s, "", `{"jsonrpc":"2.0","id":"a","method":"tools/list"}`)
+
+	// assert
+	assert.Nil(t, response.Error)
+	assert.Equal(t, "a", response.Id)
+	tools := response.Result["tools"].([]interface{})
+	assert.Len(t, tools, 2)
+	assert.Equal(t, "text_echo", tools[0].(map[string]interface{})["name"])
+}
+
+func TestCallTool(t *testing.T) {
+	// arrange
+	s := newTestServer()
+	message := `{"jsonrpc":"2.0","id":2,"method":"tools/call",` +
+		`"params":{"name":"text_echo","arguments":{"value":"hello","upper":true}}}`
+
+	// act
+	response := handle(t, s, "text-echo", message)
+
+	// assert
+	assert.Nil(t, response.Error)
+	assert.Equal(t, false, response.Result["isError"])
+	assert.Equal(t, map[string]interface{}{"value": "hello/true"}, response.Result["structuredContent"])
+	content := response.Result["content"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "text", content["type"])
+	assert.JSONEq(t, `{"value":"hello/true"}`, content["text"].(string))
+}
+
+func TestCallToolWithBody(t *testing.T) {
+	// arrange
+	s := newTestServer()
+	message := `{"jsonrpc":"2.0","id":2,"method":"tools/call",` +
+		`"params":{"name":"text_reverse","arguments":{"body":"abc"}}}`
+
+	// act
+	response := handle(t, s, "text-reverse", message)
+
+	// assert
+	assert.Nil(t, response.Error)
+	assert.Equal(t, map[string]interface{}{"value": "cba"}, response.Result["structuredContent"])
+}
+
+func TestCallToolWithErrors(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Scopes    string
+		Arguments string
+		Code      apierror.Code
+	}{
+		{Name: "invalid arguments", Scopes: "text-echo", Arguments: `{}`, Code: apierror.CodeInvalidParameters},
+		{Name: "missing scope", Scopes: "text-reverse", Arguments: `{"value":"hello"}`, Code: apierror.CodeForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			s := newTestServer()
+			message := `{"jsonrpc":"2.0","id":3,"method":"tools/call",` +
+				`"params":{"name":"text_echo","arguments":` + tc.Arguments + `}}`
+
+			// act
+			response := handle(t, s, tc.Scopes, message)
+
+			// assert
+			assert.Nil(t, response.Error)
+			assert.Equal(t, true, response.Result["isError"])
+			assert.NotContains(t, response.Result, "structuredContent")
+			content := response.Result["content"].([]interface{})[0].(map[string]interface{})
+			apierror.AssertHasCode(t, []byte(content["text"].(string)), tc.Code)
+		})
+	}
+}
+
+func TestBatch(t *testing.T) {
+	// arrange
+	s := newTestServer()
+	message := `[{"jsonrpc":"2.0","id":1,"method":"ping"},` +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"},` +
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}]`
+
+	// act
+	r := s.handleMessage(context.Background(), http.Header{}, []byte(message))
+
+	// assert
+	responses := r.([]*response)
+	assert.Len(t, responses, 2)
+	assert.Equal(t, json.RawMessage("1"), responses[0].Id)
+	assert.Equal(t, json.RawMessage("2"), responses[1].Id)
+}
+
+func TestServeStdio(t *testing.T) {
+	// arrange
+	s := newTestServer()
+	in := strings.NewReader(
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}` + "\n" +
+			`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n" +
+			`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"text_echo","arguments":{"value":"hi"}}}`)
+	out := new(bytes.Buffer)
+
+	// act
+	err := s.ServeStdio(context.Background(), in, out)
+
+	// assert
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"protocolVersion":"2025-06-18"`)
+	assert.Contains(t, lines[1], `"id":2`)
+}
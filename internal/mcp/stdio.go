@@ -0,0 +1,39 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// ServeStdio reads newline delimited messages from in and writes the responses
+// to out, one per line, until in is closed or the context is done.
+//
+// There is no authentication on stdio, the client is the process that started
+// the server.
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	encoder := json.NewEncoder(out)
+
+	for ctx.Err() == nil {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if r := s.handleMessage(ctx, http.Header{}, line); r != nil {
+				if err := encoder.Encode(r); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/renato0307/learning-go-api/internal/dispatch"
+	"github.com/renato0307/learning-go-api/internal/openapi"
+	"github.com/renato0307/learning-go-api/internal/registry"
+)
+
+// bodyArgument names the tool argument sent as the request body
+const bodyArgument = "body"
+
+// Tool calls a function of a category, described by the function docs
+type Tool struct {
+	Name         string          `json:"name"`
+	Description  string          `json:"description,omitempty"`
+	InputSchema  *openapi.Schema `json:"inputSchema"`
+	OutputSchema *openapi.Schema `json:"outputSchema,omitempty"`
+
+	// the route of the function
+	method          string
+	path            string
+	parameters      []openapi.Parameter
+	bodyContentType string
+}
+
+// ToolsFor creates a tool for each function of the categories, named after
+// the category and the function, as in "finance_currconv" or
+// "finance_rates_base_history" for "/rates/:base/history". Functions sharing
+// a path are told apart by their method, except the GET one, as in
+// "finance_currconv_post".
+func ToolsFor(mounted []registry.Mounted) []Tool {
+	tools := []Tool{}
+	for _, m := range mounted {
+		routesByPath := map[string]int{}
+		for _, route := range m.Docs {
+			routesByPath[route.Path]++
+		}
+
+		for _, route := range m.Docs {
+			name := m.Name + "_" + strings.NewReplacer("/", "_", "-", "_", ":", "", "*", "").
+				Replace(strings.Trim(route.Path, "/"))
+			if routesByPath[route.Path] > 1 && !strings.EqualFold(route.Method, http.MethodGet) {
+				name += "_" + strings.ToLower(route.Method)
+			}
+
+			tool := Tool{
+				Name:        name,
+				Description: route.Summary,
+				InputSchema: &openapi.Schema{
+					Type:       "object",
+					Properties: map[string]*openapi.Schema{},
+				},
+				method:     strings.ToUpper(route.Method),
+				path:       path.Join(m.Group.BasePath(), route.Path),
+				parameters: route.Parameters,
+			}
+
+			if route.Description != "" {
+				tool.Description += ". " + route.Description
+			}
+
+			for _, parameter := range route.Parameters {
+				schema := *parameter.Schema
+				schema.Description = parameter.Description
+				tool.InputSchema.Properties[parameter.Name] = &schema
+				if parameter.Required {
+					tool.InputSchema.Required = append(tool.InputSchema.Required, parameter.Name)
+				}
+			}
+
+			if route.RequestBody != nil {
+				for contentType, mediaType := range route.RequestBody.Content {
+					schema := openapi.Schema{Type: "string"}
+					if mediaType.Schema != nil {
+						schema = *mediaType.Schema
+					}
+					schema.Description = route.RequestBody.Description
+					tool.InputSchema.Properties[bodyArgument] = &schema
+					tool.bodyContentType = contentType
+				}
+				if route.RequestBody.Required {
+					tool.InputSchema.Required = append(tool.InputSchema.Required, bodyArgument)
+				}
+			}
+
+			if route.Output != nil {
+				tool.OutputSchema = openapi.InlineSchemaOf(route.Output)
+			}
+
+			tools = append(tools, tool)
+		}
+	}
+
+	return tools
+}
+
+// newRequest creates the request calling the function with the arguments.
+// The arguments are not validated here, that is left to the function handler
+// so the tools behave like the routes.
+func (t Tool) newRequest(ctx context.Context, args map[string]interface{}) (*http.Request, error) {
+	target := t.path
+	query := url.Values{}
+	for _, parameter := range t.parameters {
+		value, found := args[parameter.Name]
+		if !found || value == nil {
+			continue
+		}
+
+		if parameter.In == "path" {
+			target = strings.Replace(target, ":"+parameter.Name,
+				url.PathEscape(dispatch.FormatValue(value)), 1)
+			continue
+		}
+		query.Set(parameter.Name, dispatch.FormatValue(value))
+	}
+
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	body := new(bytes.Buffer)
+	if value, found := args[bodyArgument]; found && t.bodyContentType != "" {
+		if text, isString := value.(string); isString && t.bodyContentType == "text/plain" {
+			body.WriteString(text)
+		} else if err := json.NewEncoder(body).Encode(value); err != nil {
+			return nil, fmt.Errorf("cannot encode the body: %s", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, t.method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if t.bodyContentType != "" {
+		req.Header.Set("Content-Type", t.bodyContentType)
+	}
+
+	return req, nil
+}
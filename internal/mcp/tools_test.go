@@ -0,0 +1,194 @@
+package mcp
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/openapi"
+	"github.com/renato0307/learning-go-api/internal/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+type echoOutput struct {
+	Value string `json:"value"`
+}
+
+const testScopePrefix = "https://example.com/"
+
+var testPolicies = []middleware.RoutePolicy{
+	{Method: "GET", Path: "/echo", Scopes: []string{"text-echo"}},
+	{Method: "POST", Path: "/reverse", Scopes: []string{"text-reverse"}},
+}
+
+var testDocs = []openapi.Route{
+	{
+		Method:  "GET",
+		Path:    "/echo",
+		Summary: "Echoes a value",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParameter("value", openapi.String("Value to echo"), true),
+			openapi.QueryParameter("upper", openapi.Boolean("Echoes in upper case"), false),
+		},
+		Output: echoOutput{},
+		Errors: []apierror.Code{apierror.CodeInvalidParameters},
+	},
+	{
+		Method:      "POST",
+		Path:        "/reverse",
+		Summary:     "Reverses a text",
+		Description: "Reverses the bytes of the text.",
+		RequestBody: openapi.TextBody("The text to reverse"),
+		Output:      echoOutput{},
+	},
+}
+
+// setupTestGin creates a router with the "text" category, authorizing it and
+// the MCP transport. The fake authenticator reads the client scopes from the
+// Authorization header.
+func setupTestGin() (*gin.Engine, []registry.Mounted) {
+	r := gin.New()
+	r.Use(func(c *gin.Context) { // fake Authenticator
+		c.Set(middleware.ScopeKey, c.GetHeader("Authorization"))
+	})
+	policy := middleware.NewAuthorizationPolicy(testScopePrefix)
+	r.Use(middleware.Authorizer(policy))
+
+	group := r.Group("/v1").Group("/text")
+	group.GET("/echo", func(c *gin.Context) {
+		if c.Query("value") == "" {
+			apierror.Respond(c, apierror.Invalid(c, apierror.RequiredField("value")))
+			return
+		}
+		c.JSON(http.StatusOK, echoOutput{Value: c.Query("value") + "/" + c.Query("upper")})
+	})
+	group.POST("/reverse", func(c *gin.Context) {
+		data, _ := ioutil.ReadAll(c.Request.Body)
+		for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+			data[i], data[j] = data[j], data[i]
+		}
+		c.JSON(http.StatusOK, echoOutput{Value: string(data)})
+	})
+	policy.Register(group.BasePath(), testPolicies...)
+	policy.Register("", RoutePolicies...)
+
+	mounted := []registry.Mounted{{
+		Category: registry.Category{Name: "text", Policies: testPolicies, Docs: testDocs},
+		Group:    group,
+	}}
+
+	return r, mounted
+}
+
+func TestToolsFor(t *testing.T) {
+	// arrange
+	_, mounted := setupTestGin()
+
+	// act
+	tools := ToolsFor(mounted)
+
+	// assert
+	assert.Len(t, tools, 2)
+
+	echo := tools[0]
+	assert.Equal(t, "text_echo", echo.Name)
+	assert.Equal(t, "Echoes a value", echo.Description)
+	assert.Equal(t, "object", echo.InputSchema.Type)
+	assert.Equal(t, []string{"value"}, echo.InputSchema.Required)
+	assert.Equal(t, "boolean", echo.InputSchema.Properties["upper"].Type)
+	assert.Equal(t, "Echoes in upper case", echo.InputSchema.Properties["upper"].Description)
+	assert.Contains(t, echo.OutputSchema.Properties, "value")
+
+	reverse := tools[1]
+	assert.Equal(t, "text_reverse", reverse.Name)
+	assert.Equal(t, "Reverses a text. Reverses the bytes of the text.", reverse.Description)
+	assert.Equal(t, []string{bodyArgument}, reverse.InputSchema.Required)
+	assert.Equal(t, "string", reverse.InputSchema.Properties[bodyArgument].Type)
+	assert.Equal(t, "The text to reverse", reverse.InputSchema.Properties[bodyArgument].Description)
+}
+
+func TestToolsForSharedPath(t *testing.T) {
+	// arrange
+	mounted := []registry.Mounted{{
+		Category: registry.Category{Name: "text", Docs: []openapi.Route{
+			{Method: "GET", Path: "/echo"},
+			{Method: "POST", Path: "/echo"},
+		}},
+		Group: gin.New().Group("/v1/text"),
+	}}
+
+	// act
+	tools := ToolsFor(mounted)
+
+	// assert
+	assert.Equal(t, "text_echo", tools[0].Name)
+	assert.Equal(t, "text_echo_post", tools[1].Name)
+}
+
+func TestNewRequest(t *testing.T) {
+	// arrange
+	_, mounted := setupTestGin()
+	tools := ToolsFor(mounted)
+
+	// act
+	req, err := tools[0].newRequest(context.Background(), map[string]interface{}{
+		"value":   "a b",
+		"upper":   true,
+		"ignored": "x",
+	})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "GET", req.Method)
+	assert.Equal(t, "/v1/text/echo?upper=true&value=a+b", req.URL.String())
+}
+
+func TestNewRequestWithBody(t *testing.T) {
+	// arrange
+	_, mounted := setupTestGin()
+	tools := ToolsFor(mounted)
+
+	// act
+	req, err := tools[1].newRequest(context.Background(), map[string]interface{}{
+		"body": "abc",
+	})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "POST", req.Method)
+	assert.Equal(t, "text/plain", req.Header.Get("Content-Type"))
+	body, _ := ioutil.ReadAll(req.Body)
+	assert.Equal(t, "abc", string(body))
+}
+
+func TestNewRequestWithPathParameter(t *testing.T) {
+	// arrange
+	mounted := []registry.Mounted{{
+		Category: registry.Category{Name: "text", Docs: []openapi.Route{{
+			Method: "GET",
+			Path:   "/items/:id",
+			Parameters: []openapi.Parameter{
+				openapi.PathParameter("id", openapi.String("Item id")),
+				openapi.QueryParameter("upper", openapi.Boolean("Upper case"), false),
+			},
+		}}},
+		Group: gin.New().Group("/v1/text"),
+	}}
+	tools := ToolsFor(mounted)
+
+	// act
+	req, err := tools[0].newRequest(context.Background(), map[string]interface{}{
+		"id":    "a/b",
+		"upper": true,
+	})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "text_items_id", tools[0].Name)
+	assert.Equal(t, []string{"id"}, tools[0].InputSchema.Required)
+	assert.Equal(t, "/v1/text/items/a%2Fb?upper=true", req.URL.String())
+}
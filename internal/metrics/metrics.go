@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "learning_go_api"
+
+// Registry holds all the metrics exposed by the service
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts the finished HTTP requests
+	HTTPRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests.",
+		},
+		[]string{"route", "method", "status_class", "client_id"})
+
+	// HTTPRequestDuration measures the latency of the HTTP requests
+	HTTPRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of the HTTP requests.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"route", "method", "status_class", "client_id"})
+
+	// HTTPRequestsInFlight counts the HTTP requests being processed
+	HTTPRequestsInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests being processed.",
+		},
+		[]string{"route", "method"})
+
+	// UpstreamRequests counts the calls to upstream services
+	UpstreamRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upstream_requests_total",
+			Help:      "Number of calls to upstream services.",
+		},
+		[]string{"upstream", "result"})
+
+	// UpstreamRequestDuration measures the latency of the upstream calls
+	UpstreamRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upstream_request_duration_seconds",
+			Help:      "Latency of the calls to upstream services.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"upstream", "result"})
+
+	// KeySetRefreshes counts the JWKS downloads
+	KeySetRefreshes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "jwks_refreshes_total",
+			Help:      "Number of JSON Web Key Set refreshes.",
+		},
+		[]string{"location", "result"})
+)
+
+// Results of the upstream calls and refreshes
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		HTTPRequestsInFlight,
+		UpstreamRequests,
+		UpstreamRequestDuration,
+		KeySetRefreshes,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Result converts an error into the result label value.
+func Result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+
+	return ResultSuccess
+}
+
+// StatusClass converts an HTTP status code into its class, as in "2xx".
+func StatusClass(status int) string {
+	return fmt.Sprintf("%dxx", status/100)
+}
@@ -1,96 +1,221 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
-	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/lestrrat-go/jwx/jws"
 	"github.com/lestrrat-go/jwx/jwt"
 	"github.com/renato0307/learning-go-api/internal/apierror"
-	"github.com/rs/zerolog/log"
+	"github.com/renato0307/learning-go-api/internal/logging"
 )
 
 type AuthenticatorConfig struct {
-	KeySetJSON []byte
-	Issuer     string
+	// Issuers lists the identity providers whose tokens are accepted
+	Issuers []*TrustedIssuer
+
+	// TokenSources lists where the token can be read from. If empty the
+	// DefaultTokenSources are used.
+	TokenSources []TokenSource
+
+	// PublicPaths lists the paths not requiring a token. If empty the
+	// DefaultPublicPaths are used.
+	PublicPaths []string
 }
 
+// DefaultPublicPaths are used when the AuthenticatorConfig defines none. The
+// root is used for the liveness probes.
+var DefaultPublicPaths = []string{"/"}
+
 const (
 	TokenUseKey = "token_use"
 	ClientIdKey = "client_id"
 	ScopeKey    = "scope"
+	IssuerKey   = "iss"
+	GroupsKey   = "groups"
 )
 
+// realm is sent in the WWW-Authenticate header of the 401 responses
+const realm = "learning-go-api"
+
 func Authenticator(ac *AuthenticatorConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		logger := logging.FromContext(c.Request.Context())
 
-		// Ignore the root as it is used for the liveness probes
-		if c.Request.URL.Path == "/" {
+		// Ignore the public paths, like the root used for the liveness probes
+		if ac.isPublic(c) {
 			return
 		}
 
-		// Gets the JWT from the Authentication header
-		authHeader := c.GetHeader("Authentication")
+		// Trusts the identity of the in-process requests, like the batch
+		// operations, whatever the token source of the caller
+		if identity, ok := identityFromContext(c.Request.Context()); ok {
+			setIdentity(c, identity)
+			return
+		}
 
-		if authHeader == "" {
-			log.Debug().Msg("JWT not found")
-			c.AbortWithStatusJSON(
-				http.StatusUnauthorized,
-				apierror.New("Not authorized"))
+		// Gets the JWT from the allowed token sources
+		tokenString, err := extractToken(c, ac.tokenSources())
+		if err == ErrTokenNotFound {
+			logger.Debug().Msg("JWT not found")
+			c.Header("WWW-Authenticate", wwwAuthenticate(realm, "", ""))
+			apierror.Abort(c, apierror.New(c, apierror.CodeUnauthorized, ""))
+			return
+		}
+		if err != nil {
+			logger.Debug().Err(err).Msg("JWT request not valid")
+			c.Header("WWW-Authenticate",
+				wwwAuthenticate(realm, bearerErrorInvalidRequest, err.Error()))
+			apierror.Abort(c, apierror.New(c, apierror.CodeBadRequest, ""))
 			return
 		}
 
 		// Validates the JWT
-		token, err := validateToken(ac, authHeader)
+		identity, err := ac.Authenticate(tokenString)
 		if err != nil {
-			log.Debug().Err(err).Msg("JWT not valid")
-			c.AbortWithStatusJSON(
-				http.StatusUnauthorized,
-				apierror.New("Not authorized"))
+			logger.Debug().Err(err).Msg("JWT not valid")
+			c.Header("WWW-Authenticate",
+				wwwAuthenticate(realm, bearerErrorInvalidToken, "the access token is not valid"))
+			apierror.Abort(c, apierror.New(c, apierror.CodeUnauthorized, ""))
 			return
 		}
 
-		// Put the client identifier in the Gin context
-		ci, _ := token.Get(ClientIdKey)
-		c.Set(ClientIdKey, ci)
+		setIdentity(c, identity)
 
-		// Puts the scopes in the Gin context
-		scope, _ := token.Get(ScopeKey)
-		c.Set(ScopeKey, scope)
+		// Keeps the identity in the request context, so the requests
+		// dispatched in process are authenticated as the caller
+		c.Request = c.Request.WithContext(WithIdentity(c.Request.Context(), identity))
 	}
 }
 
-func validateToken(ac *AuthenticatorConfig, tokenString string) (jwt.Token, error) {
-	keySet, err := jwk.Parse(ac.KeySetJSON)
+type identityKey struct{}
+
+// WithIdentity returns a context authenticating the in-process requests made
+// with it as the identity, without a token.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+func identityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok
+}
+
+// setIdentity puts the issuer, the client identifier, the scopes and the user
+// groups in the Gin context.
+func setIdentity(c *gin.Context, identity *Identity) {
+	c.Set(ClientIdKey, identity.ClientId)
+	c.Set(IssuerKey, identity.Issuer)
+	c.Set(ScopeKey, identity.Scope)
+	c.Set(GroupsKey, identity.Groups)
+}
+
+// Identity is the client authenticated by a token
+type Identity struct {
+	ClientId string
+	Issuer   string
+
+	// Scope is the space separated list of scopes granted to the client
+	Scope  string
+	Groups []string
+}
+
+// Authenticate validates the token and returns the identity of the client, so
+// other transports like gRPC authenticate like the Authenticator.
+func (ac *AuthenticatorConfig) Authenticate(tokenString string) (*Identity, error) {
+	token, issuer, err := validateToken(ac, tokenString)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse keyset: %s", err)
+		return nil, err
+	}
+
+	ci, _ := token.Get(issuer.clientIdClaim())
+	clientId, _ := ci.(string)
+
+	return &Identity{
+		ClientId: clientId,
+		Issuer:   issuer.Issuer,
+		Scope:    issuer.scopes(token),
+		Groups:   issuer.groups(token),
+	}, nil
+}
+
+// tokenSources returns the configured token sources or the default ones.
+func (ac *AuthenticatorConfig) tokenSources() []TokenSource {
+	if ac == nil || len(ac.TokenSources) == 0 {
+		return DefaultTokenSources
 	}
 
+	return ac.TokenSources
+}
+
+// isPublic checks if the request path, or the route template it matches, as
+// in "/docs/assets/*file", does not require a token.
+func (ac *AuthenticatorConfig) isPublic(c *gin.Context) bool {
+	publicPaths := DefaultPublicPaths
+	if ac != nil && len(ac.PublicPaths) > 0 {
+		publicPaths = ac.PublicPaths
+	}
+
+	return containsAny([]string{c.Request.URL.Path, c.FullPath()}, publicPaths)
+}
+
+// findIssuer returns the trusted issuer matching the issuer claim.
+func (ac *AuthenticatorConfig) findIssuer(iss string) (*TrustedIssuer, bool) {
+	for _, ti := range ac.Issuers {
+		if ti.Issuer == iss {
+			return ti, true
+		}
+	}
+
+	return nil, false
+}
+
+func validateToken(ac *AuthenticatorConfig, tokenString string) (jwt.Token, *TrustedIssuer, error) {
 	// Step 1: Confirm the structure of the JWT
+	msg, err := jws.Parse([]byte(tokenString))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid token: %s", err)
+	}
+
+	// Chooses the issuer from the claims, which are NOT VERIFIED at this point
+	unverified, err := jwt.Parse(msg.Payload())
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid token: %s", err)
+	}
+	issuer, found := ac.findIssuer(unverified.Issuer())
+	if !found {
+		return nil, nil, fmt.Errorf("invalid token: untrusted issuer %q", unverified.Issuer())
+	}
+
+	// Makes sure the signature algorithm is accepted by the issuer
+	headers := msg.Signatures()[0].ProtectedHeaders()
+	alg := headers.Algorithm().String()
+	if !issuer.acceptsAlgorithm(alg) {
+		return nil, nil, fmt.Errorf("invalid token: algorithm %q not accepted", alg)
+	}
+
+	// Makes sure the signing key is known, refetching the key set if the key
+	// was rotated
+	kid := headers.KeyID()
+	if _, found := issuer.KeySet.LookupKeyID(kid); !found {
+		return nil, nil, fmt.Errorf("invalid token: unknown key id %q", kid)
+	}
+
 	// Step 2: Validate the JWT signature
 	token, err := jwt.Parse(
 		[]byte(tokenString),
-		jwt.WithKeySet(keySet),
+		jwt.WithKeySet(issuer.KeySet.KeySet()),
 	)
 	if err != nil {
-		log.Debug().Err(err).Msg("error parsing the token")
-		return nil, fmt.Errorf("invalid token: %s", err)
+		return nil, nil, fmt.Errorf("invalid token: %s", err)
 	}
 
 	// Step 3: Verify the claims
-	clientId, _ := token.Get(ClientIdKey)
-	err = jwt.Validate(token,
-		jwt.WithClaimValue(TokenUseKey, "access"),
-		jwt.WithClaimValue(jwt.IssuerKey, ac.Issuer),
-		jwt.WithRequiredClaim(ClientIdKey),
-		jwt.WithRequiredClaim(jwt.SubjectKey),
-		jwt.WithClaimValue(jwt.SubjectKey, clientId),
-	)
+	err = issuer.validateClaims(token)
 	if err != nil {
-		log.Debug().Err(err).Msg("error validating the token")
-		return nil, fmt.Errorf("invalid token: %s", err)
+		return nil, nil, fmt.Errorf("invalid token: %s", err)
 	}
 
-	return token, nil
+	return token, issuer, nil
 }
@@ -6,6 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -35,7 +38,7 @@ func TestAuthenticatorNoAuthHeader(t *testing.T) {
 
 	// assert
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
-	apierror.AssertIsValid(t, w.Body.Bytes())
+	apierror.AssertIsProblem(t, w, apierror.CodeUnauthorized)
 }
 
 func TestAuthenticatorRootPathSkipsAuth(t *testing.T) {
@@ -59,6 +62,7 @@ func TestAuthenticatorWithJWT(t *testing.T) {
 	// arrange - generate key, keyset and JWT
 	key := generateKey(t)
 	jwks := generateKeySetInJSON(&key, t)
+	keySet := newKeySetCache(jwks, t)
 
 	// arrange - define the several test cases
 	testCases := []struct {
@@ -106,8 +110,7 @@ func TestAuthenticatorWithJWT(t *testing.T) {
 		// arrange - init gin to use the authenticator middleware
 		r := gin.New()
 		authConfig := AuthenticatorConfig{
-			KeySetJSON: jwks,
-			Issuer:     userPool,
+			Issuers: []*TrustedIssuer{NewCognitoIssuer(userPool, keySet)},
 		}
 		r.Use(Authenticator(&authConfig))
 		r.Use(gin.Recovery())
@@ -136,6 +139,192 @@ func TestAuthenticatorWithJWT(t *testing.T) {
 	}
 }
 
+func TestAuthenticatorTokenSources(t *testing.T) {
+	// arrange - generate key, keyset and JWT
+	key := generateKey(t)
+	keySet := newKeySetCache(generateKeySetInJSON(&key, t), t)
+	validJWT := newValidJWT(key, t)
+
+	// arrange - define the several test cases
+	testCases := []struct {
+		Sources         []TokenSource
+		Header          http.Header
+		Path            string
+		StatusCode      int
+		WWWAuthenticate string
+		Purpose         string
+	}{
+		{
+			Header:     http.Header{"Authorization": {"Bearer " + validJWT}},
+			Path:       "/example",
+			StatusCode: http.StatusOK,
+			Purpose:    "bearer token",
+		},
+		{
+			Header:     http.Header{"Authorization": {"bearer " + validJWT}},
+			Path:       "/example",
+			StatusCode: http.StatusOK,
+			Purpose:    "bearer scheme is case insensitive",
+		},
+		{
+			Header:     http.Header{"Authentication": {validJWT}},
+			Path:       "/example",
+			StatusCode: http.StatusOK,
+			Purpose:    "legacy authentication header",
+		},
+		{
+			Header:          http.Header{"Authorization": {"Basic dXNlcjpwYXNz"}},
+			Path:            "/example",
+			StatusCode:      http.StatusBadRequest,
+			WWWAuthenticate: `error="invalid_request"`,
+			Purpose:         "not a bearer token",
+		},
+		{
+			Header: http.Header{
+				"Authorization":  {"Bearer " + validJWT},
+				"Authentication": {validJWT},
+			},
+			Path:            "/example",
+			StatusCode:      http.StatusBadRequest,
+			WWWAuthenticate: `error="invalid_request"`,
+			Purpose:         "more than one token",
+		},
+		{
+			Header:          http.Header{"Authorization": {"Bearer xxxxx.yyyyy.zzzzz"}},
+			Path:            "/example",
+			StatusCode:      http.StatusUnauthorized,
+			WWWAuthenticate: `error="invalid_token"`,
+			Purpose:         "invalid bearer token",
+		},
+		{
+			Header:          http.Header{},
+			Path:            "/example",
+			StatusCode:      http.StatusUnauthorized,
+			WWWAuthenticate: `Bearer realm="learning-go-api"`,
+			Purpose:         "no token",
+		},
+		{
+			Header:          http.Header{},
+			Path:            "/example?access_token=" + validJWT,
+			StatusCode:      http.StatusUnauthorized,
+			WWWAuthenticate: `Bearer realm="learning-go-api"`,
+			Purpose:         "query parameter not allowed by default",
+		},
+		{
+			Sources:    []TokenSource{TokenSourceQuery},
+			Header:     http.Header{},
+			Path:       "/example?access_token=" + validJWT,
+			StatusCode: http.StatusOK,
+			Purpose:    "query parameter allowed",
+		},
+		{
+			Sources:         []TokenSource{TokenSourceQuery},
+			Header:          http.Header{"Authorization": {"Bearer " + validJWT}},
+			Path:            "/example",
+			StatusCode:      http.StatusUnauthorized,
+			WWWAuthenticate: `Bearer realm="learning-go-api"`,
+			Purpose:         "bearer token not allowed",
+		},
+	}
+
+	for _, tc := range testCases {
+		// arrange - init gin to use the authenticator middleware
+		r := gin.New()
+		authConfig := AuthenticatorConfig{
+			Issuers:      []*TrustedIssuer{NewCognitoIssuer(userPool, keySet)},
+			TokenSources: tc.Sources,
+		}
+		r.Use(Authenticator(&authConfig))
+		r.Use(gin.Recovery())
+
+		// arrange - set the routes
+		r.GET("/example", func(c *gin.Context) {})
+
+		// act
+		w := apitesting.PerformRequestWithHeader(r, "GET", tc.Path, tc.Header)
+
+		// assert
+		assert.Equal(t, tc.StatusCode, w.Code, tc.Purpose)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), tc.WWWAuthenticate, tc.Purpose)
+	}
+}
+
+func TestAuthenticatorFormTokenSource(t *testing.T) {
+	// arrange - generate key, keyset and JWT
+	key := generateKey(t)
+	keySet := newKeySetCache(generateKeySetInJSON(&key, t), t)
+
+	r := gin.New()
+	authConfig := AuthenticatorConfig{
+		Issuers:      []*TrustedIssuer{NewCognitoIssuer(userPool, keySet)},
+		TokenSources: []TokenSource{TokenSourceForm},
+	}
+	r.Use(Authenticator(&authConfig))
+	r.POST("/example", func(c *gin.Context) {})
+
+	body := url.Values{"access_token": {newValidJWT(key, t)}}.Encode()
+	req := httptest.NewRequest("POST", "/example", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthenticatorDispatchedRequests(t *testing.T) {
+	// arrange - the caller sends the token in the query string, and the
+	// batch route calls the example route in process, without the token
+	key := generateKey(t)
+	keySet := newKeySetCache(generateKeySetInJSON(&key, t), t)
+
+	r := gin.New()
+	authConfig := AuthenticatorConfig{
+		Issuers:      []*TrustedIssuer{NewCognitoIssuer(userPool, keySet)},
+		TokenSources: []TokenSource{TokenSourceQuery},
+	}
+	r.Use(Authenticator(&authConfig))
+	r.GET("/example", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(ClientIdKey))
+	})
+	r.GET("/batch", func(c *gin.Context) {
+		req := httptest.NewRequest("GET", "/example", nil).WithContext(c.Request.Context())
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		c.String(w.Code, w.Body.String())
+	})
+
+	// act
+	w := apitesting.PerformRequest(r, "GET", "/batch?access_token="+newValidJWT(key, t))
+	withoutToken := apitesting.PerformRequest(r, "GET", "/example")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "client_id_1234567890", w.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, withoutToken.Code)
+}
+
+func TestParseTokenSources(t *testing.T) {
+	// act
+	sources, err := ParseTokenSources("Bearer, authentication,query,form,")
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t,
+		[]TokenSource{
+			TokenSourceBearer,
+			TokenSourceAuthentication,
+			TokenSourceQuery,
+			TokenSourceForm,
+		},
+		sources)
+
+	_, err = ParseTokenSources("bearer,cookie")
+	assert.NotNil(t, err)
+}
+
 func newValidJWT(key jwk.Key, t *testing.T) string {
 	return newJWT(key, false, false, false, t)
 }
@@ -192,6 +381,23 @@ func generateKey(t *testing.T) jwk.Key {
 	return key
 }
 
+// newKeySetCache serves the key set in a local HTTP server and returns a cache
+// already loaded with it
+func newKeySetCache(jwks []byte, t *testing.T) *KeySetCache {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(jwks)
+	}))
+	t.Cleanup(svr.Close)
+
+	keySet := NewKeySetCache(svr.URL, 0)
+	err := keySet.Refresh()
+	if err != nil {
+		t.Fatalf("failed to load key set: %s\n", err)
+	}
+
+	return keySet
+}
+
 func generateKeySetInJSON(key *jwk.Key, t *testing.T) []byte {
 	set := jwk.NewSet()
 	pubKey, _ := (*key).(jwk.RSAPrivateKey).PublicKey()
@@ -205,3 +411,85 @@ func generateKeySetInJSON(key *jwk.Key, t *testing.T) []byte {
 
 	return buf
 }
+
+func TestAuthenticate(t *testing.T) {
+	// arrange
+	key := generateKey(t)
+	keySet := newKeySetCache(generateKeySetInJSON(&key, t), t)
+	authConfig := AuthenticatorConfig{
+		Issuers: []*TrustedIssuer{NewCognitoIssuer(userPool, keySet)},
+	}
+
+	// act
+	identity, err := authConfig.Authenticate(newValidJWT(key, t))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, &Identity{
+		ClientId: "client_id_1234567890",
+		Issuer:   userPool,
+		Scope:    "https://learning-go-api.com/all",
+		Groups:   []string{},
+	}, identity)
+
+	_, err = authConfig.Authenticate(newJWT(key, false, true, false, t))
+	assert.Error(t, err)
+}
+
+func TestTokenFromHeaders(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Sources []TokenSource
+		Headers map[string]string
+		Token   string
+		Err     error
+	}{
+		{
+			Name:    "bearer",
+			Headers: map[string]string{"Authorization": "Bearer abc"},
+			Token:   "abc",
+		},
+		{
+			Name:    "authentication",
+			Headers: map[string]string{"Authentication": "abc"},
+			Token:   "abc",
+		},
+		{
+			Name:    "missing",
+			Headers: map[string]string{},
+			Err:     ErrTokenNotFound,
+		},
+		{
+			Name:    "not allowed source",
+			Sources: []TokenSource{TokenSourceBearer, TokenSourceQuery},
+			Headers: map[string]string{"Authentication": "abc"},
+			Err:     ErrTokenNotFound,
+		},
+		{
+			Name:    "not a bearer token",
+			Headers: map[string]string{"Authorization": "Basic abc"},
+			Err:     errInvalidAuthzHeader,
+		},
+		{
+			Name:    "multiple tokens",
+			Headers: map[string]string{"Authorization": "Bearer abc", "Authentication": "def"},
+			Err:     errMultipleTokens,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			authConfig := AuthenticatorConfig{TokenSources: tc.Sources}
+
+			// act
+			token, err := authConfig.TokenFromHeaders(func(key string) string {
+				return tc.Headers[key]
+			})
+
+			// assert
+			assert.Equal(t, tc.Err, err)
+			assert.Equal(t, tc.Token, token)
+		})
+	}
+}
@@ -1,50 +1,53 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/renato0307/learning-go-api/internal/apierror"
-	"github.com/rs/zerolog/log"
+	"github.com/renato0307/learning-go-api/internal/logging"
 )
 
-func Authorizer() gin.HandlerFunc {
+// Authorizer checks the client scopes and the user groups against the
+// authorization policy of the route.
+//
+// Requests not matching any route go through, so gin can answer with HTTP 404.
+// Returns HTTP 403 if the route has no policy or no valid scope or group is
+// found.
+func Authorizer(policy *AuthorizationPolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		logger := logging.FromContext(c.Request.Context())
 
-		// extracts the required scope from the URL
-		p1 := c.Request.URL.Path               // p1 = "/v1/programming/uuid"
-		p2 := strings.ReplaceAll(p1, "/", "-") // p2 = "-v1-programming-uuid"
-		p3 := strings.Split(p2, "-")           // p3 = ["", "v1", "programming", "uuid"]
-		p4 := p3[2:]                           // p4 = ["programming", "uuid"]
-		scope := strings.Join(p4, "-")         // scope = "programming-uuid"
-		log.Debug().Msgf("scope for url is %s", scope)
-
-		// gets the scopes added by the Authenticator middleware
-		clientScopes := c.GetString(ScopeKey)
-		clientScopesList := strings.Split(clientScopes, " ")
-		log.Debug().Msgf("client scope list is %s", clientScopes)
-
-		// tries to find a matching scope
-		// scopes have the following format
-		// https://learninggolang.com/programming-jwtdebugger
-		found := false
-		for _, clientScope := range clientScopesList {
-			found = strings.HasSuffix(clientScope, scope)
-			if found {
-				break
-			}
+		// requests not matching any route are left for gin to handle
+		route := c.FullPath()
+		if route == "" {
+			return
 		}
 
-		// returns forbidden (HTTP status 403) if no valid scope is found
+		// gets the policy for the route template, e.g. "/v1/items/:id"
+		routePolicy, found := policy.Find(c.Request.Method, route)
 		if !found {
-			log.Debug().Msg("no scope found for current route")
-			c.AbortWithStatusJSON(
-				http.StatusForbidden,
-				apierror.New("Forbidden"))
+			logger.Debug().Msgf("no authorization policy for route %s", route)
+			apierror.Abort(c, apierror.New(c, apierror.CodeForbidden, ""))
+			return
+		}
+		logger.Debug().Msgf("scopes for route are %s", routePolicy.Scopes)
+
+		// gets the scopes and groups added by the Authenticator middleware
+		clientScopes := c.GetString(ScopeKey)
+		clientScopesList := strings.Fields(clientScopes)
+		groups := c.GetStringSlice(GroupsKey)
+		logger.Debug().Msgf("client scope list is %s", clientScopes)
+		logger.Debug().Msgf("user groups are %s", groups)
+
+		// returns forbidden (HTTP status 403) if no valid scope or group is
+		// found
+		if !policy.Allows(routePolicy, clientScopesList, groups) {
+			logger.Debug().Msg("no scope or group found for current route")
+			apierror.Abort(c, apierror.New(c, apierror.CodeForbidden, ""))
 			return
 		}
 
-		log.Debug().Msg("valid client scope found for current route")
+		logger.Debug().Msg("valid client scope or group found for current route")
 	}
 }
@@ -19,42 +19,106 @@ func TestAuthorizer(t *testing.T) {
 		StatusCode int
 	}{
 		{
-			Scopes:     "https://learninggolang.com/programming-jwtdebugger",
-			URL:        "/v1/programming-jwtdebugger",
-			RequestURL: "/v1/programming-jwtdebugger?abcd",
+			Scopes:     "https://learninggolang.com/programming-jwt",
+			URL:        "/v1/programming/jwt",
+			RequestURL: "/v1/programming/jwt?abcd",
 			Purpose:    "scopes match URL",
 			StatusCode: http.StatusOK,
 		},
 		{
 			Scopes:     "https://learninggolang.com/programming-uuid",
-			URL:        "/v1/programming-jwtdebugger",
-			RequestURL: "/v1/programming-jwtdebugger",
+			URL:        "/v1/programming/jwt",
+			RequestURL: "/v1/programming/jwt",
 			Purpose:    "scopes do not match URL",
 			StatusCode: http.StatusForbidden,
 		},
 		{
 			Scopes:     "",
-			URL:        "/v1/programming-jwtdebugger",
-			RequestURL: "/v1/programming-jwtdebugger",
+			URL:        "/v1/programming/jwt",
+			RequestURL: "/v1/programming/jwt",
 			Purpose:    "no scopes defined",
 			StatusCode: http.StatusForbidden,
 		},
 		{
-			Scopes:     "https://learninggolang.com/programming-jwtdebugger https://learninggolang.com/programming-uuid",
-			URL:        "/v1/programming-jwtdebugger",
-			RequestURL: "/v1/programming-jwtdebugger",
+			Scopes:     "https://learninggolang.com/programming-jwt https://learninggolang.com/programming-uuid",
+			URL:        "/v1/programming/jwt",
+			RequestURL: "/v1/programming/jwt",
 			Purpose:    "list of scopes",
 			StatusCode: http.StatusOK,
 		},
+		{
+			Scopes:     "https://learninggolang.com/evil-programming-jwt",
+			URL:        "/v1/programming/jwt",
+			RequestURL: "/v1/programming/jwt",
+			Purpose:    "scope only ending with the required one",
+			StatusCode: http.StatusForbidden,
+		},
+		{
+			Scopes:     "https://another-api.com/programming-jwt",
+			URL:        "/v1/programming/jwt",
+			RequestURL: "/v1/programming/jwt",
+			Purpose:    "scope from another resource server",
+			StatusCode: http.StatusForbidden,
+		},
+		{
+			Scopes:     "https://learninggolang.com/programming-*",
+			URL:        "/v1/programming/jwt",
+			RequestURL: "/v1/programming/jwt",
+			Purpose:    "wildcard scope",
+			StatusCode: http.StatusOK,
+		},
+		{
+			Scopes:     "https://learninggolang.com/finance-*",
+			URL:        "/v1/programming/jwt",
+			RequestURL: "/v1/programming/jwt",
+			Purpose:    "wildcard scope for another category",
+			StatusCode: http.StatusForbidden,
+		},
+		{
+			Scopes:     "https://learninggolang.com/items-read",
+			URL:        "/v1/items/:id",
+			RequestURL: "/v1/items/1234",
+			Purpose:    "route with path parameters",
+			StatusCode: http.StatusOK,
+		},
+		{
+			Scopes:     "https://learninggolang.com/programming-jwt",
+			URL:        "/v1/no-policy",
+			RequestURL: "/v1/no-policy",
+			Purpose:    "route without policy",
+			StatusCode: http.StatusForbidden,
+		},
+		{
+			Scopes:     "",
+			URL:        "/",
+			RequestURL: "/",
+			Purpose:    "public route",
+			StatusCode: http.StatusOK,
+		},
+		{
+			Scopes:     "",
+			URL:        "/",
+			RequestURL: "/not-found",
+			Purpose:    "route not found",
+			StatusCode: http.StatusNotFound,
+		},
 	}
 
+	policy := NewAuthorizationPolicy("")
+	policy.Register("/v1/programming",
+		RoutePolicy{Method: "POST", Path: "/jwt", Scopes: []string{"programming-jwt"}})
+	policy.Register("/v1",
+		RoutePolicy{Method: "POST", Path: "/items/:id", Scopes: []string{"items-read"}})
+	policy.Register("",
+		RoutePolicy{Method: "POST", Path: "/", Public: true})
+
 	for _, tc := range testCases {
 		// arrange - init gin to use the middlewares
 		r := gin.New()
 		r.Use(func(c *gin.Context) { // fake Authenticator
 			c.Set(ScopeKey, tc.Scopes)
 		})
-		r.Use(Authorizer())
+		r.Use(Authorizer(policy))
 		r.Use(gin.Recovery())
 
 		// arrange - set the routes
@@ -67,3 +131,42 @@ func TestAuthorizer(t *testing.T) {
 		assert.Equal(t, tc.StatusCode, w.Code, tc.Purpose)
 	}
 }
+
+func TestAuthorizerWithGroups(t *testing.T) {
+	// arrange - policy granting finance routes to the analysts
+	policy := NewAuthorizationPolicy("")
+	policy.Register("/v1/finance",
+		RoutePolicy{Method: "GET", Path: "/currconv", Scopes: []string{"finance-currconv"}})
+	policy.Roles = []RolePolicy{{Group: "finance-analysts", Paths: []string{"/v1/finance/*"}}}
+
+	testCases := []struct {
+		Groups     []string
+		Purpose    string
+		StatusCode int
+	}{
+		{[]string{"finance-analysts"}, "group with access", http.StatusOK},
+		{[]string{"developers"}, "group without access", http.StatusForbidden},
+		{nil, "no groups", http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		// arrange - init gin to use the middlewares
+		r := gin.New()
+		r.Use(func(c *gin.Context) { // fake Authenticator
+			c.Set(ScopeKey, "")
+			if tc.Groups != nil {
+				c.Set(GroupsKey, tc.Groups)
+			}
+		})
+		r.Use(Authorizer(policy))
+
+		// arrange - set the routes
+		r.GET("/v1/finance/currconv", func(c *gin.Context) {})
+
+		// act
+		w := apitesting.PerformRequest(r, "GET", "/v1/finance/currconv")
+
+		// assert
+		assert.Equal(t, tc.StatusCode, w.Code, tc.Purpose)
+	}
+}
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenSource identifies where the Authenticator looks for the access token.
+type TokenSource string
+
+const (
+	// TokenSourceBearer reads "Authorization: Bearer <token>" (RFC 6750 2.1)
+	TokenSourceBearer TokenSource = "bearer"
+
+	// TokenSourceAuthentication reads the raw token from the legacy
+	// "Authentication" header
+	TokenSourceAuthentication TokenSource = "authentication"
+
+	// TokenSourceQuery reads the "access_token" query parameter (RFC 6750 2.3)
+	TokenSourceQuery TokenSource = "query"
+
+	// TokenSourceForm reads the "access_token" form-encoded body parameter
+	// (RFC 6750 2.2)
+	TokenSourceForm TokenSource = "form"
+)
+
+// DefaultTokenSources are used when the AuthenticatorConfig defines none
+var DefaultTokenSources = []TokenSource{
+	TokenSourceBearer,
+	TokenSourceAuthentication,
+}
+
+const (
+	accessTokenParam = "access_token"
+	bearerPrefix     = "bearer "
+
+	// errors codes defined in RFC 6750 section 3.1
+	bearerErrorInvalidRequest = "invalid_request"
+	bearerErrorInvalidToken   = "invalid_token"
+)
+
+var (
+	// ErrTokenNotFound is returned when the request has no token
+	ErrTokenNotFound = errors.New("token not found")
+
+	errMultipleTokens     = errors.New("more than one token found")
+	errInvalidAuthzHeader = errors.New("authorization header is not a bearer token")
+)
+
+// ParseTokenSources converts a comma separated list like "bearer,query" into
+// token sources, failing on unknown ones.
+func ParseTokenSources(value string) ([]TokenSource, error) {
+	sources := []TokenSource{}
+	for _, v := range strings.Split(value, ",") {
+		source := TokenSource(strings.TrimSpace(strings.ToLower(v)))
+		switch source {
+		case TokenSourceBearer,
+			TokenSourceAuthentication,
+			TokenSourceQuery,
+			TokenSourceForm:
+			sources = append(sources, source)
+		case "":
+			continue
+		default:
+			return nil, fmt.Errorf("unknown token source %q", v)
+		}
+	}
+
+	return sources, nil
+}
+
+// extractToken gets the access token from the allowed sources. Clients must
+// use only one of them per request.
+func extractToken(c *gin.Context, sources []TokenSource) (string, error) {
+	tokens := []string{}
+	for _, source := range sources {
+		token, err := extractTokenFrom(c, source)
+		if err != nil {
+			return "", err
+		}
+		if token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return singleToken(tokens)
+}
+
+func extractTokenFrom(c *gin.Context, source TokenSource) (string, error) {
+	switch source {
+	case TokenSourceBearer, TokenSourceAuthentication:
+		return extractHeaderToken(c.GetHeader, source)
+
+	case TokenSourceQuery:
+		return c.Query(accessTokenParam), nil
+
+	case TokenSourceForm:
+		contentType := c.ContentType()
+		if c.Request.Method == http.MethodGet ||
+			contentType != gin.MIMEPOSTForm {
+			return "", nil
+		}
+		return c.PostForm(accessTokenParam), nil
+	}
+
+	return "", nil
+}
+
+// TokenFromHeaders gets the access token from the allowed header sources, for
+// transports without query strings or forms like gRPC. Clients must use only
+// one of them per request.
+func (ac *AuthenticatorConfig) TokenFromHeaders(getHeader func(key string) string) (string, error) {
+	tokens := []string{}
+	for _, source := range ac.tokenSources() {
+		token, err := extractHeaderToken(getHeader, source)
+		if err != nil {
+			return "", err
+		}
+		if token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return singleToken(tokens)
+}
+
+// singleToken returns the only token found.
+func singleToken(tokens []string) (string, error) {
+	switch len(tokens) {
+	case 0:
+		return "", ErrTokenNotFound
+	case 1:
+		return tokens[0], nil
+	default:
+		return "", errMultipleTokens
+	}
+}
+
+// extractHeaderToken gets the access token from the header sources, ignoring
+// the other ones.
+func extractHeaderToken(getHeader func(key string) string, source TokenSource) (string, error) {
+	switch source {
+	case TokenSourceBearer:
+		header := getHeader("Authorization")
+		if header == "" {
+			return "", nil
+		}
+		if !strings.HasPrefix(strings.ToLower(header), bearerPrefix) {
+			return "", errInvalidAuthzHeader
+		}
+		return strings.TrimSpace(header[len(bearerPrefix):]), nil
+
+	case TokenSourceAuthentication:
+		return getHeader("Authentication"), nil
+	}
+
+	return "", nil
+}
+
+// wwwAuthenticate builds the WWW-Authenticate header value for a failed
+// authentication, following RFC 6750 section 3.
+func wwwAuthenticate(realm, errorCode, description string) string {
+	value := fmt.Sprintf("Bearer realm=%q", realm)
+	if errorCode != "" {
+		value += fmt.Sprintf(", error=%q", errorCode)
+	}
+	if description != "" {
+		value += fmt.Sprintf(", error_description=%q", description)
+	}
+
+	return value
+}
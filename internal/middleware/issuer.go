@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/jwt"
+)
+
+// TrustedIssuer defines an identity provider whose tokens are accepted by the
+// Authenticator, along with the rules to validate them.
+type TrustedIssuer struct {
+	// Issuer must match the "iss" claim of the token
+	Issuer string `json:"issuer"`
+
+	// JwksLocation is the URL of the issuer's JSON Web Key Set. If empty it
+	// is discovered from the OpenID provider metadata.
+	JwksLocation string `json:"jwks_location"`
+
+	// Algorithms lists the accepted signature algorithms. If empty any
+	// algorithm matching the signing key is accepted.
+	Algorithms []string `json:"algorithms"`
+
+	// TokenUse is the expected value of the "token_use" claim. If empty the
+	// claim is not checked.
+	TokenUse string `json:"token_use"`
+
+	// Audiences lists the accepted "aud" values. If empty the claim is not
+	// checked.
+	Audiences []string `json:"audiences"`
+
+	// ClientIdClaim is the claim holding the client identifier, defaults to
+	// "client_id"
+	ClientIdClaim string `json:"client_id_claim"`
+
+	// ScopeClaim is the claim holding the scopes, defaults to "scope"
+	ScopeClaim string `json:"scope_claim"`
+
+	// GroupsClaim is the claim holding the user groups, defaults to
+	// "cognito:groups"
+	GroupsClaim string `json:"groups_claim"`
+
+	// ClientCredentialsOnly requires the subject to be the client itself, as
+	// in tokens issued with the client credentials grant
+	ClientCredentialsOnly bool `json:"client_credentials_only"`
+
+	// KeySet is the cache of the issuer's signing keys
+	KeySet *KeySetCache `json:"-"`
+}
+
+// DefaultGroupsClaim is the claim where Cognito puts the user groups
+const DefaultGroupsClaim = "cognito:groups"
+
+// NewCognitoIssuer creates a TrustedIssuer for client credentials access
+// tokens issued by an AWS Cognito user pool.
+func NewCognitoIssuer(issuer string, keySet *KeySetCache) *TrustedIssuer {
+	return &TrustedIssuer{
+		Issuer:                issuer,
+		TokenUse:              "access",
+		ClientCredentialsOnly: true,
+		KeySet:                keySet,
+	}
+}
+
+// ParseTrustedIssuers reads a JSON list of trusted issuers, checking the
+// required fields.
+func ParseTrustedIssuers(data []byte) ([]*TrustedIssuer, error) {
+	issuers := []*TrustedIssuer{}
+	err := json.Unmarshal(data, &issuers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted issuers: %s", err)
+	}
+
+	if len(issuers) == 0 {
+		return nil, fmt.Errorf("invalid trusted issuers: at least one is required")
+	}
+
+	for i, ti := range issuers {
+		if ti.Issuer == "" {
+			return nil, fmt.Errorf("invalid trusted issuer %d: issuer is required", i)
+		}
+	}
+
+	return issuers, nil
+}
+
+// acceptsAlgorithm checks if the token signature algorithm is accepted.
+func (ti *TrustedIssuer) acceptsAlgorithm(alg string) bool {
+	return len(ti.Algorithms) == 0 || containsAny([]string{alg}, ti.Algorithms)
+}
+
+func (ti *TrustedIssuer) clientIdClaim() string {
+	if ti.ClientIdClaim == "" {
+		return ClientIdKey
+	}
+
+	return ti.ClientIdClaim
+}
+
+func (ti *TrustedIssuer) scopeClaim() string {
+	if ti.ScopeClaim == "" {
+		return ScopeKey
+	}
+
+	return ti.ScopeClaim
+}
+
+func (ti *TrustedIssuer) groupsClaim() string {
+	if ti.GroupsClaim == "" {
+		return DefaultGroupsClaim
+	}
+
+	return ti.GroupsClaim
+}
+
+// validateClaims checks the claims of a token with a valid signature.
+func (ti *TrustedIssuer) validateClaims(token jwt.Token) error {
+	options := []jwt.ValidateOption{
+		jwt.WithClaimValue(jwt.IssuerKey, ti.Issuer),
+		jwt.WithRequiredClaim(ti.clientIdClaim()),
+	}
+
+	if ti.TokenUse != "" {
+		options = append(options, jwt.WithClaimValue(TokenUseKey, ti.TokenUse))
+	}
+
+	if ti.ClientCredentialsOnly {
+		clientId, _ := token.Get(ti.clientIdClaim())
+		options = append(options,
+			jwt.WithRequiredClaim(jwt.SubjectKey),
+			jwt.WithClaimValue(jwt.SubjectKey, clientId))
+	}
+
+	err := jwt.Validate(token, options...)
+	if err != nil {
+		return err
+	}
+
+	if len(ti.Audiences) > 0 && !containsAny(token.Audience(), ti.Audiences) {
+		return fmt.Errorf("aud not satisfied")
+	}
+
+	return nil
+}
+
+// scopes returns the token scopes as a space separated list, the format used
+// in the Gin context. Some issuers send the scopes as a JSON array.
+func (ti *TrustedIssuer) scopes(token jwt.Token) string {
+	value, _ := token.Get(ti.scopeClaim())
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		scopes := []string{}
+		for _, s := range v {
+			scopes = append(scopes, fmt.Sprint(s))
+		}
+		return strings.Join(scopes, " ")
+	}
+
+	return ""
+}
+
+// groups returns the groups the token user belongs to. Client credentials
+// tokens have no groups.
+func (ti *TrustedIssuer) groups(token jwt.Token) []string {
+	value, _ := token.Get(ti.groupsClaim())
+
+	groups := []string{}
+	switch v := value.(type) {
+	case string:
+		groups = strings.Fields(v)
+	case []interface{}:
+		for _, g := range v {
+			groups = append(groups, fmt.Sprint(g))
+		}
+	}
+
+	return groups
+}
+
+func containsAny(values, accepted []string) bool {
+	for _, v := range values {
+		for _, a := range accepted {
+			if v == a {
+				return true
+			}
+		}
+	}
+
+	return false
+}
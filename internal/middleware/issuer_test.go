@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/lestrrat-go/jwx/jwt"
+	"github.com/renato0307/learning-go-api/internal/apitesting"
+	"github.com/stretchr/testify/assert"
+)
+
+const keycloakRealm string = "https://keycloak.example.com/realms/learning"
+
+func TestAuthenticatorWithMultipleIssuers(t *testing.T) {
+	// arrange - one key per issuer
+	cognitoKey := generateKey(t)
+	keycloakKey := generateKey(t)
+	keycloakKey.Set(jwk.KeyIDKey, "keycloakkey")
+
+	keycloak := &TrustedIssuer{
+		Issuer:        keycloakRealm,
+		Audiences:     []string{"learning-go-api"},
+		ClientIdClaim: "azp",
+		KeySet:        newKeySetCache(generateKeySetInJSON(&keycloakKey, t), t),
+	}
+	cognito := NewCognitoIssuer(
+		userPool,
+		newKeySetCache(generateKeySetInJSON(&cognitoKey, t), t))
+
+	// arrange - define the several test cases
+	testCases := []struct {
+		JWT        string
+		StatusCode int
+		Issuer     string
+		Scope      string
+		Purpose    string
+	}{
+		{
+			JWT:        newValidJWT(cognitoKey, t),
+			StatusCode: http.StatusOK,
+			Issuer:     userPool,
+			Scope:      "https://learning-go-api.com/all",
+			Purpose:    "cognito token",
+		},
+		{
+			JWT:        newKeycloakJWT(keycloakKey, keycloakRealm, "learning-go-api", t),
+			StatusCode: http.StatusOK,
+			Issuer:     keycloakRealm,
+			Scope:      "programming-uuid finance-currconv",
+			Purpose:    "keycloak token",
+		},
+		{
+			JWT:        newKeycloakJWT(cognitoKey, keycloakRealm, "learning-go-api", t),
+			StatusCode: http.StatusUnauthorized,
+			Purpose:    "keycloak token signed with the cognito key",
+		},
+		{
+			JWT:        newKeycloakJWT(keycloakKey, keycloakRealm, "another-api", t),
+			StatusCode: http.StatusUnauthorized,
+			Purpose:    "keycloak token for another audience",
+		},
+		{
+			JWT:        newKeycloakJWT(keycloakKey, "https://untrusted.example.com", "learning-go-api", t),
+			StatusCode: http.StatusUnauthorized,
+			Purpose:    "untrusted issuer",
+		},
+	}
+
+	for _, tc := range testCases {
+		// arrange - init gin to use the authenticator middleware
+		r := gin.New()
+		authConfig := AuthenticatorConfig{
+			Issuers: []*TrustedIssuer{cognito, keycloak},
+		}
+		r.Use(Authenticator(&authConfig))
+
+		// arrange - set the routes
+		r.GET("/example", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{
+				"client_id": c.GetString(ClientIdKey),
+				"iss":       c.GetString(IssuerKey),
+				"scope":     c.GetString(ScopeKey),
+			})
+		})
+
+		// arrange - headers
+		header := http.Header{}
+		header.Add("Authorization", "Bearer "+tc.JWT)
+
+		// act
+		w := apitesting.PerformRequestWithHeader(r, "GET", "/example", header)
+
+		// assert
+		assert.Equal(t, tc.StatusCode, w.Code, tc.Purpose)
+		if tc.StatusCode == http.StatusOK {
+			assert.Contains(t, w.Body.String(), tc.Issuer, tc.Purpose)
+			assert.Contains(t, w.Body.String(), tc.Scope, tc.Purpose)
+			assert.Contains(t, w.Body.String(), "client_id_1234567890", tc.Purpose)
+		}
+	}
+}
+
+func TestAuthenticatorWithUserToken(t *testing.T) {
+	// arrange - cognito issuer accepting user tokens
+	key := generateKey(t)
+	cognito := NewCognitoIssuer(userPool, newKeySetCache(generateKeySetInJSON(&key, t), t))
+	cognito.ClientCredentialsOnly = false
+
+	token := jwt.New()
+	token.Set("sub", "a1b2c3d4-0000-4000-8000-000000000000")
+	token.Set("token_use", "access")
+	token.Set("client_id", "client_id_1234567890")
+	token.Set("cognito:groups", []string{"finance-analysts", "developers"})
+	token.Set("scope", "aws.cognito.signin.user.admin")
+	token.Set("iss", userPool)
+	token.Set("exp", time.Now().Unix()+1000)
+	signed, err := signJWT(token, key)
+	assert.Nil(t, err)
+
+	r := gin.New()
+	r.Use(Authenticator(&AuthenticatorConfig{Issuers: []*TrustedIssuer{cognito}}))
+	r.GET("/example", func(c *gin.Context) {
+		c.JSON(http.StatusOK, c.GetStringSlice(GroupsKey))
+	})
+
+	header := http.Header{}
+	header.Add("Authorization", "Bearer "+string(signed))
+
+	// act
+	w := apitesting.PerformRequestWithHeader(r, "GET", "/example", header)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `["finance-analysts", "developers"]`, w.Body.String())
+
+	// act - client credentials only issuer rejects the user token
+	cognito.ClientCredentialsOnly = true
+	w = apitesting.PerformRequestWithHeader(r, "GET", "/example", header)
+
+	// assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestParseTrustedIssuers(t *testing.T) {
+	// arrange
+	data := []byte(`[{
+		"issuer": "https://keycloak.example.com/realms/learning",
+		"jwks_location": "https://keycloak.example.com/realms/learning/protocol/openid-connect/certs",
+		"audiences": ["learning-go-api"],
+		"client_id_claim": "azp",
+		"scope_claim": "scp",
+		"client_credentials_only": true
+	}]`)
+
+	// act
+	issuers, err := ParseTrustedIssuers(data)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Len(t, issuers, 1)
+	assert.Equal(t, "https://keycloak.example.com/realms/learning", issuers[0].Issuer)
+	assert.Equal(t, []string{"learning-go-api"}, issuers[0].Audiences)
+	assert.Equal(t, "azp", issuers[0].clientIdClaim())
+	assert.Equal(t, "scp", issuers[0].scopeClaim())
+	assert.True(t, issuers[0].ClientCredentialsOnly)
+}
+
+func TestParseTrustedIssuersWithInvalidData(t *testing.T) {
+	testCases := []struct {
+		Data    string
+		Purpose string
+	}{
+		{Data: "invalid json", Purpose: "invalid json"},
+		{Data: "[]", Purpose: "no issuers"},
+		{Data: `[{"jwks_location": "https://example.com"}]`, Purpose: "missing issuer"},
+	}
+
+	for _, tc := range testCases {
+		// act
+		_, err := ParseTrustedIssuers([]byte(tc.Data))
+
+		// assert
+		assert.NotNil(t, err, tc.Purpose)
+	}
+}
+
+func newKeycloakJWT(key jwk.Key, issuer, audience string, t *testing.T) string {
+	token := jwt.New()
+	token.Set("sub", "f1a2b3c4-0000-4000-8000-000000000000")
+	token.Set("azp", "client_id_1234567890")
+	token.Set("scope", []string{"programming-uuid", "finance-currconv"})
+	token.Set("iss", issuer)
+	token.Set("aud", audience)
+	token.Set("exp", time.Now().Unix()+1000)
+	token.Set("iat", time.Now().Unix())
+
+	signed, err := signJWT(token, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(signed)
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/renato0307/learning-go-api/internal/metrics"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultKeySetRefreshInterval is used when no refresh interval is set
+	DefaultKeySetRefreshInterval = time.Hour
+
+	// DefaultKeySetMinRefetchInterval limits how often an unknown key ID can
+	// trigger a download of the JSON Web Key Set
+	DefaultKeySetMinRefetchInterval = time.Minute
+)
+
+// KeySetCache keeps a parsed JSON Web Key Set in memory and refreshes it from
+// its location, so signing key rotations are picked up without restarts.
+//
+// When a refresh fails the last good key set keeps being served.
+type KeySetCache struct {
+	Location           string
+	RefreshInterval    time.Duration
+	MinRefetchInterval time.Duration
+	Client             *http.Client
+
+	mu        sync.RWMutex
+	set       jwk.Set
+	lastFetch time.Time
+}
+
+// NewKeySetCache creates a new KeySetCache for the JWKS available at the
+// location. If the refreshInterval is not positive a default value will be
+// set.
+func NewKeySetCache(location string, refreshInterval time.Duration) *KeySetCache {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultKeySetRefreshInterval
+	}
+
+	return &KeySetCache{
+		Location:           location,
+		RefreshInterval:    refreshInterval,
+		MinRefetchInterval: DefaultKeySetMinRefetchInterval,
+		Client:             &http.Client{Timeout: 10 * time.Second},
+		set:                jwk.NewSet(),
+	}
+}
+
+// Refresh downloads and parses the key set, replacing the cached one only if
+// both steps succeed.
+func (k *KeySetCache) Refresh() error {
+	err := k.refresh()
+	metrics.KeySetRefreshes.WithLabelValues(k.Location, metrics.Result(err)).Inc()
+
+	return err
+}
+
+func (k *KeySetCache) refresh() error {
+	k.mu.Lock()
+	k.lastFetch = time.Now()
+	k.mu.Unlock()
+
+	r, err := k.Client.Get(k.Location)
+	if err != nil {
+		return fmt.Errorf("cannot get the JWKS content: %s", err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot get the JWKS content: %d", r.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("cannot read the JWKS content: %s", err)
+	}
+
+	set, err := jwk.Parse(body)
+	if err != nil {
+		return fmt.Errorf("failed to parse keyset: %s", err)
+	}
+
+	k.mu.Lock()
+	k.set = set
+	k.mu.Unlock()
+
+	log.Debug().
+		Str("auth_jwks_location", k.Location).
+		Int("keys", set.Len()).
+		Msg("key set refreshed")
+
+	return nil
+}
+
+// Start refreshes the key set in the background every RefreshInterval until
+// the context is cancelled. Refresh failures are logged.
+func (k *KeySetCache) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(k.RefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				k.refreshAndLog("periodic")
+			}
+		}
+	}()
+}
+
+// KeySet returns the last good key set.
+func (k *KeySetCache) KeySet() jwk.Set {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.set
+}
+
+// LookupKeyID finds a key by its identifier. An unknown key ID triggers a
+// refetch of the key set, at most once every MinRefetchInterval.
+func (k *KeySetCache) LookupKeyID(kid string) (jwk.Key, bool) {
+	if key, found := k.KeySet().LookupKeyID(kid); found {
+		return key, true
+	}
+
+	// reserves the refetch so concurrent requests do not download it again
+	k.mu.Lock()
+	if time.Since(k.lastFetch) < k.MinRefetchInterval {
+		k.mu.Unlock()
+		return nil, false
+	}
+	k.lastFetch = time.Now()
+	k.mu.Unlock()
+
+	k.refreshAndLog("unknown kid")
+	return k.KeySet().LookupKeyID(kid)
+}
+
+func (k *KeySetCache) refreshAndLog(reason string) {
+	err := k.Refresh()
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("auth_jwks_location", k.Location).
+			Str("reason", reason).
+			Msg("key set refresh failed, keeping last good key set")
+	}
+}
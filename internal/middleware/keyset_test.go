@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeySetCacheRefresh(t *testing.T) {
+	// arrange
+	key := generateKey(t)
+	keySet := newKeySetCache(generateKeySetInJSON(&key, t), t)
+
+	// act
+	_, found := keySet.LookupKeyID("mykey")
+
+	// assert
+	assert.True(t, found)
+	assert.Equal(t, 1, keySet.KeySet().Len())
+}
+
+func TestKeySetCacheKeepsLastGoodKeySet(t *testing.T) {
+	// arrange - the first download works, the following ones fail
+	key := generateKey(t)
+	jwks := generateKeySetInJSON(&key, t)
+
+	var calls int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(jwks)
+	}))
+	defer svr.Close()
+
+	keySet := NewKeySetCache(svr.URL, 0)
+	assert.Nil(t, keySet.Refresh())
+
+	// act
+	err := keySet.Refresh()
+
+	// assert
+	assert.NotNil(t, err)
+	_, found := keySet.LookupKeyID("mykey")
+	assert.True(t, found)
+}
+
+func TestKeySetCacheRefetchesOnUnknownKeyID(t *testing.T) {
+	// arrange - the key is rotated after the first download
+	oldKey := generateKey(t)
+	oldKey.Set("kid", "oldkey")
+	newKey := generateKey(t)
+	newKey.Set("kid", "newkey")
+
+	var calls int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			w.Write(generateKeySetInJSON(&newKey, t))
+			return
+		}
+		w.Write(generateKeySetInJSON(&oldKey, t))
+	}))
+	defer svr.Close()
+
+	keySet := NewKeySetCache(svr.URL, 0)
+	keySet.MinRefetchInterval = 0
+	assert.Nil(t, keySet.Refresh())
+
+	// act
+	_, found := keySet.LookupKeyID("newkey")
+
+	// assert
+	assert.True(t, found)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestKeySetCacheLimitsRefetches(t *testing.T) {
+	// arrange
+	key := generateKey(t)
+	jwks := generateKeySetInJSON(&key, t)
+
+	var calls int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write(jwks)
+	}))
+	defer svr.Close()
+
+	keySet := NewKeySetCache(svr.URL, 0)
+	assert.Nil(t, keySet.Refresh())
+
+	// act
+	_, found1 := keySet.LookupKeyID("unknown")
+	_, found2 := keySet.LookupKeyID("unknown")
+
+	// assert
+	assert.False(t, found1)
+	assert.False(t, found2)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestKeySetCacheStart(t *testing.T) {
+	// arrange
+	key := generateKey(t)
+	jwks := generateKeySetInJSON(&key, t)
+
+	var calls int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write(jwks)
+	}))
+	defer svr.Close()
+
+	keySet := NewKeySetCache(svr.URL, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// act
+	keySet.Start(ctx)
+
+	// assert
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) >= 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, keySet.KeySet().Len())
+}
+
+func TestNewKeySetCacheWithInvalidInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		// act
+		keySet := NewKeySetCache("https://example.com/jwks.json", interval)
+
+		// assert
+		assert.Equal(t, DefaultKeySetRefreshInterval, keySet.RefreshInterval)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/requestid"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // DefaultStructuredLogger logs a gin HTTP request in JSON format. Uses the
@@ -53,6 +55,15 @@ func StructuredLogger(logger *zerolog.Logger) gin.HandlerFunc {
 			logEvent = logger.Info()
 		}
 
+		// Correlates the log line with the handler logs and the trace
+		if requestId := requestid.Get(c); requestId != "" {
+			logEvent.Str(requestid.Key, requestId)
+		}
+		spanContext := trace.SpanContextFromContext(c.Request.Context())
+		if spanContext.IsValid() {
+			logEvent.Str("trace_id", spanContext.TraceID().String())
+		}
+
 		logEvent.Str("client_id", param.ClientIP).
 			Str("method", param.Method).
 			Int("status_code", param.StatusCode).
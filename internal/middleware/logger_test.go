@@ -17,6 +17,7 @@ func TestStructuredLogger(t *testing.T) {
 
 	// arrange - init gin to use the structured logger middleware
 	r := gin.New()
+	r.Use(RequestID())
 	r.Use(StructuredLogger(&memLogger))
 	r.Use(gin.Recovery())
 
@@ -30,6 +31,7 @@ func TestStructuredLogger(t *testing.T) {
 	assert.Contains(t, buffer.String(), "GET")
 	assert.Contains(t, buffer.String(), "/example")
 	assert.Contains(t, buffer.String(), "a=100")
+	assert.Contains(t, buffer.String(), `"request_id":`)
 
 	buffer.Reset()
 	apitesting.PerformRequest(r, "GET", "/notfound")
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/metrics"
+)
+
+// unmatchedRoute labels the requests not matching any route, so unknown paths
+// do not create new time series
+const unmatchedRoute = "unmatched"
+
+// Metrics records the count, latency and in-flight HTTP requests, labeled by
+// route template, method, status class and client.
+//
+// It must run before the Authenticator to record the rejected requests too.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+
+		inFlight := metrics.HTTPRequestsInFlight.WithLabelValues(route, method)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		// Process request
+		c.Next()
+
+		statusClass := metrics.StatusClass(c.Writer.Status())
+		clientId := c.GetString(ClientIdKey)
+
+		metrics.HTTPRequests.
+			WithLabelValues(route, method, statusClass, clientId).
+			Inc()
+		metrics.HTTPRequestDuration.
+			WithLabelValues(route, method, statusClass, clientId).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// MetricsHandler serves the metrics in the Prometheus text format. If the
+// token is set, scrapers must send it as "Authorization: Bearer <token>".
+//
+// Returns HTTP 401 if the token does not match.
+func MetricsHandler(token string) gin.HandlerFunc {
+	handler := metrics.Handler()
+
+	return func(c *gin.Context) {
+		if token != "" {
+			scraperToken, err := extractTokenFrom(c, TokenSourceBearer)
+			valid := subtle.ConstantTimeCompare([]byte(scraperToken), []byte(token)) == 1
+			if err != nil || !valid {
+				c.Header("WWW-Authenticate", wwwAuthenticate(realm, "", ""))
+				apierror.Abort(c, apierror.New(c, apierror.CodeUnauthorized, ""))
+				return
+			}
+		}
+
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
+}
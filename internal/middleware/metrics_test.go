@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/renato0307/learning-go-api/internal/apitesting"
+	"github.com/renato0307/learning-go-api/internal/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	// arrange - init gin to use the metrics middleware
+	r := gin.New()
+	r.Use(Metrics())
+	r.Use(func(c *gin.Context) { // fake Authenticator
+		c.Set(ClientIdKey, "client-metrics")
+	})
+
+	// arrange - set the routes
+	r.GET("/v1/items/:id", func(c *gin.Context) {})
+
+	requests := metrics.HTTPRequests.
+		WithLabelValues("/v1/items/:id", "GET", "2xx", "client-metrics")
+	notFound := metrics.HTTPRequests.
+		WithLabelValues(unmatchedRoute, "GET", "4xx", "client-metrics")
+	before := testutil.ToFloat64(requests)
+	beforeNotFound := testutil.ToFloat64(notFound)
+
+	// act
+	apitesting.PerformRequest(r, "GET", "/v1/items/1")
+	apitesting.PerformRequest(r, "GET", "/v1/items/2")
+	apitesting.PerformRequest(r, "GET", "/v1/unknown")
+
+	// assert
+	assert.Equal(t, before+2, testutil.ToFloat64(requests))
+	assert.Equal(t, beforeNotFound+1, testutil.ToFloat64(notFound))
+	assert.Equal(t, 0.0, testutil.ToFloat64(
+		metrics.HTTPRequestsInFlight.WithLabelValues("/v1/items/:id", "GET")))
+}
+
+func TestMetricsHandler(t *testing.T) {
+	testCases := []struct {
+		Token      string
+		Header     http.Header
+		StatusCode int
+		Purpose    string
+	}{
+		{
+			Header:     http.Header{},
+			StatusCode: http.StatusOK,
+			Purpose:    "no token required",
+		},
+		{
+			Token:      "secret",
+			Header:     http.Header{"Authorization": {"Bearer secret"}},
+			StatusCode: http.StatusOK,
+			Purpose:    "valid token",
+		},
+		{
+			Token:      "secret",
+			Header:     http.Header{"Authorization": {"Bearer another"}},
+			StatusCode: http.StatusUnauthorized,
+			Purpose:    "invalid token",
+		},
+		{
+			Token:      "secret",
+			Header:     http.Header{},
+			StatusCode: http.StatusUnauthorized,
+			Purpose:    "missing token",
+		},
+	}
+
+	for _, tc := range testCases {
+		// arrange
+		r := gin.New()
+		r.GET("/metrics", MetricsHandler(tc.Token))
+
+		// act
+		w := apitesting.PerformRequestWithHeader(r, "GET", "/metrics", tc.Header)
+
+		// assert
+		assert.Equal(t, tc.StatusCode, w.Code, tc.Purpose)
+		if tc.StatusCode == http.StatusOK {
+			assert.Contains(t, w.Body.String(), "learning_go_api_", tc.Purpose)
+		}
+	}
+}
+
+func TestAuthenticatorPublicPaths(t *testing.T) {
+	// arrange
+	r := gin.New()
+	r.Use(Authenticator(&AuthenticatorConfig{PublicPaths: []string{"/metrics"}}))
+	r.GET("/", func(c *gin.Context) {})
+	r.GET("/metrics", func(c *gin.Context) {})
+
+	// act & assert
+	w := apitesting.PerformRequest(r, "GET", "/metrics")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = apitesting.PerformRequest(r, "GET", "/")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// discoveryPath is where OpenID providers publish their metadata, relative to
+// the issuer (OpenID Connect Discovery 1.0, section 4)
+const discoveryPath = "/.well-known/openid-configuration"
+
+// DiscoveryTimeout bounds the download of the provider metadata, so a slow
+// issuer does not block the startup
+const DiscoveryTimeout = 10 * time.Second
+
+// ProviderMetadata holds the OpenID provider metadata fields used by the
+// Authenticator.
+type ProviderMetadata struct {
+	Issuer                           string   `json:"issuer"`
+	JwksURI                          string   `json:"jwks_uri"`
+	IdTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// DiscoverProviderMetadata downloads the OpenID provider metadata of the
+// issuer, checking the metadata belongs to it.
+func DiscoverProviderMetadata(client *http.Client, issuer string) (*ProviderMetadata, error) {
+	location := strings.TrimSuffix(issuer, "/") + discoveryPath
+
+	r, err := client.Get(location)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the provider metadata: %s", err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot get the provider metadata: %d", r.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the provider metadata: %s", err)
+	}
+
+	metadata := ProviderMetadata{}
+	err = json.Unmarshal(body, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the provider metadata: %s", err)
+	}
+
+	if metadata.Issuer != issuer {
+		return nil, fmt.Errorf("provider metadata issuer %q does not match %q",
+			metadata.Issuer, issuer)
+	}
+
+	if metadata.JwksURI == "" {
+		return nil, fmt.Errorf("provider metadata has no jwks_uri")
+	}
+
+	return &metadata, nil
+}
+
+// Discover fills the JWKS location and the accepted algorithms of the issuer
+// from its OpenID provider metadata.
+func (ti *TrustedIssuer) Discover(client *http.Client) error {
+	metadata, err := DiscoverProviderMetadata(client, ti.Issuer)
+	if err != nil {
+		return err
+	}
+
+	ti.JwksLocation = metadata.JwksURI
+	if len(ti.Algorithms) == 0 {
+		ti.Algorithms = metadata.IdTokenSigningAlgValuesSupported
+	}
+
+	return nil
+}
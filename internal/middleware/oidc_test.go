@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoverProviderMetadata(t *testing.T) {
+	// arrange
+	svr := newFakeOidcServer(t, "", `"https://example.com/jwks.json"`)
+
+	// act
+	metadata, err := DiscoverProviderMetadata(http.DefaultClient, svr.URL)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, svr.URL, metadata.Issuer)
+	assert.Equal(t, "https://example.com/jwks.json", metadata.JwksURI)
+	assert.Equal(t, []string{"RS256"}, metadata.IdTokenSigningAlgValuesSupported)
+}
+
+func TestDiscoverProviderMetadataWithErrors(t *testing.T) {
+	testCases := []struct {
+		Issuer  string
+		JwksURI string
+		Purpose string
+	}{
+		{
+			Issuer:  "https://another-issuer.example.com",
+			JwksURI: `"https://example.com/jwks.json"`,
+			Purpose: "issuer does not match",
+		},
+		{
+			JwksURI: `""`,
+			Purpose: "missing jwks_uri",
+		},
+		{
+			JwksURI: `{}`,
+			Purpose: "invalid json",
+		},
+	}
+
+	for _, tc := range testCases {
+		// arrange
+		svr := newFakeOidcServer(t, tc.Issuer, tc.JwksURI)
+
+		// act
+		_, err := DiscoverProviderMetadata(http.DefaultClient, svr.URL)
+
+		// assert
+		assert.NotNil(t, err, tc.Purpose)
+	}
+}
+
+func TestDiscoverProviderMetadataWithMissingMetadata(t *testing.T) {
+	// arrange
+	svr := httptest.NewServer(http.NotFoundHandler())
+	defer svr.Close()
+
+	// act
+	_, err := DiscoverProviderMetadata(http.DefaultClient, svr.URL)
+
+	// assert
+	assert.NotNil(t, err)
+}
+
+func TestTrustedIssuerDiscover(t *testing.T) {
+	// arrange
+	svr := newFakeOidcServer(t, "", `"https://example.com/jwks.json"`)
+	issuer := TrustedIssuer{Issuer: svr.URL}
+
+	// act
+	err := issuer.Discover(http.DefaultClient)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/jwks.json", issuer.JwksLocation)
+	assert.Equal(t, []string{"RS256"}, issuer.Algorithms)
+	assert.True(t, issuer.acceptsAlgorithm("RS256"))
+	assert.False(t, issuer.acceptsAlgorithm("HS256"))
+}
+
+// newFakeOidcServer serves the OpenID provider metadata. If the issuer is
+// empty the server URL is used.
+func newFakeOidcServer(t *testing.T, issuer, jwksURI string) *httptest.Server {
+	mux := http.NewServeMux()
+	svr := httptest.NewServer(mux)
+	t.Cleanup(svr.Close)
+
+	if issuer == "" {
+		issuer = svr.URL
+	}
+
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{
+			"issuer": "%s",
+			"jwks_uri": %s,
+			"id_token_signing_alg_values_supported": ["RS256"]
+		}`, issuer, jwksURI)
+	})
+
+	return svr
+}
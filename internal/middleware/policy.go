@@ -0,0 +1,318 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultScopePrefix is the resource server identifier prepended to the
+// scopes, as in "https://learninggolang.com/programming-uuid"
+const DefaultScopePrefix = "https://learninggolang.com/"
+
+// pathWildcard can end a role path to match all the routes below it, as in
+// "/v1/finance/*"
+const pathWildcard = "/*"
+
+// Modes combining the scope and the role checks
+const (
+	// ModeAnyOf grants access if either the scopes or the groups allow it
+	ModeAnyOf = "any-of"
+
+	// ModeAllOf grants access only if both the scopes and the groups allow it
+	ModeAllOf = "all-of"
+)
+
+// scopeWildcard can end a client scope to grant all the scopes starting with
+// the same text, as in "programming-*"
+const scopeWildcard = "*"
+
+// RoutePolicy defines the scopes required to call a route.
+type RoutePolicy struct {
+	// Method is the HTTP method of the route
+	Method string `json:"method"`
+
+	// Path is the gin route template, as in "/v1/items/:id"
+	Path string `json:"path"`
+
+	// Scopes lists the accepted scopes, without the scope prefix. Any of them
+	// grants access. Routes only allowed to groups may have no scopes.
+	Scopes []string `json:"scopes"`
+
+	// Public routes do not require any scope
+	Public bool `json:"public"`
+
+	// Mode overrides the policy mode for the route
+	Mode string `json:"mode"`
+}
+
+// RolePolicy grants the members of a group access to routes.
+type RolePolicy struct {
+	// Group is matched with the user groups, as in "cognito:groups"
+	Group string `json:"group"`
+
+	// Methods lists the HTTP methods allowed. If empty all are allowed.
+	Methods []string `json:"methods"`
+
+	// Paths lists the gin route templates allowed. A path ending in "/*"
+	// matches all the routes below it.
+	Paths []string `json:"paths"`
+}
+
+// AuthorizationPolicy maps the routes to the scopes and groups required to
+// call them.
+type AuthorizationPolicy struct {
+	// ScopePrefix is prepended to the required scopes before comparing them
+	// with the client scopes
+	ScopePrefix string `json:"scope_prefix"`
+
+	// Mode combines the scope and role checks, ModeAnyOf or ModeAllOf.
+	// Defaults to ModeAnyOf.
+	Mode string `json:"mode"`
+
+	Routes []RoutePolicy `json:"routes"`
+	Roles  []RolePolicy  `json:"roles"`
+}
+
+// NewAuthorizationPolicy creates an empty policy. If the scopePrefix is empty
+// a default value will be set.
+func NewAuthorizationPolicy(scopePrefix string) *AuthorizationPolicy {
+	if scopePrefix == "" {
+		scopePrefix = DefaultScopePrefix
+	}
+
+	return &AuthorizationPolicy{ScopePrefix: scopePrefix}
+}
+
+// ParseAuthorizationPolicy reads a policy in JSON format. Non public routes
+// must have scopes or a role granting access to them.
+func ParseAuthorizationPolicy(data []byte) (*AuthorizationPolicy, error) {
+	policy := AuthorizationPolicy{}
+	err := json.Unmarshal(data, &policy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization policy: %s", err)
+	}
+
+	for i, role := range policy.Roles {
+		if role.Group == "" || len(role.Paths) == 0 {
+			return nil, fmt.Errorf("invalid role policy %d: group and paths are required", i)
+		}
+		for j, method := range role.Methods {
+			policy.Roles[i].Methods[j] = strings.ToUpper(method)
+		}
+	}
+
+	for i, rp := range policy.Routes {
+		if rp.Method == "" || rp.Path == "" {
+			return nil, fmt.Errorf("invalid route policy %d: method and path are required", i)
+		}
+		if len(rp.Scopes) == 0 && !rp.Public && !policy.hasRole(rp) {
+			return nil, fmt.Errorf("invalid route policy %d: scopes or a role are required for non public routes", i)
+		}
+		if !validMode(rp.Mode) {
+			return nil, fmt.Errorf("invalid route policy %d: unknown mode %q", i, rp.Mode)
+		}
+	}
+
+	if !validMode(policy.Mode) {
+		return nil, fmt.Errorf("invalid authorization policy: unknown mode %q", policy.Mode)
+	}
+
+	return &policy, nil
+}
+
+// Register adds the route policies, with paths relative to the basePath.
+// Policies already registered for the same method and path are replaced.
+func (p *AuthorizationPolicy) Register(basePath string, routes ...RoutePolicy) {
+	for _, rp := range routes {
+		rp.Method = strings.ToUpper(rp.Method)
+		rp.Path = joinPaths(basePath, rp.Path)
+
+		if i, found := p.indexOf(rp.Method, rp.Path); found {
+			p.Routes[i] = rp
+			continue
+		}
+		p.Routes = append(p.Routes, rp)
+	}
+}
+
+// Merge adds the routes and roles of another policy, replacing the routes with
+// the same method and path. The scope prefix and the mode are replaced if the
+// other one defines them.
+func (p *AuthorizationPolicy) Merge(other *AuthorizationPolicy) {
+	if other.ScopePrefix != "" {
+		p.ScopePrefix = other.ScopePrefix
+	}
+	if other.Mode != "" {
+		p.Mode = other.Mode
+	}
+
+	p.Register("", other.Routes...)
+	p.Roles = append(p.Roles, other.Roles...)
+}
+
+// Find returns the policy of a route.
+func (p *AuthorizationPolicy) Find(method, path string) (RoutePolicy, bool) {
+	i, found := p.indexOf(method, path)
+	if !found {
+		return RoutePolicy{}, false
+	}
+
+	return p.Routes[i], true
+}
+
+// CheckRoutes makes sure every gin route has a policy, so no route is
+// accidentally left unprotected or unreachable.
+func (p *AuthorizationPolicy) CheckRoutes(routes gin.RoutesInfo) error {
+	missing := []string{}
+	for _, route := range routes {
+		if _, found := p.Find(route.Method, route.Path); !found {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("routes without authorization policy: %s",
+			strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// Allows checks if the client scopes or the user groups grant access to the
+// route, combining both checks according to the mode.
+func (p *AuthorizationPolicy) Allows(rp RoutePolicy, clientScopes, groups []string) bool {
+	if rp.Public {
+		return true
+	}
+
+	scopesAllow := p.scopesAllow(rp, clientScopes)
+	groupsAllow := p.groupsAllow(rp, groups)
+
+	if p.mode(rp) == ModeAllOf {
+		return scopesAllow && groupsAllow
+	}
+
+	return scopesAllow || groupsAllow
+}
+
+// scopesAllow checks if any of the client scopes grants access to the route.
+// Scopes are compared exactly, after removing the scope prefix, unless the
+// client scope ends with a wildcard.
+func (p *AuthorizationPolicy) scopesAllow(rp RoutePolicy, clientScopes []string) bool {
+	for _, clientScope := range clientScopes {
+		if !strings.HasPrefix(clientScope, p.ScopePrefix) {
+			continue
+		}
+		clientScope = strings.TrimPrefix(clientScope, p.ScopePrefix)
+
+		for _, scope := range rp.Scopes {
+			if scopeMatches(clientScope, scope) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// groupsAllow checks if any role of the user groups grants access to the
+// route.
+func (p *AuthorizationPolicy) groupsAllow(rp RoutePolicy, groups []string) bool {
+	for _, role := range p.Roles {
+		if containsAny([]string{role.Group}, groups) && role.grants(rp) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasRole checks if any role grants access to the route.
+func (p *AuthorizationPolicy) hasRole(rp RoutePolicy) bool {
+	for _, role := range p.Roles {
+		if role.grants(rp) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *AuthorizationPolicy) mode(rp RoutePolicy) string {
+	if rp.Mode != "" {
+		return rp.Mode
+	}
+	if p.Mode != "" {
+		return p.Mode
+	}
+
+	return ModeAnyOf
+}
+
+func (p *AuthorizationPolicy) indexOf(method, path string) (int, bool) {
+	for i, rp := range p.Routes {
+		if rp.Method == method && rp.Path == path {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// grants checks if the role methods and paths include the route.
+func (role RolePolicy) grants(rp RoutePolicy) bool {
+	if len(role.Methods) > 0 && !containsAny([]string{strings.ToUpper(rp.Method)}, role.Methods) {
+		return false
+	}
+
+	for _, rolePath := range role.Paths {
+		if pathMatches(rolePath, rp.Path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scopeMatches compares a client scope, which can end with a wildcard, with
+// a required scope.
+func scopeMatches(clientScope, scope string) bool {
+	if strings.HasSuffix(clientScope, scopeWildcard) {
+		return strings.HasPrefix(scope, strings.TrimSuffix(clientScope, scopeWildcard))
+	}
+
+	return clientScope == scope
+}
+
+// pathMatches compares a role path, which can end with a wildcard, with a
+// route template.
+func pathMatches(rolePath, route string) bool {
+	if strings.HasSuffix(rolePath, pathWildcard) {
+		return strings.HasPrefix(route, strings.TrimSuffix(rolePath, "*"))
+	}
+
+	return rolePath == route
+}
+
+func validMode(mode string) bool {
+	return mode == "" || mode == ModeAnyOf || mode == ModeAllOf
+}
+
+// joinPaths joins the paths like gin does for router groups, keeping the
+// trailing slash.
+func joinPaths(basePath, relativePath string) string {
+	if basePath == "" {
+		return relativePath
+	}
+
+	joined := path.Join(basePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(joined, "/") {
+		return joined + "/"
+	}
+
+	return joined
+}
@@ -0,0 +1,250 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorizationPolicyRegister(t *testing.T) {
+	// arrange
+	policy := NewAuthorizationPolicy("")
+
+	// act
+	policy.Register("/v1/finance",
+		RoutePolicy{Method: "get", Path: "/currconv", Scopes: []string{"finance-currconv"}},
+		RoutePolicy{Method: "GET", Path: "/rates/", Scopes: []string{"finance-rates"}})
+	policy.Register("/v1/finance",
+		RoutePolicy{Method: "GET", Path: "/currconv", Scopes: []string{"finance-*"}})
+
+	// assert
+	assert.Equal(t, DefaultScopePrefix, policy.ScopePrefix)
+	assert.Len(t, policy.Routes, 2)
+
+	rp, found := policy.Find("GET", "/v1/finance/currconv")
+	assert.True(t, found)
+	assert.Equal(t, []string{"finance-*"}, rp.Scopes)
+
+	_, found = policy.Find("GET", "/v1/finance/rates/")
+	assert.True(t, found)
+
+	_, found = policy.Find("POST", "/v1/finance/currconv")
+	assert.False(t, found)
+}
+
+func TestAuthorizationPolicyMerge(t *testing.T) {
+	// arrange
+	policy := NewAuthorizationPolicy("")
+	policy.Register("/v1/programming",
+		RoutePolicy{Method: "POST", Path: "/uuid", Scopes: []string{"programming-uuid"}})
+
+	filePolicy, err := ParseAuthorizationPolicy([]byte(`{
+		"scope_prefix": "https://api.example.com/",
+		"routes": [
+			{"method": "POST", "path": "/v1/programming/uuid", "scopes": ["uuid"]},
+			{"method": "GET", "path": "/healthz", "public": true}
+		]
+	}`))
+	assert.Nil(t, err)
+
+	// act
+	policy.Merge(filePolicy)
+
+	// assert
+	assert.Equal(t, "https://api.example.com/", policy.ScopePrefix)
+	assert.Len(t, policy.Routes, 2)
+
+	rp, _ := policy.Find("POST", "/v1/programming/uuid")
+	assert.Equal(t, []string{"uuid"}, rp.Scopes)
+}
+
+func TestParseAuthorizationPolicyWithInvalidData(t *testing.T) {
+	testCases := []struct {
+		Data    string
+		Purpose string
+	}{
+		{Data: "invalid json", Purpose: "invalid json"},
+		{Data: `{"routes": [{"path": "/", "public": true}]}`, Purpose: "missing method"},
+		{Data: `{"routes": [{"method": "GET", "path": "/v1/x"}]}`, Purpose: "missing scopes"},
+		{
+			Data: `{
+				"routes": [{"method": "GET", "path": "/v1/x"}],
+				"roles": [{"group": "developers", "methods": ["POST"], "paths": ["/v1/x"]}]
+			}`,
+			Purpose: "missing scopes with a role for another method",
+		},
+	}
+
+	for _, tc := range testCases {
+		// act
+		_, err := ParseAuthorizationPolicy([]byte(tc.Data))
+
+		// assert
+		assert.NotNil(t, err, tc.Purpose)
+	}
+}
+
+func TestAuthorizationPolicyCheckRoutes(t *testing.T) {
+	// arrange
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) {})
+	r.POST("/v1/programming/uuid", func(c *gin.Context) {})
+	r.GET("/v1/items/:id", func(c *gin.Context) {})
+
+	policy := NewAuthorizationPolicy("")
+	policy.Register("", RoutePolicy{Method: "GET", Path: "/", Public: true})
+	policy.Register("/v1/programming",
+		RoutePolicy{Method: "POST", Path: "/uuid", Scopes: []string{"programming-uuid"}})
+
+	// act
+	err := policy.CheckRoutes(r.Routes())
+
+	// assert
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "GET /v1/items/:id")
+	assert.NotContains(t, err.Error(), "/v1/programming/uuid")
+
+	// act - with all routes covered
+	policy.Register("/v1", RoutePolicy{Method: "GET", Path: "/items/:id", Scopes: []string{"items"}})
+	err = policy.CheckRoutes(r.Routes())
+
+	// assert
+	assert.Nil(t, err)
+}
+
+func TestAuthorizationPolicyAllows(t *testing.T) {
+	// arrange
+	policy := NewAuthorizationPolicy("https://api.example.com/")
+	rp := RoutePolicy{Scopes: []string{"programming-uuid", "programming-all"}}
+
+	testCases := []struct {
+		ClientScopes []string
+		Allowed      bool
+		Purpose      string
+	}{
+		{[]string{"https://api.example.com/programming-uuid"}, true, "exact match"},
+		{[]string{"https://api.example.com/programming-all"}, true, "any of the scopes"},
+		{[]string{"https://api.example.com/programming-*"}, true, "wildcard"},
+		{[]string{"https://api.example.com/*"}, true, "full wildcard"},
+		{[]string{"https://api.example.com/programming-uuid-v2"}, false, "longer scope"},
+		{[]string{"https://api.example.com/evil-programming-uuid"}, false, "suffix only"},
+		{[]string{"programming-uuid"}, false, "no prefix"},
+		{[]string{}, false, "no scopes"},
+	}
+
+	for _, tc := range testCases {
+		// act
+		allowed := policy.Allows(rp, tc.ClientScopes, []string{})
+
+		// assert
+		assert.Equal(t, tc.Allowed, allowed, tc.Purpose)
+	}
+
+	assert.True(t, policy.Allows(RoutePolicy{Public: true}, []string{}, []string{}))
+}
+
+func TestAuthorizationPolicyAllowsWithRoles(t *testing.T) {
+	// arrange
+	filePolicy, err := ParseAuthorizationPolicy([]byte(`{
+		"roles": [
+			{"group": "finance-analysts", "paths": ["/v1/finance/*"]},
+			{"group": "developers", "methods": ["POST"], "paths": ["/v1/programming/uuid"]}
+		]
+	}`))
+	assert.Nil(t, err)
+
+	policy := NewAuthorizationPolicy("")
+	policy.Merge(filePolicy)
+
+	currconv := RoutePolicy{Method: "GET", Path: "/v1/finance/currconv", Scopes: []string{"finance-currconv"}}
+	uuid := RoutePolicy{Method: "POST", Path: "/v1/programming/uuid", Scopes: []string{"programming-uuid"}}
+	jwt := RoutePolicy{Method: "POST", Path: "/v1/programming/jwt", Scopes: []string{"programming-jwt"}}
+	scopes := []string{DefaultScopePrefix + "finance-currconv"}
+
+	testCases := []struct {
+		Mode         string
+		Route        RoutePolicy
+		ClientScopes []string
+		Groups       []string
+		Allowed      bool
+		Purpose      string
+	}{
+		{ModeAnyOf, currconv, []string{}, []string{"finance-analysts"}, true, "group with wildcard path"},
+		{ModeAnyOf, currconv, scopes, []string{}, true, "scope only"},
+		{ModeAnyOf, currconv, []string{}, []string{"developers"}, false, "group without access"},
+		{ModeAnyOf, uuid, []string{}, []string{"developers"}, true, "group with exact path"},
+		{ModeAnyOf, jwt, []string{}, []string{"developers"}, false, "group for another path"},
+		{ModeAllOf, currconv, scopes, []string{"finance-analysts"}, true, "scope and group"},
+		{ModeAllOf, currconv, scopes, []string{}, false, "scope without group"},
+		{ModeAllOf, currconv, []string{}, []string{"finance-analysts"}, false, "group without scope"},
+	}
+
+	for _, tc := range testCases {
+		// act
+		policy.Mode = tc.Mode
+		allowed := policy.Allows(tc.Route, tc.ClientScopes, tc.Groups)
+
+		// assert
+		assert.Equal(t, tc.Allowed, allowed, tc.Purpose)
+	}
+
+	// act & assert - route mode overrides the policy mode
+	policy.Mode = ModeAllOf
+	currconv.Mode = ModeAnyOf
+	assert.True(t, policy.Allows(currconv, scopes, []string{}))
+
+	// act & assert - methods are checked
+	getUuid := RoutePolicy{Method: "GET", Path: "/v1/programming/uuid"}
+	assert.False(t, policy.Allows(getUuid, []string{}, []string{"developers"}))
+}
+
+func TestParseAuthorizationPolicyWithRoleOnlyRoute(t *testing.T) {
+	// act
+	policy, err := ParseAuthorizationPolicy([]byte(`{
+		"routes": [{"method": "GET", "path": "/v1/finance/reports"}],
+		"roles": [{"group": "finance-analysts", "paths": ["/v1/finance/*"]}]
+	}`))
+
+	// assert
+	assert.Nil(t, err)
+	rp, found := policy.Find("GET", "/v1/finance/reports")
+	assert.True(t, found)
+	assert.True(t, policy.Allows(rp, []string{}, []string{"finance-analysts"}))
+	assert.False(t, policy.Allows(rp, []string{}, []string{"developers"}))
+}
+
+func TestParseAuthorizationPolicyWithLowercaseRoleMethods(t *testing.T) {
+	// act
+	policy, err := ParseAuthorizationPolicy([]byte(`{
+		"routes": [{"method": "GET", "path": "/v1/finance/reports"}],
+		"roles": [{"group": "finance-analysts", "methods": ["get"], "paths": ["/v1/finance/*"]}]
+	}`))
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"GET"}, policy.Roles[0].Methods)
+	rp, found := policy.Find("GET", "/v1/finance/reports")
+	assert.True(t, found)
+	assert.True(t, policy.Allows(rp, []string{}, []string{"finance-analysts"}))
+}
+
+func TestParseAuthorizationPolicyWithInvalidRoles(t *testing.T) {
+	testCases := []struct {
+		Data    string
+		Purpose string
+	}{
+		{Data: `{"roles": [{"paths": ["/v1/finance/*"]}]}`, Purpose: "missing group"},
+		{Data: `{"roles": [{"group": "finance-analysts"}]}`, Purpose: "missing paths"},
+		{Data: `{"mode": "one-of"}`, Purpose: "unknown mode"},
+		{Data: `{"routes": [{"method": "GET", "path": "/", "public": true, "mode": "x"}]}`, Purpose: "unknown route mode"},
+	}
+
+	for _, tc := range testCases {
+		// act
+		_, err := ParseAuthorizationPolicy([]byte(tc.Data))
+
+		// assert
+		assert.NotNil(t, err, tc.Purpose)
+	}
+}
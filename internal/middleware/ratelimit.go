@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/logging"
+)
+
+// Duration is a time.Duration read from JSON strings like "1m" or "24h".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return err
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(duration)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// RateLimit allows a number of requests per period, with bursts up to the
+// number of requests (token bucket).
+type RateLimit struct {
+	Requests int      `json:"requests"`
+	Period   Duration `json:"period"`
+}
+
+// Unlimited checks if the limit is not set.
+func (rl RateLimit) Unlimited() bool {
+	return rl.Requests <= 0 || rl.Period <= 0
+}
+
+// validate checks the limit is either not set, for no limit, or allows a
+// positive number of requests per a positive period.
+func (rl RateLimit) validate() error {
+	if rl.Requests == 0 && rl.Period == 0 {
+		return nil
+	}
+
+	if rl.Requests <= 0 || rl.Period <= 0 {
+		return fmt.Errorf("requests and period must be positive, got %d per %s",
+			rl.Requests, time.Duration(rl.Period))
+	}
+
+	return nil
+}
+
+// RateLimitRule sets the limit for a client and/or a route group. The most
+// specific matching rule applies: client and group, client, group.
+type RateLimitRule struct {
+	// ClientId is the client the rule applies to. If empty it applies to all.
+	ClientId string `json:"client_id"`
+
+	// Group is the route group prefix, as in "/v1/finance". If empty it
+	// applies to all routes.
+	Group string `json:"group"`
+
+	RateLimit
+}
+
+// RateLimiterConfig defines the default limit and the rules overriding it.
+type RateLimiterConfig struct {
+	Default RateLimit       `json:"default"`
+	Rules   []RateLimitRule `json:"rules"`
+}
+
+// ParseRateLimiterConfig reads a rate limiter config in JSON format.
+func ParseRateLimiterConfig(data []byte) (*RateLimiterConfig, error) {
+	config := RateLimiterConfig{}
+	err := json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limiter config: %s", err)
+	}
+
+	if err := config.Default.validate(); err != nil {
+		return nil, fmt.Errorf("invalid default rate limit: %s", err)
+	}
+
+	for i, rule := range config.Rules {
+		if rule.ClientId == "" && rule.Group == "" {
+			return nil, fmt.Errorf("invalid rate limit rule %d: client_id or group is required", i)
+		}
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("invalid rate limit rule %d: %s", i, err)
+		}
+	}
+
+	return &config, nil
+}
+
+// Find returns the limit for the client and route, along with the key of the
+// bucket. The key is per client and group of the matching rule, so the routes
+// limited by the same rule share a bucket, as all the routes do with the
+// default limit.
+func (rc *RateLimiterConfig) Find(clientId, route string) (RateLimit, string) {
+	best := -1
+	bestScore := 0
+	for i, rule := range rc.Rules {
+		if rule.ClientId != "" && rule.ClientId != clientId {
+			continue
+		}
+		if rule.Group != "" && !inGroup(route, rule.Group) {
+			continue
+		}
+
+		// client rules win over group rules, longer groups over shorter ones
+		score := len(rule.Group) + 1
+		if rule.ClientId != "" {
+			score += 1 << 16
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+
+	if best == -1 {
+		return rc.Default, clientId + "|"
+	}
+
+	rule := rc.Rules[best]
+	return rule.RateLimit, clientId + "|" + rule.Group
+}
+
+// inGroup checks if the route is the group or below it, so "/v1/finance"
+// does not match "/v1/financex".
+func inGroup(route, group string) bool {
+	group = strings.TrimSuffix(group, "/")
+	return route == group || strings.HasPrefix(route, group+"/")
+}
+
+// RateLimitResult is the state of a bucket after taking a token.
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+
+	// RetryAfter is the time until a token is available, when not allowed
+	RetryAfter time.Duration
+}
+
+// RateLimitStore keeps the token buckets. The in-memory store is enough for a
+// single instance, a shared store is needed when running several.
+type RateLimitStore interface {
+	Take(key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// RateLimiter limits the requests per client using token buckets, returning
+// HTTP 429 when the bucket is empty.
+//
+// Responses include the RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers, and Retry-After when limited. Requests without
+// client (public routes) or not matching any route are not limited.
+func RateLimiter(config *RateLimiterConfig, store RateLimitStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := logging.FromContext(c.Request.Context())
+
+		clientId := c.GetString(ClientIdKey)
+		route := c.FullPath()
+		if clientId == "" || route == "" {
+			return
+		}
+
+		limit, key := config.Find(clientId, route)
+		if limit.Unlimited() {
+			return
+		}
+
+		result, err := store.Take(key, limit)
+		if err != nil {
+			// fails open, the store being down should not stop the API
+			logger.Error().Err(err).Str("key", key).Msg("rate limit store error")
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", seconds(result.Reset))
+
+		if !result.Allowed {
+			logger.Debug().Str("key", key).Msg("rate limit exceeded")
+			c.Header("Retry-After", seconds(result.RetryAfter))
+			apierror.Abort(c, apierror.New(c, apierror.CodeRateLimited, ""))
+			return
+		}
+	}
+}
+
+// seconds formats a duration as whole seconds, rounding up.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
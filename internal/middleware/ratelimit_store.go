@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the full buckets are removed from memory
+const sweepInterval = time.Minute
+
+// bucket is a token bucket, refilled continuously.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+
+	// full is when the bucket is full again, so it can be forgotten
+	full time.Time
+}
+
+// MemoryRateLimitStore keeps the token buckets in memory.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryRateLimitStore creates an empty in-memory store.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+// Take refills the bucket for the time passed since the last request and
+// takes a token from it, if available.
+func (s *MemoryRateLimitStore) Take(key string, limit RateLimit) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	capacity := float64(limit.Requests)
+	perToken := float64(limit.Period) / float64(limit.Requests)
+
+	b, found := s.buckets[key]
+	if !found {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+
+	// refills the bucket
+	elapsed := now.Sub(b.updated)
+	b.tokens = math.Min(capacity, b.tokens+float64(elapsed)/perToken)
+	b.updated = now
+
+	result := RateLimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) * perToken)
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = time.Duration((capacity - b.tokens) * perToken)
+	b.full = now.Add(result.Reset)
+
+	return result, nil
+}
+
+// sweep removes the buckets that are full, as they are the same as new ones.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.After(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/apitesting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	// arrange - two requests per minute for finance, one for the client b
+	config := RateLimiterConfig{
+		Default: RateLimit{Requests: 100, Period: Duration(time.Minute)},
+		Rules: []RateLimitRule{
+			{Group: "/v1/finance", RateLimit: RateLimit{Requests: 2, Period: Duration(time.Minute)}},
+			{ClientId: "client-b", RateLimit: RateLimit{Requests: 1, Period: Duration(time.Minute)}},
+		},
+	}
+	r := setupRateLimitedGin(&config, NewMemoryRateLimitStore())
+
+	// act & assert - the finance bucket gets empty
+	w := performClientRequest(r, "client-a", "/v1/finance/currconv")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+
+	w = performClientRequest(r, "client-a", "/v1/finance/currconv")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+	w = performClientRequest(r, "client-a", "/v1/finance/currconv")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	apierror.AssertIsProblem(t, w, apierror.CodeRateLimited)
+
+	// act & assert - other groups and clients have their own buckets
+	w = performClientRequest(r, "client-a", "/v1/programming/uuid")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "100", w.Header().Get("RateLimit-Limit"))
+
+	w = performClientRequest(r, "client-c", "/v1/finance/currconv")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// act & assert - client rules override the group rules
+	w = performClientRequest(r, "client-b", "/v1/finance/currconv")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+
+	w = performClientRequest(r, "client-b", "/v1/programming/uuid")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestRateLimiterSkipsRequestsWithoutClient(t *testing.T) {
+	// arrange
+	config := RateLimiterConfig{
+		Default: RateLimit{Requests: 1, Period: Duration(time.Minute)},
+	}
+	r := setupRateLimitedGin(&config, NewMemoryRateLimitStore())
+
+	// act & assert
+	for i := 0; i < 3; i++ {
+		w := performClientRequest(r, "", "/v1/finance/currconv")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+}
+
+func TestRateLimiterWithStoreError(t *testing.T) {
+	// arrange
+	config := RateLimiterConfig{
+		Default: RateLimit{Requests: 1, Period: Duration(time.Minute)},
+	}
+	r := setupRateLimitedGin(&config, failingRateLimitStore{})
+
+	// act
+	w := performClientRequest(r, "client-a", "/v1/finance/currconv")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMemoryRateLimitStoreRefill(t *testing.T) {
+	// arrange
+	now := time.Now()
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	limit := RateLimit{Requests: 2, Period: Duration(time.Minute)}
+
+	// act & assert - empties the bucket
+	result, _ := store.Take("key", limit)
+	assert.True(t, result.Allowed)
+	result, _ = store.Take("key", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, time.Minute, result.Reset)
+	result, _ = store.Take("key", limit)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 30*time.Second, result.RetryAfter)
+
+	// act & assert - one token is refilled after half the period
+	now = now.Add(30 * time.Second)
+	result, _ = store.Take("key", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// act & assert - full buckets are removed
+	now = now.Add(2 * time.Minute)
+	store.Take("another-key", limit)
+	assert.Len(t, store.buckets, 1)
+}
+
+func TestMemoryRateLimitStoreWithShortPeriod(t *testing.T) {
+	// arrange - more requests than nanoseconds in the period
+	now := time.Now()
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	limit := RateLimit{Requests: 2000, Period: Duration(time.Microsecond)}
+
+	// act
+	result, err := store.Take("key", limit)
+
+	// assert
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1999, result.Remaining)
+	assert.Equal(t, time.Duration(0), result.Reset)
+}
+
+func TestParseRateLimiterConfig(t *testing.T) {
+	// act
+	config, err := ParseRateLimiterConfig([]byte(`{
+		"default": {"requests": 60, "period": "1m"},
+		"rules": [
+			{"group": "/v1/finance", "requests": 10, "period": "1h"},
+			{"client_id": "client-a", "group": "/v1/finance", "requests": 100, "period": "1h"}
+		]
+	}`))
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, RateLimit{Requests: 60, Period: Duration(time.Minute)}, config.Default)
+	assert.Equal(t, Duration(time.Hour), config.Rules[1].Period)
+
+	limit, key := config.Find("client-a", "/v1/finance/currconv")
+	assert.Equal(t, 100, limit.Requests)
+	assert.Equal(t, "client-a|/v1/finance", key)
+
+	limit, key = config.Find("client-b", "/v1/finance/currconv")
+	assert.Equal(t, 10, limit.Requests)
+	assert.Equal(t, "client-b|/v1/finance", key)
+
+	limit, key = config.Find("client-a", "/v1/programming/uuid")
+	assert.Equal(t, 60, limit.Requests)
+	assert.Equal(t, "client-a|", key)
+
+	limit, key = config.Find("client-b", "/v1/financex/currconv")
+	assert.Equal(t, 60, limit.Requests)
+	assert.Equal(t, "client-b|", key)
+
+	limit, _ = config.Find("client-b", "/v1/finance")
+	assert.Equal(t, 10, limit.Requests)
+
+	data, _ := json.Marshal(config.Default)
+	assert.JSONEq(t, `{"requests": 60, "period": "1m0s"}`, string(data))
+}
+
+func TestParseRateLimiterConfigWithInvalidData(t *testing.T) {
+	testCases := []struct {
+		Data    string
+		Purpose string
+	}{
+		{Data: "invalid json", Purpose: "invalid json"},
+		{Data: `{"default": {"requests": 1, "period": "one minute"}}`, Purpose: "invalid period"},
+		{Data: `{"rules": [{"requests": 1, "period": "1m"}]}`, Purpose: "rule without client or group"},
+		{Data: `{"default": {"requests": -1, "period": "1m"}}`, Purpose: "negative default requests"},
+		{Data: `{"default": {"requests": 10}}`, Purpose: "default without period"},
+		{Data: `{"rules": [{"group": "/v1/finance", "requests": 10, "period": "-1m"}]}`, Purpose: "negative rule period"},
+	}
+
+	for _, tc := range testCases {
+		// act
+		_, err := ParseRateLimiterConfig([]byte(tc.Data))
+
+		// assert
+		assert.NotNil(t, err, tc.Purpose)
+	}
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(key string, limit RateLimit) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("store is down")
+}
+
+func setupRateLimitedGin(config *RateLimiterConfig, store RateLimitStore) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) { // fake Authenticator
+		if clientId := c.GetHeader("X-Client-Id"); clientId != "" {
+			c.Set(ClientIdKey, clientId)
+		}
+	})
+	r.Use(RateLimiter(config, store))
+	r.GET("/v1/finance/currconv", func(c *gin.Context) {})
+	r.GET("/v1/programming/uuid", func(c *gin.Context) {})
+
+	return r
+}
+
+func performClientRequest(r http.Handler, clientId, path string) *httptest.ResponseRecorder {
+	header := http.Header{}
+	header.Set("X-Client-Id", clientId)
+	return apitesting.PerformRequestWithHeader(r, "GET", path, header)
+}
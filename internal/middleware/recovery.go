@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+)
+
+// Recovery recovers from panics in the handlers, answering with an internal
+// error problem instead of an empty HTTP 500.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		apierror.Abort(c, apierror.New(c, apierror.CodeInternal, ""))
+	})
+}
+
+// NotFound answers the requests not matching any route with a not found
+// problem.
+func NotFound(c *gin.Context) {
+	apierror.Respond(c, apierror.New(c, apierror.CodeNotFound, ""))
+}
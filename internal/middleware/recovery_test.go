@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/apitesting"
+)
+
+func TestRecovery(t *testing.T) {
+	// arrange
+	r := gin.New()
+	r.Use(Recovery())
+	r.GET("/force500", func(c *gin.Context) { panic("forced panic") })
+
+	// act
+	w := apitesting.PerformRequest(r, "GET", "/force500")
+
+	// assert
+	apierror.AssertIsProblem(t, w, apierror.CodeInternal)
+}
+
+func TestNotFound(t *testing.T) {
+	// arrange
+	r := gin.New()
+	r.NoRoute(NotFound)
+
+	// act
+	w := apitesting.PerformRequest(r, "GET", "/unknown")
+
+	// assert
+	apierror.AssertIsProblem(t, w, apierror.CodeNotFound)
+}
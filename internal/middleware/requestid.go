@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/logging"
+	"github.com/renato0307/learning-go-api/internal/requestid"
+	"github.com/rs/zerolog/log"
+)
+
+// RequestID identifies each request with the X-Request-ID header sent by the
+// client, or with a generated one if it is missing or invalid.
+//
+// The request ID is stored in the gin context, echoed back in the response
+// and added to a request-scoped logger available with logging.FromContext.
+// It must run before any middleware that logs or returns errors.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.IsValid(id) {
+			id = requestid.New()
+		}
+
+		c.Set(requestid.Key, id)
+		c.Header(requestid.Header, id)
+
+		logger := log.Logger.With().Str(requestid.Key, id).Logger()
+		ctx := logging.NewContext(c.Request.Context(), logger)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
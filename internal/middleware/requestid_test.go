@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/apitesting"
+	"github.com/renato0307/learning-go-api/internal/logging"
+	"github.com/renato0307/learning-go-api/internal/requestid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRequestIdGin() *gin.Engine {
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/example", func(c *gin.Context) {
+		logging.FromContext(c.Request.Context()).Info().Msg("handler line")
+		c.String(http.StatusOK, requestid.Get(c))
+	})
+	r.GET("/fail", func(c *gin.Context) {
+		apierror.Respond(c, apierror.New(c, apierror.CodeBadRequest, "fake error"))
+	})
+
+	return r
+}
+
+func requestIdHeader(id string) http.Header {
+	header := http.Header{}
+	header.Set(requestid.Header, id)
+
+	return header
+}
+
+func TestRequestID(t *testing.T) {
+	// arrange
+	buffer := new(bytes.Buffer)
+	defaultLogger := log.Logger
+	log.Logger = zerolog.New(buffer)
+	defer func() { log.Logger = defaultLogger }()
+
+	r := setupRequestIdGin()
+
+	// act
+	w := apitesting.PerformRequestWithHeader(
+		r,
+		"GET",
+		"/example",
+		requestIdHeader("client-request-1"))
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "client-request-1", w.Header().Get(requestid.Header))
+	assert.Equal(t, "client-request-1", w.Body.String())
+	assert.Contains(t, buffer.String(), `"request_id":"client-request-1"`)
+	assert.Contains(t, buffer.String(), "handler line")
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Header http.Header
+	}{
+		{Name: "missing", Header: http.Header{}},
+		{Name: "invalid", Header: requestIdHeader("forged\tid")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			r := setupRequestIdGin()
+
+			// act
+			w := apitesting.PerformRequestWithHeader(r, "GET", "/example", tc.Header)
+
+			// assert
+			id := w.Header().Get(requestid.Header)
+			assert.True(t, requestid.IsValid(id))
+			assert.Equal(t, id, w.Body.String())
+		})
+	}
+}
+
+func TestRequestIDInApiError(t *testing.T) {
+	// arrange
+	r := setupRequestIdGin()
+
+	// act
+	w := apitesting.PerformRequestWithHeader(
+		r,
+		"GET",
+		"/fail",
+		requestIdHeader("client-request-2"))
+
+	// assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	apiError := apierror.ApiError{}
+	err := json.Unmarshal(w.Body.Bytes(), &apiError)
+	assert.NoError(t, err)
+	assert.Equal(t, "client-request-2", apiError.RequestId)
+}
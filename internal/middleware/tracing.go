@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts the server span of the request, continuing the trace from
+// the W3C "traceparent" header if present. The span is put in the request
+// context for the following stages and handlers.
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(
+			c.Request.Context(),
+			propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		spanName := route
+		if route == "" {
+			spanName = "HTTP " + c.Request.Method
+		}
+
+		ctx, span := tracing.Tracer.Start(ctx, spanName,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethodKey.String(c.Request.Method),
+				semconv.HTTPRouteKey.String(route),
+				semconv.HTTPTargetKey.String(c.Request.URL.Path)))
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		// Process request
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(status))
+		if clientId := c.GetString(ClientIdKey); clientId != "" {
+			span.SetAttributes(attribute.String(ClientIdKey, clientId))
+		}
+	}
+}
+
+// Traced runs a middleware stage that does not call c.Next() in its own span,
+// recording if the stage aborted the request. The values the stage puts in
+// the request context, like the caller identity, are kept for the following
+// stages, with the parent span back as the current one.
+func Traced(name string, stage gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parent := trace.SpanFromContext(c.Request.Context())
+		ctx, span := tracing.Tracer.Start(c.Request.Context(), name)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		stage(c)
+		c.Request = c.Request.WithContext(trace.ContextWithSpan(c.Request.Context(), parent))
+
+		if c.IsAborted() {
+			status := c.Writer.Status()
+			span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+			span.SetStatus(codes.Error, "request aborted")
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apitesting"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	spanRecorder     *tracetest.SpanRecorder
+	spanRecorderOnce sync.Once
+)
+
+// setupSpanRecorder installs a global tracer provider recording the spans in
+// memory. The global tracer binds to the first provider set, so it is shared
+// by all the tests.
+func setupSpanRecorder() *tracetest.SpanRecorder {
+	spanRecorderOnce.Do(func() {
+		spanRecorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(
+			sdktrace.WithSpanProcessor(spanRecorder)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+
+	return spanRecorder
+}
+
+func findSpans(recorder *tracetest.SpanRecorder, traceId string) map[string]sdktrace.ReadOnlySpan {
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		if s.SpanContext().TraceID().String() == traceId {
+			spans[s.Name()] = s
+		}
+	}
+
+	return spans
+}
+
+func TestTracing(t *testing.T) {
+	// arrange
+	recorder := setupSpanRecorder()
+	traceId := "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent := "00-" + traceId + "-00f067aa0ba902b7-01"
+
+	r := gin.New()
+	r.Use(Tracing())
+	r.Use(Traced("stage", func(c *gin.Context) {}))
+	r.GET("/v1/items/:id", func(c *gin.Context) {
+		spanContext := trace.SpanContextFromContext(c.Request.Context())
+		c.String(http.StatusOK, spanContext.TraceID().String())
+	})
+
+	// act
+	w := apitesting.PerformRequestWithHeader(
+		r,
+		"GET",
+		"/v1/items/1",
+		http.Header{"Traceparent": {traceparent}})
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, traceId, w.Body.String())
+
+	spans := findSpans(recorder, traceId)
+	server, found := spans["/v1/items/:id"]
+	assert.True(t, found)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+
+	stage, found := spans["stage"]
+	assert.True(t, found)
+	assert.Equal(t, server.SpanContext().SpanID(), stage.Parent().SpanID())
+}
+
+func TestTracingWithoutTraceparent(t *testing.T) {
+	// arrange
+	setupSpanRecorder()
+
+	r := gin.New()
+	r.Use(Tracing())
+	r.GET("/example", func(c *gin.Context) {
+		spanContext := trace.SpanContextFromContext(c.Request.Context())
+		c.String(http.StatusOK, spanContext.TraceID().String())
+	})
+
+	// act
+	w := apitesting.PerformRequest(r, "GET", "/example")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	traceId, err := trace.TraceIDFromHex(w.Body.String())
+	assert.NoError(t, err)
+	assert.True(t, traceId.IsValid())
+}
+
+func TestTracedAborted(t *testing.T) {
+	// arrange
+	recorder := setupSpanRecorder()
+	traceId := "0af7651916cd43dd8448eb211c80319c"
+	traceparent := "00-" + traceId + "-b7ad6b7169203331-01"
+
+	r := gin.New()
+	r.Use(Tracing())
+	r.Use(Traced("denier", func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusForbidden)
+	}))
+	r.GET("/example", func(c *gin.Context) {})
+
+	// act
+	w := apitesting.PerformRequestWithHeader(
+		r,
+		"GET",
+		"/example",
+		http.Header{"Traceparent": {traceparent}})
+
+	// assert
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	spans := findSpans(recorder, traceId)
+	denier, found := spans["denier"]
+	assert.True(t, found)
+	assert.Equal(t, codes.Error, denier.Status().Code)
+}
+
+func TestTracedKeepsStageContext(t *testing.T) {
+	// arrange
+	recorder := setupSpanRecorder()
+	traceId := "5b8aa5a2d2c872e8321cf37308d69df2"
+	traceparent := "00-" + traceId + "-051581bf3cb55c13-01"
+
+	type key struct{}
+	r := gin.New()
+	r.Use(Tracing())
+	r.Use(Traced("stage", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), key{}, "value"))
+	}))
+	var spanId trace.SpanID
+	r.GET("/example", func(c *gin.Context) {
+		spanId = trace.SpanContextFromContext(c.Request.Context()).SpanID()
+		c.String(http.StatusOK, "%v", c.Request.Context().Value(key{}))
+	})
+
+	// act
+	w := apitesting.PerformRequestWithHeader(
+		r,
+		"GET",
+		"/example",
+		http.Header{"Traceparent": {traceparent}})
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "value", w.Body.String())
+
+	spans := findSpans(recorder, traceId)
+	assert.Equal(t, spans["/example"].SpanContext().SpanID(), spanId)
+}
@@ -0,0 +1,82 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// AssertMatchesSchema asserts the JSON data matches the schema, which must
+// be inlined, as from InlineSchemaOf.
+func AssertMatchesSchema(t *testing.T, schema *Schema, jsonData []byte) {
+	var value interface{}
+	err := json.Unmarshal(jsonData, &value)
+
+	assert.Nil(t, err)
+	assert.Empty(t, mismatches(schema, value, "$"))
+}
+
+// mismatches lists where the value does not match the schema, by their path
+// in the value, as in "$.results[0].to".
+func mismatches(schema *Schema, value interface{}, path string) []string {
+	if len(schema.OneOf) > 0 {
+		matching := 0
+		for _, option := range schema.OneOf {
+			if len(mismatches(option, value, path)) == 0 {
+				matching++
+			}
+		}
+		if matching != 1 {
+			return []string{fmt.Sprintf("%s matches %d of the oneOf schemas", path, matching)}
+		}
+	}
+
+	var found []string
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{path + " is not an object"}
+		}
+		for _, name := range schema.Required {
+			if _, present := object[name]; !present {
+				found = append(found, fmt.Sprintf("%s.%s is required", path, name))
+			}
+		}
+		for name, property := range object {
+			propertySchema := schema.Properties[name]
+			if propertySchema == nil {
+				propertySchema = schema.AdditionalProperties
+			}
+			if propertySchema != nil {
+				found = append(found, mismatches(propertySchema, property, path+"."+name)...)
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{path + " is not an array"}
+		}
+		for i, item := range items {
+			if schema.Items != nil {
+				found = append(found, mismatches(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			found = append(found, path+" is not a string")
+		}
+	case "number", "integer":
+		if _, ok := value.(float64); !ok {
+			found = append(found, path+" is not a number")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			found = append(found, path+" is not a boolean")
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,62 @@
+package openapi
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+)
+
+// docsPage renders the spec with Swagger UI
+//
+//go:embed docs.html
+var docsPage string
+
+// swaggerUI holds the Swagger UI files, served by the API so the docs UI does
+// not depend on third party servers
+//
+//go:embed swaggerui/*.css swaggerui/*.js
+var swaggerUI embed.FS
+
+var docsTemplate = template.Must(template.New("docs").Parse(docsPage))
+
+// Handler serves the document in JSON format.
+func Handler(doc *Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// DocsHandler serves a page browsing the document served at the specPath,
+// loading the Swagger UI files from the assetsPath.
+func DocsHandler(title, specPath, assetsPath string) gin.HandlerFunc {
+	page := new(bytes.Buffer)
+	docsTemplate.Execute(page, struct{ Title, SpecPath, AssetsPath string }{title, specPath, assetsPath})
+
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+	}
+}
+
+// AssetsHandler serves the Swagger UI files used by the DocsHandler page. The
+// route must end with the "*file" parameter, as in "/docs/assets/*file".
+//
+// Returns HTTP 404 if the file does not exist.
+func AssetsHandler() gin.HandlerFunc {
+	assets, _ := fs.Sub(swaggerUI, "swaggerui")
+
+	return func(c *gin.Context) {
+		name := path.Clean(c.Param("file"))
+		if _, err := fs.Stat(assets, name[1:]); err != nil || name == "/" {
+			apierror.Respond(c, apierror.New(c, apierror.CodeNotFound, ""))
+			return
+		}
+
+		c.FileFromFS(name, http.FS(assets))
+	}
+}
@@ -0,0 +1,76 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apitesting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	// arrange
+	r := gin.New()
+	r.GET("/openapi.json", Handler(newTestDocument()))
+
+	// act
+	w := apitesting.PerformRequest(r, "GET", "/openapi.json")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	doc := map[string]interface{}{}
+	err := json.Unmarshal(w.Body.Bytes(), &doc)
+	assert.NoError(t, err)
+	assert.Equal(t, Version, doc["openapi"])
+	assert.Contains(t, doc["paths"], "/v1/items/{id}")
+}
+
+func TestDocsHandler(t *testing.T) {
+	// arrange
+	r := gin.New()
+	r.GET("/docs", DocsHandler("test api", "/openapi.json", "/docs/assets"))
+
+	// act
+	w := apitesting.PerformRequest(r, "GET", "/docs")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), "<title>test api</title>")
+	assert.Contains(t, w.Body.String(), `data-spec-url="/openapi.json"`)
+	assert.Contains(t, w.Body.String(), `src="/docs/assets/swagger-ui-bundle.js"`)
+	assert.NotContains(t, w.Body.String(), "https://")
+}
+
+func TestAssetsHandler(t *testing.T) {
+	// arrange
+	r := gin.New()
+	r.GET("/docs/assets/*file", AssetsHandler())
+
+	testCases := []struct {
+		Path        string
+		Status      int
+		ContentType string
+	}{
+		{Path: "/docs/assets/swagger-ui-bundle.js", Status: http.StatusOK, ContentType: "javascript"},
+		{Path: "/docs/assets/swagger-ui.css", Status: http.StatusOK, ContentType: "text/css"},
+		{Path: "/docs/assets/docs.js", Status: http.StatusOK, ContentType: "javascript"},
+		{Path: "/docs/assets/README.md", Status: http.StatusNotFound},
+		{Path: "/docs/assets/", Status: http.StatusNotFound},
+		{Path: "/docs/assets/../handler.go", Status: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		// act
+		w := apitesting.PerformRequest(r, "GET", tc.Path)
+
+		// assert
+		assert.Equal(t, tc.Status, w.Code, tc.Path)
+		if tc.Status == http.StatusOK {
+			assert.Contains(t, w.Header().Get("Content-Type"), tc.ContentType, tc.Path)
+		}
+	}
+}
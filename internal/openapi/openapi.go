@@ -0,0 +1,450 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+)
+
+// Version of the OpenAPI specification used by the document
+const Version = "3.0.3"
+
+// Names of the security schemes in the document, the OAuth 2.0 one when the
+// token URL is known or else the plain bearer token one
+const (
+	oauth2SchemeName = "oauth2"
+	bearerSchemeName = "bearer"
+)
+
+// Route documents a route, with the path relative to its router group.
+type Route struct {
+	Method      string
+	Path        string
+	OperationId string
+	Summary     string
+	Description string
+	Tags        []string
+	Parameters  []Parameter
+	RequestBody *RequestBody
+
+	// Output is a value of the response type, described with its JSON
+	// schema, or a OneOf if the response has several shapes. Nil if the
+	// response has no JSON body.
+	Output interface{}
+
+	// ContentType of the response. Defaults to "application/json".
+	ContentType string
+
+	// Errors lists the codes of the route specific errors
+	Errors []apierror.Code
+}
+
+// Parameter describes a query or path parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// QueryParameter describes a parameter read from the query string.
+func QueryParameter(name string, schema *Schema, required bool) Parameter {
+	return Parameter{
+		Name:        name,
+		In:          "query",
+		Description: schema.Description,
+		Required:    required,
+		Schema:      schema,
+	}
+}
+
+// PathParameter describes a parameter read from a segment of the path, as in
+// "base" for "/rates/:base". Path parameters are always required.
+func PathParameter(name string, schema *Schema) Parameter {
+	return Parameter{
+		Name:        name,
+		In:          "path",
+		Description: schema.Description,
+		Required:    true,
+		Schema:      schema,
+	}
+}
+
+// RequestBody describes the body of a request
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// TextBody describes a required plain text body.
+func TextBody(description string) *RequestBody {
+	return &RequestBody{
+		Description: description,
+		Required:    true,
+		Content: map[string]MediaType{
+			"text/plain": {Schema: &Schema{Type: "string"}},
+		},
+	}
+}
+
+// JSONBody describes a required JSON body, with the schema of the value.
+func JSONBody(description string, v interface{}) *RequestBody {
+	return &RequestBody{
+		Description: description,
+		Required:    true,
+		Content: map[string]MediaType{
+			"application/json": {Schema: InlineSchemaOf(v)},
+		},
+	}
+}
+
+// MediaType describes the content of a body
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Response describes a response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Operation describes a route in the document
+type Operation struct {
+	OperationId string                 `json:"operationId"`
+	Summary     string                 `json:"summary,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Parameters  []Parameter            `json:"parameters,omitempty"`
+	RequestBody *RequestBody           `json:"requestBody,omitempty"`
+	Responses   map[string]*Response   `json:"responses"`
+	Security    *[]map[string][]string `json:"security,omitempty"`
+
+	// the gin route, used to find its authorization policy
+	method  string
+	ginPath string
+	errors  []apierror.Code
+}
+
+// Tag describes a group of operations
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Components holds the schemas and security schemes referenced in the
+// document
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how clients authenticate
+type SecurityScheme struct {
+	Type         string      `json:"type"`
+	Description  string      `json:"description,omitempty"`
+	Scheme       string      `json:"scheme,omitempty"`
+	BearerFormat string      `json:"bearerFormat,omitempty"`
+	Flows        *OAuthFlows `json:"flows,omitempty"`
+}
+
+// OAuthFlows lists the OAuth 2.0 flows supported
+type OAuthFlows struct {
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+}
+
+// OAuthFlow describes an OAuth 2.0 flow and its scopes
+type OAuthFlow struct {
+	TokenUrl string            `json:"tokenUrl"`
+	Scopes   map[string]string `json:"scopes"`
+}
+
+// Document is an OpenAPI 3 document, built from the routes registered by the
+// function categories.
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Tags       []Tag                            `json:"tags,omitempty"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+// NewDocument creates an empty document for the API.
+func NewDocument(info Info) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]map[string]*Operation{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+		},
+	}
+
+	// every error response shares the problem details schema
+	schemaOf(reflect.TypeOf(apierror.ApiError{}), doc.Components.Schemas)
+
+	return doc
+}
+
+// AddTag describes a group of operations, like a function category.
+func (d *Document) AddTag(name, description string) {
+	d.Tags = append(d.Tags, Tag{Name: name, Description: description})
+}
+
+// Register adds the routes to the document, joining their paths with the
+// base path of the router group.
+func (d *Document) Register(basePath string, routes ...Route) {
+	for _, route := range routes {
+		method := strings.ToUpper(route.Method)
+		ginPath := joinPaths(basePath, route.Path)
+
+		operation := &Operation{
+			OperationId: route.OperationId,
+			Summary:     route.Summary,
+			Description: route.Description,
+			Tags:        route.Tags,
+			Parameters:  route.Parameters,
+			RequestBody: route.RequestBody,
+			Responses:   map[string]*Response{},
+			method:      method,
+			ginPath:     ginPath,
+		}
+		if operation.OperationId == "" {
+			operation.OperationId = operationId(method, ginPath)
+		}
+
+		contentType := route.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		success := &Response{Description: "Successful response"}
+		if route.Output != nil {
+			success.Content = map[string]MediaType{
+				contentType: {
+					Schema: schemaOfValue(route.Output, d.Components.Schemas),
+				},
+			}
+		} else if route.ContentType != "" {
+			success.Content = map[string]MediaType{contentType: {}}
+		}
+		operation.Responses[strconv.Itoa(http.StatusOK)] = success
+
+		d.addErrors(operation, route.Errors...)
+		d.addErrors(operation, apierror.CodeInternal)
+
+		specPath := specPath(ginPath)
+		if d.Paths[specPath] == nil {
+			d.Paths[specPath] = map[string]*Operation{}
+		}
+		d.Paths[specPath][strings.ToLower(method)] = operation
+	}
+}
+
+// ApplyPolicy documents the scopes required by each operation, and the
+// authentication and authorization errors of the protected ones.
+//
+// If the tokenUrl is set, clients get their tokens from it with the client
+// credentials flow. Otherwise the tokens are documented as plain bearer
+// tokens, with the scopes in the operation descriptions.
+func (d *Document) ApplyPolicy(policy *middleware.AuthorizationPolicy, tokenUrl string) {
+	description := "JWT access tokens sent as \"Authorization: Bearer <token>\"."
+	schemeName := bearerSchemeName
+	scheme := &SecurityScheme{Type: "http", Description: description, Scheme: "bearer", BearerFormat: "JWT"}
+
+	var flow *OAuthFlow
+	if tokenUrl != "" {
+		flow = &OAuthFlow{TokenUrl: tokenUrl, Scopes: map[string]string{}}
+		schemeName = oauth2SchemeName
+		scheme = &SecurityScheme{Type: "oauth2", Description: description, Flows: &OAuthFlows{ClientCredentials: flow}}
+	}
+	d.Components.SecuritySchemes = map[string]*SecurityScheme{schemeName: scheme}
+
+	for _, operation := range d.operations() {
+		routePolicy, found := policy.Find(operation.method, operation.ginPath)
+		if !found {
+			continue
+		}
+
+		if routePolicy.Public {
+			operation.Security = &[]map[string][]string{}
+			continue
+		}
+
+		scopes := []string{}
+		for _, scope := range routePolicy.Scopes {
+			fullScope := policy.ScopePrefix + scope
+			scopes = append(scopes, fullScope)
+			if flow != nil {
+				flow.Scopes[fullScope] = fmt.Sprintf("Access to %s %s",
+					operation.method, operation.ginPath)
+			}
+		}
+
+		// only OAuth 2.0 schemes list the scopes in the requirements
+		if flow == nil && len(scopes) > 0 {
+			operation.Description = strings.TrimSpace(fmt.Sprintf("%s\n\nRequires any of the scopes: %s.",
+				operation.Description, strings.Join(scopes, ", ")))
+			scopes = []string{}
+		}
+		operation.Security = &[]map[string][]string{{schemeName: scopes}}
+
+		d.addErrors(operation,
+			apierror.CodeUnauthorized,
+			apierror.CodeForbidden,
+			apierror.CodeRateLimited,
+			apierror.CodeQuotaExceeded)
+	}
+}
+
+// Find returns the operation documenting a gin route.
+func (d *Document) Find(method, ginPath string) (*Operation, bool) {
+	methods, found := d.Paths[specPath(ginPath)]
+	if !found {
+		return nil, false
+	}
+
+	operation, found := methods[strings.ToLower(method)]
+	return operation, found
+}
+
+// CheckRoutes makes sure every gin route is documented, so the document
+// stays in sync with the router.
+func (d *Document) CheckRoutes(routes gin.RoutesInfo) error {
+	missing := []string{}
+	for _, route := range routes {
+		if _, found := d.Find(route.Method, route.Path); !found {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("routes missing from the OpenAPI document: %s",
+			strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// operations returns all the operations, sorted by path and method.
+func (d *Document) operations() []*Operation {
+	operations := []*Operation{}
+	for _, methods := range d.Paths {
+		for _, operation := range methods {
+			operations = append(operations, operation)
+		}
+	}
+
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].ginPath != operations[j].ginPath {
+			return operations[i].ginPath < operations[j].ginPath
+		}
+		return operations[i].method < operations[j].method
+	})
+
+	return operations
+}
+
+// addErrors documents the error responses of the codes, grouping the codes
+// sharing the same HTTP status.
+func (d *Document) addErrors(operation *Operation, codes ...apierror.Code) {
+	for _, code := range codes {
+		if containsCode(operation.errors, code) {
+			continue
+		}
+		operation.errors = append(operation.errors, code)
+
+		status := strconv.Itoa(code.Status())
+		response, found := operation.Responses[status]
+		if !found {
+			response = &Response{
+				Content: map[string]MediaType{
+					apierror.ContentType: {
+						Schema: schemaOf(reflect.TypeOf(apierror.ApiError{}), d.Components.Schemas),
+					},
+				},
+			}
+			operation.Responses[status] = response
+		}
+
+		description := fmt.Sprintf("%s (code \"%s\")", code.Title(), code)
+		if response.Description == "" {
+			response.Description = description
+		} else {
+			response.Description += ", or " + description
+		}
+	}
+}
+
+func containsCode(codes []apierror.Code, code apierror.Code) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// specPath converts a gin path into an OpenAPI path, as in "/items/{id}" for
+// "/items/:id".
+func specPath(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// operationId names an operation after its method and path, as in
+// "getV1FinanceCurrconv" for "GET /v1/finance/currconv".
+func operationId(method, ginPath string) string {
+	id := strings.ToLower(method)
+	segments := strings.FieldsFunc(ginPath, isSeparator)
+	if len(segments) == 0 {
+		return id + "Root"
+	}
+
+	for _, segment := range segments {
+		id += strings.ToUpper(segment[:1]) + strings.ToLower(segment[1:])
+	}
+
+	return id
+}
+
+func isSeparator(r rune) bool {
+	return r == '/' || r == '-' || r == '_' || r == '.' || r == ':' || r == '*'
+}
+
+// joinPaths joins the paths like gin does for router groups.
+func joinPaths(basePath, relativePath string) string {
+	if basePath == "" {
+		return relativePath
+	}
+
+	joined := path.Join(basePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(joined, "/") {
+		return joined + "/"
+	}
+
+	return joined
+}
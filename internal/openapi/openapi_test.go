@@ -0,0 +1,190 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+type itemOutput struct {
+	Id string `json:"id"`
+}
+
+var testRoutes = []Route{
+	{
+		Method:  "get",
+		Path:    "/items/:id",
+		Summary: "Gets an item",
+		Parameters: []Parameter{
+			QueryParameter("expand", Boolean("Expands the item"), false),
+		},
+		Output: itemOutput{},
+		Errors: []apierror.Code{apierror.CodeInvalidParameters},
+	},
+	{
+		Method:      "POST",
+		Path:        "/items",
+		RequestBody: TextBody("The item"),
+		ContentType: "text/plain",
+	},
+}
+
+func newTestDocument() *Document {
+	doc := NewDocument(Info{Title: "test", Version: "1"})
+	doc.Register("/v1", testRoutes...)
+
+	return doc
+}
+
+func TestRegister(t *testing.T) {
+	// act
+	doc := newTestDocument()
+
+	// assert
+	get, found := doc.Find("GET", "/v1/items/:id")
+	assert.True(t, found)
+	assert.Contains(t, doc.Paths, "/v1/items/{id}")
+	assert.Equal(t, "getV1ItemsId", get.OperationId)
+	assert.Equal(t, "#/components/schemas/ItemOutput",
+		get.Responses["200"].Content["application/json"].Schema.Ref)
+	assert.Contains(t, doc.Components.Schemas, "ItemOutput")
+	assert.Contains(t, doc.Components.Schemas, "ApiError")
+	assert.Contains(t, get.Responses, "400")
+	assert.Contains(t, get.Responses, "500")
+	assert.Equal(t,
+		"#/components/schemas/ApiError",
+		get.Responses["400"].Content[apierror.ContentType].Schema.Ref)
+
+	post, found := doc.Find("POST", "/v1/items")
+	assert.True(t, found)
+	assert.Equal(t, "postV1Items", post.OperationId)
+	assert.Contains(t, post.Responses["200"].Content, "text/plain")
+	assert.Contains(t, post.RequestBody.Content, "text/plain")
+}
+
+func TestApplyPolicy(t *testing.T) {
+	// arrange
+	doc := newTestDocument()
+	policy := middleware.NewAuthorizationPolicy("https://example.com/")
+	policy.Register("/v1",
+		middleware.RoutePolicy{Method: "GET", Path: "/items/:id", Scopes: []string{"items-read"}},
+		middleware.RoutePolicy{Method: "POST", Path: "/items", Public: true})
+
+	// act
+	doc.ApplyPolicy(policy, "https://auth.example.com/oauth2/token")
+
+	// assert
+	get, _ := doc.Find("GET", "/v1/items/:id")
+	assert.Equal(t,
+		[]map[string][]string{{"oauth2": {"https://example.com/items-read"}}},
+		*get.Security)
+	assert.Contains(t, get.Responses, "401")
+	assert.Contains(t, get.Responses, "403")
+	assert.Contains(t, get.Responses["429"].Description, string(apierror.CodeRateLimited))
+	assert.Contains(t, get.Responses["429"].Description, string(apierror.CodeQuotaExceeded))
+
+	post, _ := doc.Find("POST", "/v1/items")
+	assert.Empty(t, *post.Security)
+	assert.NotContains(t, post.Responses, "401")
+
+	flow := doc.Components.SecuritySchemes["oauth2"].Flows.ClientCredentials
+	assert.Equal(t, "https://auth.example.com/oauth2/token", flow.TokenUrl)
+	assert.Contains(t, flow.Scopes, "https://example.com/items-read")
+}
+
+func TestApplyPolicyWithoutTokenUrl(t *testing.T) {
+	// arrange
+	doc := newTestDocument()
+	policy := middleware.NewAuthorizationPolicy("https://example.com/")
+	policy.Register("/v1",
+		middleware.RoutePolicy{Method: "GET", Path: "/items/:id", Scopes: []string{"items-read"}},
+		middleware.RoutePolicy{Method: "POST", Path: "/items", Public: true})
+
+	// act
+	doc.ApplyPolicy(policy, "")
+
+	// assert
+	assert.NotContains(t, doc.Components.SecuritySchemes, "oauth2")
+	scheme := doc.Components.SecuritySchemes["bearer"]
+	assert.Equal(t, "http", scheme.Type)
+	assert.Equal(t, "bearer", scheme.Scheme)
+	assert.Nil(t, scheme.Flows)
+
+	get, _ := doc.Find("GET", "/v1/items/:id")
+	assert.Equal(t, []map[string][]string{{"bearer": {}}}, *get.Security)
+	assert.Contains(t, get.Description, "https://example.com/items-read")
+	assert.Contains(t, get.Responses, "401")
+}
+
+func TestCheckRoutes(t *testing.T) {
+	// arrange
+	doc := newTestDocument()
+	r := gin.New()
+	r.GET("/v1/items/:id", func(c *gin.Context) {})
+	r.POST("/v1/items", func(c *gin.Context) {})
+
+	// act
+	err := doc.CheckRoutes(r.Routes())
+
+	// assert
+	assert.NoError(t, err)
+}
+
+func TestCheckRoutesWithMissingRoute(t *testing.T) {
+	// arrange
+	doc := newTestDocument()
+	r := gin.New()
+	r.GET("/v1/items/:id", func(c *gin.Context) {})
+	r.DELETE("/v1/items/:id", func(c *gin.Context) {})
+
+	// act
+	err := doc.CheckRoutes(r.Routes())
+
+	// assert
+	assert.EqualError(t, err,
+		"routes missing from the OpenAPI document: DELETE /v1/items/:id")
+}
+
+func TestOperationId(t *testing.T) {
+	testCases := []struct {
+		Method string
+		Path   string
+		Id     string
+	}{
+		{Method: "GET", Path: "/", Id: "getRoot"},
+		{Method: "GET", Path: "/openapi.json", Id: "getOpenapiJson"},
+		{Method: "POST", Path: "/v1/programming/uuid", Id: "postV1ProgrammingUuid"},
+		{Method: "GET", Path: "/v1/me/usage", Id: "getV1MeUsage"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Id, func(t *testing.T) {
+			assert.Equal(t, tc.Id, operationId(tc.Method, tc.Path))
+		})
+	}
+}
+
+func TestJSONBody(t *testing.T) {
+	// act
+	body := JSONBody("The item", itemOutput{})
+
+	// assert
+	assert.True(t, body.Required)
+	assert.Equal(t, "The item", body.Description)
+	schema := body.Content["application/json"].Schema
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, "string", schema.Properties["id"].Type)
+}
+
+func TestPathParameter(t *testing.T) {
+	// act
+	parameter := PathParameter("id", String("The item id"))
+
+	// assert
+	assert.Equal(t, "path", parameter.In)
+	assert.Equal(t, "The item id", parameter.Description)
+	assert.True(t, parameter.Required)
+}
@@ -0,0 +1,220 @@
+package openapi
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Schema describes a JSON value, as in the OpenAPI schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+// OneOf is the Output of a route answering with the type of any of the
+// values, as in OneOf{singleOutput{}, listOutput{}}
+type OneOf []interface{}
+
+// schemaRefPrefix locates the named schemas in the document
+const schemaRefPrefix = "#/components/schemas/"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// String describes a string value.
+func String(description string) *Schema {
+	return &Schema{Type: "string", Description: description}
+}
+
+// Number describes a number value.
+func Number(description string) *Schema {
+	return &Schema{Type: "number", Description: description}
+}
+
+// Boolean describes a boolean value.
+func Boolean(description string) *Schema {
+	return &Schema{Type: "boolean", Description: description}
+}
+
+// schemaOfValue describes the JSON encoding of the value, or of any of the
+// values of a OneOf.
+func schemaOfValue(v interface{}, schemas map[string]*Schema) *Schema {
+	values, ok := v.(OneOf)
+	if !ok {
+		return schemaOf(reflect.TypeOf(v), schemas)
+	}
+
+	schema := &Schema{}
+	for _, value := range values {
+		schema.OneOf = append(schema.OneOf, schemaOf(reflect.TypeOf(value), schemas))
+	}
+
+	return schema
+}
+
+// schemaOf describes the JSON encoding of the type. Named structs are added
+// to the schemas and referenced, so they are described only once. Types
+// encoded as text, like decimal numbers, are strings.
+func schemaOf(t reflect.Type, schemas map[string]*Schema) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if t.Implements(textMarshalerType) && t.Kind() != reflect.String {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: schemaOf(t.Elem(), schemas),
+		}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+
+		name := schemaName(t)
+		if _, found := schemas[name]; !found {
+			// reserves the name first, so recursive types terminate
+			schemas[name] = &Schema{}
+			*schemas[name] = *structSchema(t, schemas)
+		}
+		return &Schema{Ref: schemaRefPrefix + name}
+	}
+
+	// interfaces and other kinds accept any value
+	return &Schema{}
+}
+
+// structSchema describes the exported fields of a struct, following their
+// json tags. Fields without "omitempty" are required.
+func structSchema(t reflect.Type, schemas map[string]*Schema) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, options := field.Name, ""
+		if tag, found := field.Tag.Lookup("json"); found {
+			if tag == "-" {
+				continue
+			}
+			parts := strings.SplitN(tag, ",", 2)
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			if len(parts) > 1 {
+				options = parts[1]
+			}
+		}
+
+		fieldSchema := schemaOf(field.Type, schemas)
+		if strings.Contains(options, "string") {
+			fieldSchema = &Schema{Type: "string"}
+		}
+		schema.Properties[name] = fieldSchema
+
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+// schemaName names the schema of a type after it, in upper camel case, as in
+// "GetCurrConvOutput" for "getCurrConvOutput".
+func schemaName(t reflect.Type) string {
+	name := []rune(t.Name())
+	name[0] = unicode.ToUpper(name[0])
+
+	return string(name)
+}
+
+// InlineSchemaOf describes the JSON encoding of the value with the named
+// schemas expanded in place, for consumers without the document components.
+// A OneOf of objects is typed as an object itself, as MCP requires for the
+// tool output.
+func InlineSchemaOf(v interface{}) *Schema {
+	schemas := map[string]*Schema{}
+	schema := inline(schemaOfValue(v, schemas), schemas, map[string]bool{})
+
+	if len(schema.OneOf) > 0 && schema.Type == "" {
+		schema.Type = "object"
+		for _, option := range schema.OneOf {
+			if option.Type != "object" {
+				schema.Type = ""
+			}
+		}
+	}
+
+	return schema
+}
+
+// inline expands the references of the schema. Recursive references are
+// left as schemas accepting any value.
+func inline(schema *Schema, schemas map[string]*Schema, expanding map[string]bool) *Schema {
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, schemaRefPrefix)
+		if expanding[name] {
+			return &Schema{}
+		}
+
+		expanding[name] = true
+		defer delete(expanding, name)
+
+		return inline(schemas[name], schemas, expanding)
+	}
+
+	inlined := *schema
+	if schema.Properties != nil {
+		inlined.Properties = map[string]*Schema{}
+		for name, property := range schema.Properties {
+			inlined.Properties[name] = inline(property, schemas, expanding)
+		}
+	}
+	inlined.Items = inline(schema.Items, schemas, expanding)
+	inlined.AdditionalProperties = inline(schema.AdditionalProperties, schemas, expanding)
+	if schema.OneOf != nil {
+		inlined.OneOf = []*Schema{}
+		for _, option := range schema.OneOf {
+			inlined.OneOf = append(inlined.OneOf, inline(option, schemas, expanding))
+		}
+	}
+
+	return &inlined
+}
@@ -0,0 +1,140 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sampleText is encoded as text, as in "3/4"
+type sampleText struct {
+	numerator, denominator int
+}
+
+func (s sampleText) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d/%d", s.numerator, s.denominator)), nil
+}
+
+type sampleItem struct {
+	Name string `json:"name"`
+}
+
+type sampleOutput struct {
+	Id        int               `json:"id"`
+	Price     float64           `json:"price,omitempty"`
+	Active    bool              `json:"active"`
+	Quantity  int64             `json:"quantity,string"`
+	Items     []sampleItem      `json:"items"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	Ratio     sampleText        `json:"ratio,omitempty"`
+	Next      *sampleOutput     `json:"next,omitempty"`
+	Ignored   string            `json:"-"`
+	NoTag     string
+	private   string
+}
+
+func TestSchemaOf(t *testing.T) {
+	// arrange
+	schemas := map[string]*Schema{}
+
+	// act
+	schema := schemaOf(reflect.TypeOf(sampleOutput{}), schemas)
+
+	// assert
+	assert.Equal(t, "#/components/schemas/SampleOutput", schema.Ref)
+	assert.Contains(t, schemas, "SampleItem")
+
+	output := schemas["SampleOutput"]
+	assert.Equal(t, "object", output.Type)
+	assert.Equal(t, "integer", output.Properties["id"].Type)
+	assert.Equal(t, "number", output.Properties["price"].Type)
+	assert.Equal(t, "boolean", output.Properties["active"].Type)
+	assert.Equal(t, "string", output.Properties["quantity"].Type)
+	assert.Equal(t, "array", output.Properties["items"].Type)
+	assert.Equal(t, "#/components/schemas/SampleItem", output.Properties["items"].Items.Ref)
+	assert.Equal(t, "string", output.Properties["labels"].AdditionalProperties.Type)
+	assert.Equal(t, "date-time", output.Properties["created_at"].Format)
+	assert.Equal(t, &Schema{Type: "string"}, output.Properties["ratio"])
+	assert.NotContains(t, schemas, "SampleText")
+	assert.Equal(t, "#/components/schemas/SampleOutput", output.Properties["next"].Ref)
+	assert.Contains(t, output.Properties, "NoTag")
+	assert.NotContains(t, output.Properties, "Ignored")
+	assert.NotContains(t, output.Properties, "private")
+	assert.ElementsMatch(t,
+		[]string{"id", "active", "quantity", "items", "created_at", "NoTag"},
+		output.Required)
+}
+
+func TestSchemaOfAnonymousStruct(t *testing.T) {
+	// arrange
+	schemas := map[string]*Schema{}
+	value := struct {
+		Message string `json:"message"`
+	}{}
+
+	// act
+	schema := schemaOf(reflect.TypeOf(value), schemas)
+
+	// assert
+	assert.Empty(t, schemas)
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, "string", schema.Properties["message"].Type)
+}
+
+func TestInlineSchemaOf(t *testing.T) {
+	// act
+	schema := InlineSchemaOf(sampleOutput{})
+
+	// assert
+	assert.Empty(t, schema.Ref)
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, "object", schema.Properties["items"].Items.Type)
+	assert.Equal(t, "string", schema.Properties["items"].Items.Properties["name"].Type)
+	assert.Equal(t, &Schema{}, schema.Properties["next"])
+}
+
+func TestInlineSchemaOfOneOf(t *testing.T) {
+	// act
+	schema := InlineSchemaOf(OneOf{sampleItem{}, sampleOutput{}})
+	mixed := InlineSchemaOf(OneOf{sampleItem{}, ""})
+
+	// assert
+	assert.Equal(t, "object", schema.Type)
+	assert.Len(t, schema.OneOf, 2)
+	assert.Equal(t, "string", schema.OneOf[0].Properties["name"].Type)
+	assert.Equal(t, "integer", schema.OneOf[1].Properties["id"].Type)
+	assert.Empty(t, mixed.Type)
+}
+
+func TestAssertMatchesSchema(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		Data       string
+		Mismatches []string
+	}{
+		{Name: "first", Data: `{"name":"a"}`},
+		{Name: "second", Data: `{"id":1,"active":true,"quantity":"2","items":[{"name":"a"}],"created_at":"2022-02-04T00:00:00Z","NoTag":""}`},
+		{Name: "none", Data: `{"id":"1"}`, Mismatches: []string{"$ matches 0 of the oneOf schemas"}},
+		{Name: "wrong item", Data: `{"items":[{"name":1}]}`, Mismatches: []string{"$ matches 0 of the oneOf schemas"}},
+	}
+
+	schema := InlineSchemaOf(OneOf{sampleItem{}, sampleOutput{}})
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			var value interface{}
+			assert.NoError(t, json.Unmarshal([]byte(tc.Data), &value))
+
+			// act
+			found := mismatches(schema, value, "$")
+
+			// assert
+			assert.Equal(t, tc.Mismatches, found)
+		})
+	}
+}
@@ -0,0 +1,7 @@
+window.onload = function () {
+  var element = document.getElementById("swagger-ui");
+  window.ui = SwaggerUIBundle({
+    url: element.dataset.specUrl,
+    dom_id: "#swagger-ui",
+  });
+};
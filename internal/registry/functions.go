@@ -0,0 +1,127 @@
+package registry
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/openapi"
+)
+
+// functionsOutput is the output of the "GET /functions" action
+type functionsOutput struct {
+	Categories []categoryOutput `json:"categories"`
+}
+
+// categoryOutput describes an enabled category and its functions
+type categoryOutput struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Functions   []functionOutput `json:"functions"`
+}
+
+// functionOutput describes a function and if the client can call it
+type functionOutput struct {
+	Name        string               `json:"name"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Method      string               `json:"method"`
+	Path        string               `json:"path"`
+	Parameters  []openapi.Parameter  `json:"parameters"`
+	RequestBody *openapi.RequestBody `json:"request_body,omitempty"`
+	Output      *openapi.Schema      `json:"output,omitempty"`
+	Scopes      []string             `json:"scopes"`
+	Public      bool                 `json:"public"`
+	Granted     bool                 `json:"granted"`
+}
+
+// RoutePolicies defines the scopes required by the discovery route, with
+// paths relative to the base router group. Every client can list the
+// functions.
+var RoutePolicies = []middleware.RoutePolicy{
+	{Method: "GET", Path: "/functions", Public: true},
+}
+
+// RouteDocs documents the discovery route in the OpenAPI document, with paths
+// relative to the base router group
+var RouteDocs = []openapi.Route{
+	{
+		Method:      "GET",
+		Path:        "/functions",
+		Summary:     "Lists the functions of the enabled categories",
+		Description: "Includes the inputs, output and scopes of each function, and if the calling client is granted access to it.",
+		Tags:        []string{"discovery"},
+		Output:      functionsOutput{},
+	},
+}
+
+// SetRouterGroup defines the discovery route for the mounted categories
+func SetRouterGroup(
+	mounted []Mounted,
+	policy *middleware.AuthorizationPolicy,
+	base *gin.RouterGroup) *gin.RouterGroup {
+
+	base.GET("/functions", getFunctions(mounted, policy))
+
+	return base
+}
+
+// getFunctions handles the request listing the functions.
+//
+// The access of the client is checked with the scopes and groups added by
+// the Authenticator, against the policy in use, including the policy file.
+//
+// It returns HTTP 200 on success.
+func getFunctions(mounted []Mounted, policy *middleware.AuthorizationPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientScopes := strings.Fields(c.GetString(middleware.ScopeKey))
+		groups := c.GetStringSlice(middleware.GroupsKey)
+
+		output := functionsOutput{Categories: []categoryOutput{}}
+		for _, m := range mounted {
+			category := categoryOutput{
+				Name:        m.Name,
+				Description: m.Description,
+				Functions:   []functionOutput{},
+			}
+
+			for _, route := range m.Docs {
+				method := strings.ToUpper(route.Method)
+				fullPath := path.Join(m.Group.BasePath(), route.Path)
+
+				function := functionOutput{
+					Name:        strings.Trim(route.Path, "/"),
+					Summary:     route.Summary,
+					Description: route.Description,
+					Method:      method,
+					Path:        fullPath,
+					Parameters:  route.Parameters,
+					RequestBody: route.RequestBody,
+					Scopes:      []string{},
+				}
+				if function.Parameters == nil {
+					function.Parameters = []openapi.Parameter{}
+				}
+				if route.Output != nil {
+					function.Output = openapi.InlineSchemaOf(route.Output)
+				}
+
+				if routePolicy, found := policy.Find(method, fullPath); found {
+					for _, scope := range routePolicy.Scopes {
+						function.Scopes = append(function.Scopes, policy.ScopePrefix+scope)
+					}
+					function.Public = routePolicy.Public
+					function.Granted = policy.Allows(routePolicy, clientScopes, groups)
+				}
+
+				category.Functions = append(category.Functions, function)
+			}
+
+			output.Categories = append(output.Categories, category)
+		}
+
+		c.JSON(http.StatusOK, output)
+	}
+}
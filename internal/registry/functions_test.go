@@ -0,0 +1,118 @@
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apitesting"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+type pingOutput struct {
+	Pong string `json:"pong"`
+}
+
+func setupFunctionsGin(t *testing.T) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) { // fake Authenticator
+		c.Set(middleware.ScopeKey, c.GetHeader("X-Scope"))
+	})
+	base := r.Group("/v1")
+
+	category := newTestCategory("network")
+	category.Description = "Network functions"
+	category.Policies = []middleware.RoutePolicy{
+		{Method: "GET", Path: "/ping", Scopes: []string{"network-ping"}},
+	}
+	category.Docs = []openapi.Route{
+		{
+			Method:  "GET",
+			Path:    "/ping",
+			Summary: "Pings a host",
+			Parameters: []openapi.Parameter{
+				openapi.QueryParameter("host", openapi.String("Host to ping"), true),
+			},
+			Output: pingOutput{},
+		},
+	}
+
+	mounted, err := Mount([]Category{category}, base, nil, func(string) (string, bool) {
+		return "", false
+	})
+	assert.NoError(t, err)
+
+	policy := middleware.NewAuthorizationPolicy("https://example.com/")
+	for _, m := range mounted {
+		policy.Register(m.Group.BasePath(), m.Policies...)
+	}
+	SetRouterGroup(mounted, policy, base)
+
+	return r
+}
+
+func getFunctionsFor(t *testing.T, r *gin.Engine, scope string) functionsOutput {
+	header := http.Header{}
+	header.Set("X-Scope", scope)
+	w := apitesting.PerformRequestWithHeader(r, "GET", "/v1/functions", header)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	output := functionsOutput{}
+	err := json.Unmarshal(w.Body.Bytes(), &output)
+	assert.NoError(t, err)
+
+	return output
+}
+
+func TestGetFunctions(t *testing.T) {
+	// arrange
+	r := setupFunctionsGin(t)
+
+	// act
+	output := getFunctionsFor(t, r, "https://example.com/network-ping")
+
+	// assert
+	assert.Len(t, output.Categories, 1)
+	category := output.Categories[0]
+	assert.Equal(t, "network", category.Name)
+	assert.Equal(t, "Network functions", category.Description)
+
+	assert.Len(t, category.Functions, 1)
+	function := category.Functions[0]
+	assert.Equal(t, "ping", function.Name)
+	assert.Equal(t, "GET", function.Method)
+	assert.Equal(t, "/v1/network/ping", function.Path)
+	assert.Equal(t, "host", function.Parameters[0].Name)
+	assert.Equal(t, "string", function.Parameters[0].Schema.Type)
+	assert.Equal(t, "string", function.Output.Properties["pong"].Type)
+	assert.Equal(t, []string{"https://example.com/network-ping"}, function.Scopes)
+	assert.False(t, function.Public)
+	assert.True(t, function.Granted)
+}
+
+func TestGetFunctionsNotGranted(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Scope string
+	}{
+		{Name: "no scope", Scope: ""},
+		{Name: "other scope", Scope: "https://example.com/finance-currconv"},
+		{Name: "other prefix", Scope: "https://other.com/network-ping"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			r := setupFunctionsGin(t)
+
+			// act
+			output := getFunctionsFor(t, r, tc.Scope)
+
+			// assert
+			assert.False(t, output.Categories[0].Functions[0].Granted)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/logging"
+)
+
+// healthCheckTimeout limits how long the health checks can take
+const healthCheckTimeout = 5 * time.Second
+
+// Health statuses
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+)
+
+// ErrDegraded is the error of the health checks of a category that still
+// serves requests, as when an upstream it depends on fails
+var ErrDegraded = errors.New("degraded")
+
+// Degraded marks the error of a health check as degrading the category
+// instead of making it unavailable.
+func Degraded(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrDegraded, err)
+}
+
+// HealthOutput is the output of the health route
+type HealthOutput struct {
+	Status     string                    `json:"status"`
+	Categories map[string]CategoryHealth `json:"categories"`
+}
+
+// CategoryHealth is the health of a category
+type CategoryHealth struct {
+	Status string `json:"status"`
+}
+
+// HealthHandler runs the health checks of the categories, to be used as the
+// readiness probe. The errors of the checks are logged, not returned, as the
+// route is public.
+//
+// It returns HTTP 200 if all the categories are healthy or degraded.
+// Returns HTTP 503 if any category is unavailable.
+func HealthHandler(mounted []Mounted) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+		defer cancel()
+
+		output := HealthOutput{
+			Status:     StatusOK,
+			Categories: map[string]CategoryHealth{},
+		}
+		for _, m := range mounted {
+			health := CategoryHealth{Status: StatusOK}
+			if m.HealthCheck != nil {
+				err := m.HealthCheck(ctx)
+				if errors.Is(err, ErrDegraded) {
+					logging.FromContext(ctx).Warn().
+						Err(err).
+						Str("category", m.Name).
+						Msg("category degraded")
+					health = CategoryHealth{Status: StatusDegraded}
+					if output.Status == StatusOK {
+						output.Status = StatusDegraded
+					}
+				} else if err != nil {
+					logging.FromContext(ctx).Error().
+						Err(err).
+						Str("category", m.Name).
+						Msg("category health check failed")
+					health = CategoryHealth{Status: StatusUnavailable}
+					output.Status = StatusUnavailable
+				}
+			}
+			output.Categories[m.Name] = health
+		}
+
+		status := http.StatusOK
+		if output.Status == StatusUnavailable {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, output)
+	}
+}
@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apitesting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandler(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		FinanceErr    error
+		StatusCode    int
+		Status        string
+		FinanceStatus string
+	}{
+		{
+			Name:          "healthy",
+			StatusCode:    http.StatusOK,
+			Status:        StatusOK,
+			FinanceStatus: StatusOK,
+		},
+		{
+			Name:          "degraded",
+			FinanceErr:    Degraded(errors.New("upstream down")),
+			StatusCode:    http.StatusOK,
+			Status:        StatusDegraded,
+			FinanceStatus: StatusDegraded,
+		},
+		{
+			Name:          "unhealthy",
+			FinanceErr:    errors.New("upstream down"),
+			StatusCode:    http.StatusServiceUnavailable,
+			Status:        StatusUnavailable,
+			FinanceStatus: StatusUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			mounted := []Mounted{
+				{
+					Category: Category{Name: "finance"},
+					HealthCheck: func(ctx context.Context) error {
+						return tc.FinanceErr
+					},
+				},
+				{Category: Category{Name: "programming"}},
+			}
+			r := gin.New()
+			r.GET("/health", HealthHandler(mounted))
+
+			// act
+			w := apitesting.PerformRequest(r, "GET", "/health")
+
+			// assert
+			assert.Equal(t, tc.StatusCode, w.Code)
+
+			output := HealthOutput{}
+			err := json.Unmarshal(w.Body.Bytes(), &output)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Status, output.Status)
+			assert.Equal(t, tc.FinanceStatus, output.Categories["finance"].Status)
+			assert.Equal(t, StatusOK, output.Categories["programming"].Status)
+			assert.NotContains(t, w.Body.String(), "upstream down")
+		})
+	}
+}
+
+func TestDegraded(t *testing.T) {
+	// act
+	err := Degraded(errors.New("upstream down"))
+
+	// assert
+	assert.True(t, errors.Is(err, ErrDegraded))
+	assert.EqualError(t, err, "degraded: upstream down")
+	assert.Nil(t, Degraded(nil))
+}
@@ -0,0 +1,204 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/openapi"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+)
+
+// Config holds the values of the environment variables required by a
+// category
+type Config map[string]string
+
+// LookupEnv reads an environment variable, like os.LookupEnv
+type LookupEnv func(key string) (string, bool)
+
+// HealthCheck reports if a category can serve requests. Errors marked with
+// Degraded report a category still serving requests.
+type HealthCheck func(ctx context.Context) error
+
+// Category is a group of utility functions served below the base router
+// group, like "finance" or "programming". Categories register themselves in
+// the init function of their package.
+type Category struct {
+	Name        string
+	Description string
+
+	// RequiredEnv lists the environment variables the category needs, read
+	// only if the category is enabled
+	RequiredEnv []string
+
+	// OptionalEnv lists the environment variables the category reads if they
+	// are set, like tuning options with defaults
+	OptionalEnv []string
+
+	// Policies and Docs describe the routes, with paths relative to the
+	// category router group
+	Policies []middleware.RoutePolicy
+	Docs     []openapi.Route
+
+	// GrpcMethods maps the gRPC methods of the category to the routes they
+	// mirror, so they are authorized with the same policy
+	GrpcMethods []GrpcMethod
+
+	// Setup creates the category functions with the config and defines their
+	// routes below the base router group. It also registers the gRPC services
+	// of the category, unless services is nil because gRPC is disabled.
+	// Returns the category router group and its health check, nil if the
+	// category is always healthy, or an error if the config is invalid.
+	Setup func(config Config, base *gin.RouterGroup, services grpc.ServiceRegistrar) (*gin.RouterGroup, HealthCheck, error)
+}
+
+// GrpcMethod maps a gRPC method, like "/learninggoapi.v1.FinanceService/ConvertCurrency",
+// to the route it mirrors, with the path relative to the category router group
+type GrpcMethod struct {
+	FullMethod string
+	Method     string
+	Path       string
+}
+
+// Mounted is an enabled category with its routes defined
+type Mounted struct {
+	Category
+	Group       *gin.RouterGroup
+	HealthCheck HealthCheck
+}
+
+var (
+	mu         sync.RWMutex
+	categories = map[string]Category{}
+)
+
+// Register makes a category available. It panics if the category has no
+// name or setup, or if the name is already registered.
+func Register(category Category) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if category.Name == "" || category.Setup == nil {
+		panic("registry: category without name or setup")
+	}
+	if _, found := categories[category.Name]; found {
+		panic(fmt.Sprintf("registry: category %q registered twice", category.Name))
+	}
+
+	categories[category.Name] = category
+}
+
+// Categories returns all the registered categories, sorted by name.
+func Categories() []Category {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	all := make([]Category, 0, len(categories))
+	for _, category := range categories {
+		all = append(all, category)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+
+	return all
+}
+
+// Enabled returns the categories in the comma-separated list, or all the
+// registered ones if the list is empty. Categories prefixed with "-" are
+// disabled instead, as in "-finance".
+func Enabled(list string) ([]Category, error) {
+	names := strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+
+	enabled := map[string]bool{}
+	disabled := map[string]bool{}
+	for _, name := range names {
+		set := enabled
+		if strings.HasPrefix(name, "-") {
+			name = strings.TrimPrefix(name, "-")
+			set = disabled
+		}
+
+		if _, found := lookup(name); !found {
+			return nil, fmt.Errorf("unknown category %q", name)
+		}
+		set[name] = true
+	}
+
+	selected := []Category{}
+	for _, category := range Categories() {
+		if disabled[category.Name] {
+			continue
+		}
+		if len(enabled) > 0 && !enabled[category.Name] {
+			continue
+		}
+		selected = append(selected, category)
+	}
+
+	return selected, nil
+}
+
+// Mount reads the config of the categories and sets up their routes below
+// the base router group, and their gRPC services if services is not nil. It
+// fails if a required environment variable is missing, or if a category
+// rejects its config.
+func Mount(
+	categories []Category,
+	base *gin.RouterGroup,
+	services grpc.ServiceRegistrar,
+	lookupEnv LookupEnv) ([]Mounted, error) {
+
+	mounted := []Mounted{}
+	for _, category := range categories {
+		config := Config{}
+		for _, key := range category.RequiredEnv {
+			value, found := lookupEnv(key)
+			if !found || value == "" {
+				return nil, fmt.Errorf("category %q requires the %s environment variable",
+					category.Name, key)
+			}
+			config[key] = value
+		}
+		for _, key := range category.OptionalEnv {
+			if value, found := lookupEnv(key); found && value != "" {
+				config[key] = value
+			}
+		}
+
+		log.Debug().Str("category", category.Name).Msg("mounting category")
+
+		group, healthCheck, err := category.Setup(config, base, services)
+		if err != nil {
+			return nil, fmt.Errorf("category %q: %s", category.Name, err)
+		}
+		mounted = append(mounted, Mounted{
+			Category:    category,
+			Group:       group,
+			HealthCheck: healthCheck,
+		})
+	}
+
+	return mounted, nil
+}
+
+func lookup(name string) (Category, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	category, found := categories[name]
+	return category, found
+}
+
+// reset removes all the registered categories, for testing purposes.
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	categories = map[string]Category{}
+}
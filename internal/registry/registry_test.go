@@ -0,0 +1,180 @@
+package registry
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apitesting"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+// newTestCategory creates a category with a single "GET /<name>/ping" route
+func newTestCategory(name string, requiredEnv ...string) Category {
+	return Category{
+		Name:        name,
+		RequiredEnv: requiredEnv,
+		Setup: func(config Config, base *gin.RouterGroup, services grpc.ServiceRegistrar) (*gin.RouterGroup, HealthCheck, error) {
+			group := base.Group("/" + name)
+			group.GET("/ping", func(c *gin.Context) {
+				c.JSON(200, config)
+			})
+			return group, nil, nil
+		},
+	}
+}
+
+func setupRegistry(t *testing.T, names ...string) {
+	reset()
+	t.Cleanup(reset)
+
+	for _, name := range names {
+		Register(newTestCategory(name))
+	}
+}
+
+func TestRegister(t *testing.T) {
+	// arrange
+	setupRegistry(t, "finance", "programming")
+
+	// act
+	categories := Categories()
+
+	// assert
+	assert.Len(t, categories, 2)
+	assert.Equal(t, "finance", categories[0].Name)
+	assert.Equal(t, "programming", categories[1].Name)
+}
+
+func TestRegisterTwice(t *testing.T) {
+	// arrange
+	setupRegistry(t, "finance")
+
+	// act & assert
+	assert.Panics(t, func() { Register(newTestCategory("finance")) })
+}
+
+func TestRegisterWithoutSetup(t *testing.T) {
+	// arrange
+	setupRegistry(t)
+
+	// act & assert
+	assert.Panics(t, func() { Register(Category{Name: "finance"}) })
+}
+
+func TestEnabled(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		List    string
+		Enabled []string
+		WantErr bool
+	}{
+		{Name: "all by default", List: "", Enabled: []string{"finance", "programming", "text"}},
+		{Name: "enabled list", List: "finance, text", Enabled: []string{"finance", "text"}},
+		{Name: "disabled list", List: "-finance", Enabled: []string{"programming", "text"}},
+		{Name: "both lists", List: "finance,text,-text", Enabled: []string{"finance"}},
+		{Name: "unknown", List: "finance,weather", WantErr: true},
+		{Name: "unknown disabled", List: "-weather", WantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			setupRegistry(t, "finance", "programming", "text")
+
+			// act
+			categories, err := Enabled(tc.List)
+
+			// assert
+			if tc.WantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			names := []string{}
+			for _, category := range categories {
+				names = append(names, category.Name)
+			}
+			assert.Equal(t, tc.Enabled, names)
+		})
+	}
+}
+
+func TestMount(t *testing.T) {
+	// arrange
+	r := gin.New()
+	categories := []Category{
+		newTestCategory("finance", "CURRCONV_API_KEY"),
+		newTestCategory("programming"),
+	}
+	env := map[string]string{"CURRCONV_API_KEY": "fake_key"}
+	lookupEnv := func(key string) (string, bool) {
+		value, found := env[key]
+		return value, found
+	}
+
+	// act
+	mounted, err := Mount(categories, r.Group("/v1"), nil, lookupEnv)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, mounted, 2)
+	assert.Equal(t, "/v1/finance", mounted[0].Group.BasePath())
+
+	w := apitesting.PerformRequest(r, "GET", "/v1/finance/ping")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), "fake_key")
+}
+
+func TestMountWithMissingEnv(t *testing.T) {
+	// arrange
+	r := gin.New()
+	categories := []Category{newTestCategory("finance", "CURRCONV_API_KEY")}
+	lookupEnv := func(key string) (string, bool) { return "", false }
+
+	// act
+	_, err := Mount(categories, r.Group("/v1"), nil, lookupEnv)
+
+	// assert
+	assert.EqualError(t, err,
+		`category "finance" requires the CURRCONV_API_KEY environment variable`)
+}
+
+func TestMountWithOptionalEnv(t *testing.T) {
+	// arrange
+	r := gin.New()
+	category := newTestCategory("finance")
+	category.OptionalEnv = []string{"CACHE_TTL", "MISSING"}
+	lookupEnv := func(key string) (string, bool) {
+		if key == "CACHE_TTL" {
+			return "1m", true
+		}
+		return "", false
+	}
+
+	// act
+	_, err := Mount([]Category{category}, r.Group("/v1"), nil, lookupEnv)
+
+	// assert
+	assert.NoError(t, err)
+	w := apitesting.PerformRequest(r, "GET", "/v1/finance/ping")
+	assert.JSONEq(t, `{"CACHE_TTL":"1m"}`, w.Body.String())
+}
+
+func TestMountWithInvalidConfig(t *testing.T) {
+	// arrange
+	r := gin.New()
+	category := newTestCategory("finance")
+	category.Setup = func(config Config, base *gin.RouterGroup, services grpc.ServiceRegistrar) (*gin.RouterGroup, HealthCheck, error) {
+		return nil, nil, errors.New("invalid CACHE_TTL value")
+	}
+
+	// act
+	_, err := Mount([]Category{category}, r.Group("/v1"), nil, os.LookupEnv)
+
+	// assert
+	assert.EqualError(t, err, `category "finance": invalid CACHE_TTL value`)
+}
@@ -0,0 +1,56 @@
+package requestid
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// Header carries the request ID in the requests and responses
+	Header = "X-Request-ID"
+
+	// Key stores the request ID in the gin context and in the log lines
+	Key = "request_id"
+)
+
+// maxLength is the length of the longest request ID accepted
+const maxLength = 128
+
+// validRequestId limits the accepted request IDs to safe characters, so they
+// can be logged and echoed back without escaping
+var validRequestId = regexp.MustCompile(fmt.Sprintf(`^[A-Za-z0-9._:-]{1,%d}$`, maxLength))
+
+// New generates a new request ID.
+func New() string {
+	return uuid.NewString()
+}
+
+// Sub returns the request ID of a part of the request, as in "<id>.3" for the
+// fourth operation of a batch. IDs too long to take the suffix are replaced
+// by their SHA-256 hash, so the result is still a valid request ID.
+func Sub(id string, part int) string {
+	suffix := "." + strconv.Itoa(part)
+	if len(id)+len(suffix) > maxLength {
+		sum := sha256.Sum256([]byte(id))
+		id = hex.EncodeToString(sum[:])
+	}
+
+	return id + suffix
+}
+
+// IsValid tells if a request ID sent by a client can be accepted.
+func IsValid(id string) bool {
+	return validRequestId.MatchString(id)
+}
+
+// Get returns the request ID of the request, or an empty string if the
+// request ID middleware did not run.
+func Get(c *gin.Context) string {
+	return c.GetString(Key)
+}
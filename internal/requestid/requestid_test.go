@@ -0,0 +1,77 @@
+package requestid
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	// act
+	first := New()
+	second := New()
+
+	// assert
+	assert.True(t, IsValid(first))
+	assert.NotEqual(t, first, second)
+}
+
+func TestSub(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Id   string
+	}{
+		{Name: "uuid", Id: "0b9c7c1e-5b2f-4f3e-9d7e-1f0c2b3a4d5e"},
+		{Name: "longest", Id: strings.Repeat("a", 128)},
+		{Name: "almost longest", Id: strings.Repeat("a", 126)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// act
+			sub := Sub(tc.Id, 12)
+
+			// assert
+			assert.True(t, IsValid(sub), sub)
+			assert.True(t, strings.HasSuffix(sub, ".12"), sub)
+			assert.Equal(t, sub, Sub(tc.Id, 12), "the same for the same request")
+		})
+	}
+
+	assert.Equal(t, "abc.3", Sub("abc", 3))
+	assert.Equal(t, strings.Repeat("a", 125)+".1", Sub(strings.Repeat("a", 125), 1))
+}
+
+func TestIsValid(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Id    string
+		Valid bool
+	}{
+		{Name: "uuid", Id: "0b9c7c1e-5b2f-4f3e-9d7e-1f0c2b3a4d5e", Valid: true},
+		{Name: "dotted", Id: "lb.1:abc_DEF", Valid: true},
+		{Name: "empty", Id: "", Valid: false},
+		{Name: "spaces", Id: "an id", Valid: false},
+		{Name: "newline", Id: "id\nforged", Valid: false},
+		{Name: "too long", Id: strings.Repeat("a", 129), Valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Valid, IsValid(tc.Id))
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	// arrange
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	// act & assert
+	assert.Empty(t, Get(c))
+	c.Set(Key, "fake-request-id")
+	assert.Equal(t, "fake-request-id", Get(c))
+}
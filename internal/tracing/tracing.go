@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+// ServiceName identifies the service in the traces
+const ServiceName = "learning-go-api"
+
+// Exporters supported by Setup
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Tracer creates the spans of the service
+var Tracer = otel.Tracer("github.com/renato0307/learning-go-api")
+
+// ShutdownFunc flushes the pending spans and stops the exporter
+type ShutdownFunc func(context.Context) error
+
+// Setup configures the global tracer provider with the exporter and the W3C
+// trace context propagator.
+//
+// The OTLP exporter is configured with the standard OTEL_EXPORTER_OTLP_*
+// environment variables. The stdout exporter writes the spans to the writer,
+// or to the standard output if nil.
+func Setup(exporter string, w io.Writer) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		if w == nil {
+			w = os.Stdout
+		}
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(context.Background())
+	default:
+		return nil, fmt.Errorf("unknown traces exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the traces exporter: %s", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(ServiceName))))
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupStdout(t *testing.T) {
+	// arrange
+	buffer := new(bytes.Buffer)
+	shutdown, err := Setup(ExporterStdout, buffer)
+	assert.NoError(t, err)
+
+	// act
+	_, span := Tracer.Start(context.Background(), "test-span")
+	span.End()
+	err = shutdown(context.Background())
+
+	// assert
+	assert.NoError(t, err)
+	assert.Contains(t, buffer.String(), "test-span")
+	assert.Contains(t, buffer.String(), ServiceName)
+}
+
+func TestSetup(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Exporter string
+		WantErr  bool
+	}{
+		{Name: "empty", Exporter: "", WantErr: false},
+		{Name: "none", Exporter: ExporterNone, WantErr: false},
+		{Name: "otlp", Exporter: ExporterOTLP, WantErr: false},
+		{Name: "unknown", Exporter: "zipkin", WantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// act
+			shutdown, err := Setup(tc.Exporter, nil)
+
+			// assert
+			if tc.WantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, shutdown)
+		})
+	}
+}
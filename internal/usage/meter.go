@@ -0,0 +1,61 @@
+package usage
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/logging"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+)
+
+// Meter counts the successful calls of each client per function and enforces
+// the daily and monthly quotas, returning HTTP 429 when exceeded.
+//
+// Each call reserves its unit of the quota before running, so concurrent
+// calls cannot exceed it, and gives it back if it fails.
+//
+// Requests without client (public routes) or not matching any route are not
+// counted.
+func Meter(qc *QuotaConfig, s Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := logging.FromContext(c.Request.Context())
+
+		clientId := c.GetString(middleware.ClientIdKey)
+		function := c.FullPath()
+		if clientId == "" || function == "" {
+			return
+		}
+
+		now := time.Now()
+		reserved, err := s.Reserve(clientId, function, qc.Find(clientId, function), now)
+		if err != nil {
+			// fails open, the store being down should not stop the API
+			logger.Error().Err(err).Msg("usage store error")
+			c.Next()
+			return
+		}
+
+		if !reserved {
+			logger.Debug().
+				Str("client_id", clientId).
+				Str("function", function).
+				Msg("quota exceeded")
+			apierror.Abort(c, apierror.New(c, apierror.CodeQuotaExceeded, ""))
+			return
+		}
+
+		c.Next()
+
+		// counts only the successful calls
+		if c.Writer.Status() < http.StatusBadRequest {
+			return
+		}
+
+		err = s.Release(clientId, function, now)
+		if err != nil {
+			logger.Error().Err(err).Msg("usage store error")
+		}
+	}
+}
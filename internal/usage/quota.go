@@ -0,0 +1,75 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Quota limits the calls to a function per day and per month. Zero means
+// unlimited.
+type Quota struct {
+	Daily   uint64 `json:"daily"`
+	Monthly uint64 `json:"monthly"`
+}
+
+// Limit returns the quota limit for the period.
+func (q Quota) Limit(period string) uint64 {
+	if period == Month {
+		return q.Monthly
+	}
+
+	return q.Daily
+}
+
+// QuotaRule sets the quota of a function, for a client or, if the client is
+// empty, for all clients.
+type QuotaRule struct {
+	ClientId string `json:"client_id"`
+
+	// Function is the route template, as in "/v1/finance/currconv"
+	Function string `json:"function"`
+
+	Quota
+}
+
+// QuotaConfig lists the quota rules. Functions without rules are only
+// metered.
+type QuotaConfig struct {
+	Rules []QuotaRule `json:"rules"`
+}
+
+// ParseQuotaConfig reads a quota config in JSON format.
+func ParseQuotaConfig(data []byte) (*QuotaConfig, error) {
+	config := QuotaConfig{}
+	err := json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quota config: %s", err)
+	}
+
+	for i, rule := range config.Rules {
+		if rule.Function == "" {
+			return nil, fmt.Errorf("invalid quota rule %d: function is required", i)
+		}
+	}
+
+	return &config, nil
+}
+
+// Find returns the quota of a client function. Client rules override the
+// rules for all clients.
+func (qc *QuotaConfig) Find(clientId, function string) Quota {
+	quota := Quota{}
+	for _, rule := range qc.Rules {
+		if rule.Function != function {
+			continue
+		}
+		if rule.ClientId == clientId {
+			return rule.Quota
+		}
+		if rule.ClientId == "" {
+			quota = rule.Quota
+		}
+	}
+
+	return quota
+}
@@ -0,0 +1,44 @@
+package usage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuotaConfig(t *testing.T) {
+	// act
+	config, err := ParseQuotaConfig([]byte(`{
+		"rules": [
+			{"function": "/v1/finance/currconv", "monthly": 1000},
+			{"client_id": "client-a", "function": "/v1/finance/currconv", "daily": 10, "monthly": 100}
+		]
+	}`))
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, Quota{Daily: 10, Monthly: 100}, config.Find("client-a", "/v1/finance/currconv"))
+	assert.Equal(t, Quota{Monthly: 1000}, config.Find("client-b", "/v1/finance/currconv"))
+	assert.Equal(t, Quota{}, config.Find("client-a", "/v1/programming/uuid"))
+	assert.Equal(t, uint64(1000), config.Find("client-b", "/v1/finance/currconv").Limit(Month))
+	assert.Equal(t, uint64(0), config.Find("client-b", "/v1/finance/currconv").Limit(Day))
+}
+
+func TestParseQuotaConfigWithInvalidData(t *testing.T) {
+	testCases := []struct {
+		Data    string
+		Purpose string
+	}{
+		{Data: "invalid json", Purpose: "invalid json"},
+		{Data: `{"rules": [{"daily": 10}]}`, Purpose: "missing function"},
+		{Data: `{"rules": [{"function": "/v1/x", "daily": -1}]}`, Purpose: "negative limit"},
+	}
+
+	for _, tc := range testCases {
+		// act
+		_, err := ParseQuotaConfig([]byte(tc.Data))
+
+		// assert
+		assert.NotNil(t, err, tc.Purpose)
+	}
+}
@@ -0,0 +1,234 @@
+package usage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Periods used to count the usage
+const (
+	Day   = "day"
+	Month = "month"
+)
+
+// keySeparator separates the client, function and period in the store keys
+const keySeparator = "\x00"
+
+var usageBucket = []byte("usage")
+
+// periods lists the periods each call is counted in
+var periods = []string{Day, Month}
+
+// Counter is the number of calls a client made to a function in a period.
+type Counter struct {
+	ClientId string `json:"client_id"`
+	Function string `json:"function"`
+	Period   string `json:"period"`
+	Start    string `json:"start"`
+	Count    uint64 `json:"count"`
+}
+
+// Store keeps the usage counters.
+type Store interface {
+	// Reserve adds one call to the counters of all periods containing at,
+	// only if none of them has reached the quota, returning if it did. The
+	// check and the increment are atomic, so concurrent calls cannot exceed
+	// the quota.
+	Reserve(clientId, function string, quota Quota, at time.Time) (bool, error)
+
+	// Release gives back a call added by Reserve, as when the call failed
+	Release(clientId, function string, at time.Time) error
+
+	// Get returns the count of a client function in the period containing at
+	Get(clientId, function, period string, at time.Time) (uint64, error)
+
+	// List returns the counters of the periods starting at the given times,
+	// for a client or, if empty, for all clients
+	List(clientId string, starts ...string) ([]Counter, error)
+
+	Close() error
+}
+
+// BoltStore keeps the usage counters in an embedded bbolt database, so they
+// survive restarts.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens, or creates, the database at the path.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the usage store: %s", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(usageBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot create the usage bucket: %s", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// Reserve adds one call to the counters unless the quota is reached, checking
+// them in the same transaction.
+func (s *BoltStore) Reserve(clientId, function string, quota Quota, at time.Time) (bool, error) {
+	reserved := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(usageBucket)
+		for _, period := range periods {
+			limit := quota.Limit(period)
+			key := counterKey(clientId, function, PeriodStart(period, at))
+			if limit > 0 && decodeCount(b.Get(key)) >= limit {
+				return nil
+			}
+		}
+
+		reserved = true
+		return addCall(b, clientId, function, at, 1)
+	})
+
+	return reserved, err
+}
+
+// Release removes one call from the counters.
+func (s *BoltStore) Release(clientId, function string, at time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return addCall(tx.Bucket(usageBucket), clientId, function, at, -1)
+	})
+}
+
+// Get returns the count of a client function in the period containing at.
+func (s *BoltStore) Get(clientId, function, period string, at time.Time) (uint64, error) {
+	var count uint64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		key := counterKey(clientId, function, PeriodStart(period, at))
+		count = decodeCount(tx.Bucket(usageBucket).Get(key))
+		return nil
+	})
+
+	return count, err
+}
+
+// List returns the counters of the periods starting at the given times, for
+// a client or, if empty, for all clients, ordered by client and function.
+func (s *BoltStore) List(clientId string, starts ...string) ([]Counter, error) {
+	counters := []Counter{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		prefix := ""
+		if clientId != "" {
+			prefix = clientId + keySeparator
+		}
+
+		c := tx.Bucket(usageBucket).Cursor()
+		for k, v := c.Seek([]byte(prefix)); k != nil && strings.HasPrefix(string(k), prefix); k, v = c.Next() {
+			counter := parseCounterKey(k)
+			if len(starts) > 0 && !contains(starts, counter.Start) {
+				continue
+			}
+
+			counter.Count = decodeCount(v)
+			counters = append(counters, counter)
+		}
+
+		return nil
+	})
+
+	return counters, err
+}
+
+// Close closes the database.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// PeriodStart returns the identifier of the period containing the time, as in
+// "2022-01-31" for days and "2022-01" for months, in UTC.
+func PeriodStart(period string, at time.Time) string {
+	if period == Month {
+		return at.UTC().Format("2006-01")
+	}
+
+	return at.UTC().Format("2006-01-02")
+}
+
+// PeriodOf returns the period of a period start.
+func PeriodOf(start string) string {
+	if len(start) == len("2006-01") {
+		return Month
+	}
+
+	return Day
+}
+
+// addCall adds delta calls to the counters of all periods containing at,
+// never going below zero.
+func addCall(b *bolt.Bucket, clientId, function string, at time.Time, delta int) error {
+	for _, period := range periods {
+		key := counterKey(clientId, function, PeriodStart(period, at))
+
+		count := decodeCount(b.Get(key))
+		if delta < 0 && count < uint64(-delta) {
+			count = 0
+		} else {
+			count = uint64(int64(count) + int64(delta))
+		}
+
+		err := b.Put(key, encodeCount(count))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func counterKey(clientId, function, start string) []byte {
+	return []byte(strings.Join([]string{clientId, function, start}, keySeparator))
+}
+
+func parseCounterKey(key []byte) Counter {
+	parts := strings.SplitN(string(key), keySeparator, 3)
+	if len(parts) != 3 {
+		return Counter{}
+	}
+
+	return Counter{
+		ClientId: parts[0],
+		Function: parts[1],
+		Period:   PeriodOf(parts[2]),
+		Start:    parts[2],
+	}
+}
+
+func encodeCount(count uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, count)
+	return buf
+}
+
+func decodeCount(value []byte) uint64 {
+	if len(value) != 8 {
+		return 0
+	}
+
+	return binary.BigEndian.Uint64(value)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,131 @@
+package usage
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoltStore(t *testing.T) {
+	// arrange
+	s := newTestStore(t)
+	day1 := time.Date(2022, 1, 30, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2022, 1, 31, 10, 0, 0, 0, time.UTC)
+
+	// act
+	s.Reserve("client-a", "/v1/finance/currconv", Quota{}, day1)
+	s.Reserve("client-a", "/v1/finance/currconv", Quota{}, day2)
+	s.Reserve("client-a", "/v1/finance/currconv", Quota{}, day2)
+	s.Reserve("client-b", "/v1/programming/uuid", Quota{}, day2)
+
+	// assert
+	count, err := s.Get("client-a", "/v1/finance/currconv", Day, day2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), count)
+
+	count, _ = s.Get("client-a", "/v1/finance/currconv", Month, day2)
+	assert.Equal(t, uint64(3), count)
+
+	count, _ = s.Get("client-b", "/v1/finance/currconv", Month, day2)
+	assert.Equal(t, uint64(0), count)
+
+	counters, err := s.List("client-a", "2022-01-31", "2022-01")
+	assert.Nil(t, err)
+	assert.Equal(t, []Counter{
+		{ClientId: "client-a", Function: "/v1/finance/currconv", Period: Month, Start: "2022-01", Count: 3},
+		{ClientId: "client-a", Function: "/v1/finance/currconv", Period: Day, Start: "2022-01-31", Count: 2},
+	}, counters)
+
+	counters, _ = s.List("", "2022-01")
+	assert.Len(t, counters, 2)
+
+	counters, _ = s.List("")
+	assert.Len(t, counters, 5)
+}
+
+func TestBoltStoreReserve(t *testing.T) {
+	// arrange
+	s := newTestStore(t)
+	now := time.Date(2022, 1, 31, 10, 0, 0, 0, time.UTC)
+	quota := Quota{Daily: 5}
+
+	// act - more concurrent calls than the quota
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reserved := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := s.Reserve("client-a", "/v1/finance/currconv", quota, now)
+			assert.Nil(t, err)
+			if ok {
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// assert
+	assert.Equal(t, 5, reserved)
+	count, _ := s.Get("client-a", "/v1/finance/currconv", Day, now)
+	assert.Equal(t, uint64(5), count)
+
+	// act & assert - released calls can be reserved again
+	assert.Nil(t, s.Release("client-a", "/v1/finance/currconv", now))
+	count, _ = s.Get("client-a", "/v1/finance/currconv", Month, now)
+	assert.Equal(t, uint64(4), count)
+
+	ok, err := s.Reserve("client-a", "/v1/finance/currconv", quota, now)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	// act & assert - releasing never goes below zero
+	assert.Nil(t, s.Release("client-b", "/v1/finance/currconv", now))
+	count, _ = s.Get("client-b", "/v1/finance/currconv", Day, now)
+	assert.Equal(t, uint64(0), count)
+}
+
+func TestBoltStoreSurvivesRestarts(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "usage.db")
+	s, err := NewBoltStore(path)
+	assert.Nil(t, err)
+	s.Reserve("client-a", "/v1/finance/currconv", Quota{}, time.Now())
+	s.Close()
+
+	// act
+	s, err = NewBoltStore(path)
+	assert.Nil(t, err)
+	defer s.Close()
+
+	// assert
+	count, _ := s.Get("client-a", "/v1/finance/currconv", Day, time.Now())
+	assert.Equal(t, uint64(1), count)
+}
+
+func TestPeriodStart(t *testing.T) {
+	// arrange
+	at := time.Date(2022, 1, 31, 23, 30, 0, 0, time.FixedZone("UTC-1", -3600))
+
+	// act & assert
+	assert.Equal(t, "2022-02-01", PeriodStart(Day, at))
+	assert.Equal(t, "2022-02", PeriodStart(Month, at))
+	assert.Equal(t, Day, PeriodOf("2022-02-01"))
+	assert.Equal(t, Month, PeriodOf("2022-02"))
+}
+
+func newTestStore(t *testing.T) *BoltStore {
+	s, err := NewBoltStore(filepath.Join(t.TempDir(), "usage.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
@@ -0,0 +1,176 @@
+package usage
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/logging"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/openapi"
+	"github.com/rs/zerolog/log"
+)
+
+// periodRegexp matches the period starts accepted in the "period" parameter
+var periodRegexp = regexp.MustCompile(`^\d{4}-\d{2}(-\d{2})?$`)
+
+// usageItem is the usage of a function in a period, with its quota limit
+type usageItem struct {
+	ClientId string `json:"client_id,omitempty"`
+	Function string `json:"function"`
+	Period   string `json:"period"`
+	Start    string `json:"start"`
+	Count    uint64 `json:"count"`
+	Limit    uint64 `json:"limit,omitempty"`
+}
+
+// getMyUsageOutput is the output of the "GET /me/usage" action
+type getMyUsageOutput struct {
+	ClientId string      `json:"client_id"`
+	Usage    []usageItem `json:"usage"`
+}
+
+// getUsageOutput is the output of the "GET /admin/usage" action
+type getUsageOutput struct {
+	Usage []usageItem `json:"usage"`
+}
+
+// RoutePolicies defines the scopes required by the usage routes, with paths
+// relative to the base router group. Every client can see its own usage.
+var RoutePolicies = []middleware.RoutePolicy{
+	{Method: "GET", Path: "/me/usage", Public: true},
+	{Method: "GET", Path: "/admin/usage", Scopes: []string{"admin-usage"}},
+}
+
+// periodParameter documents the "period" parameter of the usage routes
+var periodParameter = openapi.QueryParameter("period",
+	&openapi.Schema{
+		Type:        "string",
+		Description: "Day like \"2022-01-31\" or month like \"2022-01\". Defaults to the current day and month.",
+		Pattern:     periodRegexp.String(),
+	},
+	false)
+
+// RouteDocs documents the usage routes in the OpenAPI document, with paths
+// relative to the base router group
+var RouteDocs = []openapi.Route{
+	{
+		Method:     "GET",
+		Path:       "/me/usage",
+		Summary:    "Gets the usage of the calling client",
+		Tags:       []string{"usage"},
+		Parameters: []openapi.Parameter{periodParameter},
+		Output:     getMyUsageOutput{},
+		Errors:     []apierror.Code{apierror.CodeInvalidParameters},
+	},
+	{
+		Method:     "GET",
+		Path:       "/admin/usage",
+		Summary:    "Gets the usage of all the clients",
+		Tags:       []string{"usage"},
+		Parameters: []openapi.Parameter{periodParameter},
+		Output:     getUsageOutput{},
+		Errors:     []apierror.Code{apierror.CodeInvalidParameters},
+	},
+}
+
+// SetRouterGroup defines all the routes for the usage
+func SetRouterGroup(s Store, qc *QuotaConfig, base *gin.RouterGroup) *gin.RouterGroup {
+	log.Debug().Msg("setting router group for: usage")
+
+	base.GET("/me/usage", getMyUsage(s, qc))
+	base.GET("/admin/usage", getUsage(s, qc))
+
+	return base
+}
+
+// getMyUsage handles the request for the usage of the calling client.
+//
+// Reads the optional "period" parameter from the query string, a day like
+// "2022-01-31" or a month like "2022-01". Defaults to the current day and
+// month.
+//
+// It returns HTTP 200 on success.
+// Returns HTTP 400 if the period is not valid.
+// Returns HTTP 500 if the store fails.
+func getMyUsage(s Store, qc *QuotaConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientId := c.GetString(middleware.ClientIdKey)
+
+		items, ok := listUsage(c, s, qc, clientId)
+		if !ok {
+			return
+		}
+
+		// the client is already in the output
+		for i := range items {
+			items[i].ClientId = ""
+		}
+
+		c.JSON(http.StatusOK, getMyUsageOutput{ClientId: clientId, Usage: items})
+	}
+}
+
+// getUsage handles the request for the usage of all clients.
+//
+// Reads the same parameters and returns the same status codes as getMyUsage.
+func getUsage(s Store, qc *QuotaConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		items, ok := listUsage(c, s, qc, "")
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, getUsageOutput{Usage: items})
+	}
+}
+
+// listUsage reads the counters of the requested periods, writing the error
+// response if it fails.
+func listUsage(c *gin.Context, s Store, qc *QuotaConfig, clientId string) ([]usageItem, bool) {
+	logger := logging.FromContext(c.Request.Context())
+
+	starts := []string{
+		PeriodStart(Day, time.Now()),
+		PeriodStart(Month, time.Now()),
+	}
+
+	if period := c.Query("period"); period != "" {
+		if !periodRegexp.MatchString(period) {
+			msg := "error: 'period' must be a day (YYYY-MM-DD) or a month (YYYY-MM)"
+			apierror.Respond(c, apierror.Invalid(c, apierror.InvalidField("period", msg)))
+			return nil, false
+		}
+		starts = []string{period}
+	}
+
+	logger.Debug().
+		Str("client_id", clientId).
+		Strs("periods", starts).
+		Msg("listing usage")
+
+	counters, err := s.List(clientId, starts...)
+	if err != nil {
+		logger.Error().Err(err).Msg("error listing usage")
+		msg := "error reading the usage"
+		apierror.Respond(c, apierror.New(c, apierror.CodeInternal, msg))
+		return nil, false
+	}
+
+	items := []usageItem{}
+	for _, counter := range counters {
+		quota := qc.Find(counter.ClientId, counter.Function)
+		items = append(items, usageItem{
+			ClientId: counter.ClientId,
+			Function: counter.Function,
+			Period:   counter.Period,
+			Start:    counter.Start,
+			Count:    counter.Count,
+			Limit:    quota.Limit(counter.Period),
+		})
+	}
+
+	return items, true
+}
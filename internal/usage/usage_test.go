@@ -0,0 +1,169 @@
+package usage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/apitesting"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeter(t *testing.T) {
+	// arrange - two calls per day
+	s := newTestStore(t)
+	qc := &QuotaConfig{Rules: []QuotaRule{
+		{Function: "/v1/finance/currconv", Quota: Quota{Daily: 2}},
+	}}
+	r := setupGin(s, qc)
+
+	// act & assert - failed calls are not counted
+	w := performClientRequest(r, "client-a", "/v1/finance/currconv?fail=true")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performClientRequest(r, "client-a", "/v1/finance/currconv")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = performClientRequest(r, "client-a", "/v1/finance/currconv")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = performClientRequest(r, "client-a", "/v1/finance/currconv")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	apierror.AssertIsValid(t, w.Body.Bytes())
+
+	// act & assert - other clients have their own quota
+	w = performClientRequest(r, "client-b", "/v1/finance/currconv")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	count, _ := s.Get("client-a", "/v1/finance/currconv", Month, time.Now())
+	assert.Equal(t, uint64(2), count)
+}
+
+func TestGetMyUsage(t *testing.T) {
+	// arrange
+	s := newTestStore(t)
+	qc := &QuotaConfig{Rules: []QuotaRule{
+		{Function: "/v1/finance/currconv", Quota: Quota{Monthly: 1000}},
+	}}
+	r := setupGin(s, qc)
+	performClientRequest(r, "client-a", "/v1/finance/currconv")
+	performClientRequest(r, "client-b", "/v1/finance/currconv")
+
+	// act
+	w := performClientRequest(r, "client-a", "/v1/me/usage")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	output := getMyUsageOutput{}
+	err := json.Unmarshal(w.Body.Bytes(), &output)
+	assert.Nil(t, err)
+	assert.Equal(t, "client-a", output.ClientId)
+	assert.Equal(t, []usageItem{
+		{
+			Function: "/v1/finance/currconv",
+			Period:   Month,
+			Start:    PeriodStart(Month, time.Now()),
+			Count:    1,
+			Limit:    1000,
+		},
+		{
+			Function: "/v1/finance/currconv",
+			Period:   Day,
+			Start:    PeriodStart(Day, time.Now()),
+			Count:    1,
+		},
+		{
+			Function: "/v1/me/usage",
+			Period:   Month,
+			Start:    PeriodStart(Month, time.Now()),
+			Count:    1,
+		},
+		{
+			Function: "/v1/me/usage",
+			Period:   Day,
+			Start:    PeriodStart(Day, time.Now()),
+			Count:    1,
+		},
+	}, output.Usage, "the call counts itself")
+}
+
+func TestGetUsage(t *testing.T) {
+	// arrange
+	s := newTestStore(t)
+	r := setupGin(s, &QuotaConfig{})
+	performClientRequest(r, "client-a", "/v1/finance/currconv")
+	performClientRequest(r, "client-b", "/v1/finance/currconv")
+
+	// act
+	w := performClientRequest(r, "client-admin", "/v1/admin/usage?period="+PeriodStart(Month, time.Now()))
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	output := getUsageOutput{}
+	err := json.Unmarshal(w.Body.Bytes(), &output)
+	assert.Nil(t, err)
+	assert.Len(t, output.Usage, 3)
+	assert.Equal(t, "client-a", output.Usage[0].ClientId)
+	assert.Equal(t, "client-admin", output.Usage[1].ClientId, "the call counts itself")
+	assert.Equal(t, "client-b", output.Usage[2].ClientId)
+}
+
+func TestGetUsageWithInvalidPeriod(t *testing.T) {
+	// arrange
+	r := setupGin(newTestStore(t), &QuotaConfig{})
+
+	// act
+	w := performClientRequest(r, "client-admin", "/v1/admin/usage?period=january")
+
+	// assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	apierror.AssertIsProblem(t, w, apierror.CodeInvalidParameters)
+	apierror.AssertHasFieldError(t, w.Body.Bytes(), "period")
+}
+
+func TestRouteDocs(t *testing.T) {
+	// arrange
+	r := gin.New()
+	base := r.Group("/v1")
+	SetRouterGroup(newTestStore(t), &QuotaConfig{}, base)
+	doc := openapi.NewDocument(openapi.Info{Title: "test", Version: "1"})
+
+	// act
+	doc.Register(base.BasePath(), RouteDocs...)
+
+	// assert
+	assert.NoError(t, doc.CheckRoutes(r.Routes()))
+}
+
+func setupGin(s Store, qc *QuotaConfig) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) { // fake Authenticator
+		c.Set(middleware.ClientIdKey, c.GetHeader("X-Client-Id"))
+	})
+	r.Use(Meter(qc, s))
+
+	v1 := r.Group("/v1")
+	SetRouterGroup(s, qc, v1)
+	v1.GET("/finance/currconv", func(c *gin.Context) {
+		if c.Query("fail") != "" {
+			apierror.Respond(c, apierror.New(c, apierror.CodeBadRequest, "fake error"))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	return r
+}
+
+func performClientRequest(r http.Handler, clientId, path string) *httptest.ResponseRecorder {
+	header := http.Header{}
+	header.Set("X-Client-Id", clientId)
+	return apitesting.PerformRequestWithHeader(r, "GET", path, header)
+}
@@ -1,39 +1,231 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/batch"
+	"github.com/renato0307/learning-go-api/internal/grpcapi"
+	"github.com/renato0307/learning-go-api/internal/mcp"
 	"github.com/renato0307/learning-go-api/internal/middleware"
-	"github.com/renato0307/learning-go-api/pkg/finance"
-	"github.com/renato0307/learning-go-api/pkg/programming"
-	financelib "github.com/renato0307/learning-go-lib/finance"
-	programminglib "github.com/renato0307/learning-go-lib/programming"
+	"github.com/renato0307/learning-go-api/internal/openapi"
+	"github.com/renato0307/learning-go-api/internal/registry"
+	"github.com/renato0307/learning-go-api/internal/tracing"
+	"github.com/renato0307/learning-go-api/internal/usage"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 const (
-	CURRCONV_API_KEY   = "CURRCONV_API_KEY"
 	AUTH_TOKEN_ISS     = "AUTH_TOKEN_ISS"
 	AUTH_JWKS_LOCATION = "AUTH_JWKS_LOCATION"
+
+	AUTH_JWKS_REFRESH_INTERVAL = "AUTH_JWKS_REFRESH_INTERVAL"
+	AUTH_TOKEN_SOURCES         = "AUTH_TOKEN_SOURCES"
+	AUTH_ISSUERS_FILE          = "AUTH_ISSUERS_FILE"
+
+	AUTH_CLIENT_CREDENTIALS_ONLY = "AUTH_CLIENT_CREDENTIALS_ONLY"
+
+	AUTHZ_SCOPE_PREFIX = "AUTHZ_SCOPE_PREFIX"
+	AUTHZ_POLICY_FILE  = "AUTHZ_POLICY_FILE"
+
+	RATELIMIT_FILE = "RATELIMIT_FILE"
+	QUOTA_FILE     = "QUOTA_FILE"
+	USAGE_DB_PATH  = "USAGE_DB_PATH"
+
+	METRICS_ENABLED = "METRICS_ENABLED"
+	METRICS_TOKEN   = "METRICS_TOKEN"
+
+	OTEL_TRACES_EXPORTER = "OTEL_TRACES_EXPORTER"
+
+	OPENAPI_TOKEN_URL = "OPENAPI_TOKEN_URL"
+
+	ENABLED_CATEGORIES = "ENABLED_CATEGORIES"
+
+	APIERROR_INCLUDE_MESSAGE = "APIERROR_INCLUDE_MESSAGE"
+
+	SERVER_MODE = "SERVER_MODE"
+
+	PORT = "PORT"
+
+	GRPC_ADDRESS = "GRPC_ADDRESS"
+
+	BATCH_MAX_OPERATIONS = "BATCH_MAX_OPERATIONS"
+	BATCH_CONCURRENCY    = "BATCH_CONCURRENCY"
+)
+
+// Server modes, set in SERVER_MODE
+const (
+	// serverModeHttp serves the API over HTTP, the default
+	serverModeHttp = "http"
+
+	// serverModeMcpStdio serves the functions as MCP tools over the standard
+	// input and output
+	serverModeMcpStdio = "mcp-stdio"
 )
 
+const (
+	// metricsPath is where the Prometheus metrics are served
+	metricsPath = "/metrics"
+
+	// healthPath is where the health of the categories is served
+	healthPath = "/health"
+
+	// openapiPath and docsPath are where the OpenAPI document and its docs UI
+	// are served, with the docs UI files below docsAssetsPath
+	openapiPath    = "/openapi.json"
+	docsPath       = "/docs"
+	docsAssetsPath = "/docs/assets"
+)
+
+// apiTitle and apiVersion describe the API in the OpenAPI document
+const (
+	apiTitle   = "learning-go-api"
+	apiVersion = "1.0.0"
+)
+
+// defaultPort is where the HTTP API listens if PORT is not set
+const defaultPort = "8080"
+
+// shutdownTimeout limits how long stopping takes, for the requests in flight
+// to finish and the buffered spans to be exported
+const shutdownTimeout = 10 * time.Second
+
+// metricsRouteDoc documents the metrics route, defined if METRICS_ENABLED
+var metricsRouteDoc = openapi.Route{
+	Method:      "GET",
+	Path:        metricsPath,
+	Summary:     "Serves the metrics in the Prometheus text format",
+	ContentType: "text/plain",
+	Errors:      []apierror.Code{apierror.CodeUnauthorized},
+}
+
+// rootRouteDocs documents the routes defined in configureGin
+var rootRouteDocs = []openapi.Route{
+	{
+		Method:  "GET",
+		Path:    "/",
+		Summary: "Welcomes the clients, also used as the liveness probe",
+		Output:  gin.H{},
+	},
+	{
+		Method:      "GET",
+		Path:        healthPath,
+		Summary:     "Checks the health of the function categories",
+		Description: "Returns HTTP 503 if any category is unavailable. Used as the readiness probe.",
+		Output:      registry.HealthOutput{},
+	},
+	{
+		Method:  "GET",
+		Path:    openapiPath,
+		Summary: "Serves this OpenAPI document",
+		Output:  map[string]interface{}{},
+	},
+	{
+		Method:      "GET",
+		Path:        docsPath,
+		Summary:     "Serves the docs UI of this OpenAPI document",
+		ContentType: "text/html",
+	},
+	{
+		Method:      "GET",
+		Path:        docsAssetsPath + "/*file",
+		Summary:     "Serves the files of the docs UI",
+		ContentType: "application/octet-stream",
+		Errors:      []apierror.Code{apierror.CodeNotFound},
+	},
+}
+
 func main() {
-	r := configureGin()
-	r.Run()
+	switch mode := getEnv(SERVER_MODE, serverModeHttp); mode {
+	case serverModeHttp:
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		shutdown := setupTracing(os.Stdout)
+		defer flushTracing(shutdown)
+
+		r, grpcServer := configureServers()
+		server := &http.Server{Addr: ":" + getEnv(PORT, defaultPort), Handler: r}
+		err := serveHttp(ctx, server, grpcServer, getEnv(GRPC_ADDRESS, ""))
+		panicOnError(err, "cannot serve the HTTP API")
+	case serverModeMcpStdio:
+		// the standard output is reserved for the MCP messages
+		shutdown := setupTracing(os.Stderr)
+		defer shutdown(context.Background())
+
+		err := serveMcpStdio(context.Background(), os.Stdin, os.Stdout)
+		panicOnError(err, "cannot serve MCP over stdio")
+	default:
+		panic(fmt.Sprintf("error: unknown %s value %q", SERVER_MODE, mode))
+	}
+}
+
+// flushTracing exports the spans still buffered, within the shutdownTimeout.
+func flushTracing(shutdown tracing.ShutdownFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := shutdown(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("cannot flush the traces")
+	}
+}
+
+// setupTracing configures the exporter set in OTEL_TRACES_EXPORTER: "otlp",
+// "stdout" or "none", the default. The OTLP exporter reads the standard
+// OTEL_EXPORTER_OTLP_* environment variables, like
+// OTEL_EXPORTER_OTLP_ENDPOINT. The stdout exporter writes to w.
+func setupTracing(w io.Writer) tracing.ShutdownFunc {
+	exporter := getEnv(OTEL_TRACES_EXPORTER, tracing.ExporterNone)
+	shutdown, err := tracing.Setup(exporter, w)
+	panicOnError(err, "cannot set up tracing")
+
+	log.Debug().Str("otel_traces_exporter", exporter).Msg("tracing set up")
+
+	return shutdown
 }
 
-func configureGin() *gin.Engine {
+// configureServers sets up the HTTP API and the gRPC API, which is nil unless
+// GRPC_ADDRESS is set, as in ":9090". Both share the functions, the
+// authentication and the authorization policy.
+func configureServers() (*gin.Engine, *grpc.Server) {
 	// Initialize Gin
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
+	configureApiErrors()
+	policy := middleware.NewAuthorizationPolicy(getEnv(AUTHZ_SCOPE_PREFIX, ""))
+	authenticatorConfig := newAuthenticatorConfig()
+	r.Use(middleware.RequestID())
 	r.Use(middleware.DefaultStructuredLogger())
-	r.Use(middleware.Authenticator(newAuthenticatorConfig()))
-	r.Use(middleware.Authorizer())
-	r.Use(gin.Recovery())
+	r.Use(middleware.Metrics())
+	r.Use(middleware.Tracing())
+	r.Use(middleware.Traced("authenticator",
+		middleware.Authenticator(authenticatorConfig)))
+	r.Use(middleware.Traced("authorizer",
+		middleware.Authorizer(policy)))
+	rateLimits := newRateLimiterConfig()
+	rateLimitStore := middleware.NewMemoryRateLimitStore()
+	r.Use(middleware.Traced("rate-limiter",
+		middleware.RateLimiter(rateLimits, rateLimitStore)))
+	quotas := newQuotaConfig()
+	usageStore := newUsageStore()
+	r.Use(usage.Meter(quotas, usageStore))
+	r.Use(middleware.Recovery())
+	r.NoRoute(middleware.NotFound)
 
 	// Default route>
 	r.GET("/", func(c *gin.Context) {
@@ -41,19 +233,211 @@ func configureGin() *gin.Engine {
 			"message": "Hello, welcome to the learning-go-api",
 		})
 	})
+	policy.Register("", middleware.RoutePolicy{Method: "GET", Path: "/", Public: true})
+
+	// OpenAPI document and docs UI routes
+	doc := openapi.NewDocument(openapi.Info{Title: apiTitle, Version: apiVersion})
+	r.GET(openapiPath, openapi.Handler(doc))
+	r.GET(docsPath, openapi.DocsHandler(apiTitle, openapiPath, docsAssetsPath))
+	r.GET(docsAssetsPath+"/*file", openapi.AssetsHandler())
+	policy.Register("",
+		middleware.RoutePolicy{Method: "GET", Path: openapiPath, Public: true},
+		middleware.RoutePolicy{Method: "GET", Path: docsPath, Public: true},
+		middleware.RoutePolicy{Method: "GET", Path: docsAssetsPath + "/*file", Public: true})
+	doc.Register("", rootRouteDocs...)
+
+	// Metrics route, if enabled, protected by its own token instead of the
+	// authenticator
+	if metricsEnabled() {
+		r.GET(metricsPath, middleware.MetricsHandler(getEnv(METRICS_TOKEN, "")))
+		policy.Register("", middleware.RoutePolicy{Method: "GET", Path: metricsPath, Public: true})
+		doc.Register("", metricsRouteDoc)
+	}
+
+	// gRPC API, authorizing, rate limiting and metering the methods like the
+	// routes they mirror
+	var grpcServer *grpc.Server
+	var grpcServices grpc.ServiceRegistrar
+	grpcRoutes := grpcapi.Routes{}
+	if getEnv(GRPC_ADDRESS, "") != "" {
+		grpcServer = grpcapi.NewServer(authenticatorConfig, policy,
+			rateLimits, rateLimitStore, quotas, usageStore, grpcRoutes)
+		grpcServices = grpcServer
+	}
 
-	// Utility functions routes
+	// Utility functions routes, one router group per enabled category
 	base := r.Group("/v1")
+	categories := mountCategories(base, grpcServices)
+	for _, category := range categories {
+		policy.Register(category.Group.BasePath(), category.Policies...)
+		doc.Register(category.Group.BasePath(), category.Docs...)
+		doc.AddTag(category.Name, category.Description)
+		grpcRoutes.Add(category.Group.BasePath(), category.GrpcMethods...)
+	}
+	if grpcServer != nil {
+		grpc_health_v1.RegisterHealthServer(grpcServer, grpcapi.NewHealthServer(categories))
+		reflection.Register(grpcServer)
+	}
+
+	// Health route, the readiness probe checking the categories
+	r.GET(healthPath, registry.HealthHandler(categories))
+	policy.Register("", middleware.RoutePolicy{Method: "GET", Path: healthPath, Public: true})
+
+	// Function discovery routes
+	registry.SetRouterGroup(categories, policy, base)
+	policy.Register(base.BasePath(), registry.RoutePolicies...)
+	doc.Register(base.BasePath(), registry.RouteDocs...)
+	doc.AddTag("discovery", "Discovery of the available functions")
+
+	// Batch route, calling the functions through this router so each
+	// operation is authorized like the function route
+	batch.SetRouterGroup(categories, r, newBatchConfig(), base)
+	policy.Register(base.BasePath(), batch.RoutePolicies...)
+	doc.Register(base.BasePath(), batch.RouteDocs...)
+	doc.AddTag("batch", "Several function calls in one request")
+
+	// MCP streamable HTTP transport, calling the functions through this router
+	// so each tool call is authorized like the function route
+	mcpServer := mcp.NewServer(apiTitle, apiVersion, mcp.ToolsFor(categories), r)
+	r.POST(mcp.Path, mcpServer.HttpHandler())
+	policy.Register("", mcp.RoutePolicies...)
+	doc.Register("", mcp.RouteDocs...)
+	doc.AddTag("mcp", "Model Context Protocol server")
+
+	// Usage routes
+	usage.SetRouterGroup(usageStore, quotas, base)
+	policy.Register(base.BasePath(), usage.RoutePolicies...)
+	doc.Register(base.BasePath(), usage.RouteDocs...)
+	doc.AddTag("usage", "Usage and quotas of the clients")
+
+	// Policies in the file override the registered ones
+	loadAuthorizationPolicy(policy)
+	err := policy.CheckRoutes(r.Routes())
+	panicOnError(err, "invalid authorization policy")
+
+	// Documents the scopes after the policy file is merged
+	doc.ApplyPolicy(policy, getEnv(OPENAPI_TOKEN_URL, ""))
+	err = doc.CheckRoutes(r.Routes())
+	panicOnError(err, "incomplete OpenAPI document")
+
+	return r, grpcServer
+}
+
+// serveHttp serves the HTTP API, and the gRPC API if not nil, until the
+// context is done, as when the process is asked to stop. The requests in
+// flight are then given the shutdownTimeout to finish.
+func serveHttp(ctx context.Context, server *http.Server, grpcServer *grpc.Server, grpcAddress string) error {
+	if grpcServer != nil {
+		go serveGrpc(grpcServer, grpcAddress)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Info().Str("http_address", server.Addr).Msg("serving the HTTP API")
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info().Msg("shutting down the servers")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+
+	return err
+}
+
+// serveGrpc serves the gRPC API on the address, like ":9090".
+func serveGrpc(grpcServer *grpc.Server, address string) {
+	listener, err := net.Listen("tcp", address)
+	panicOnError(err, "cannot listen for the gRPC API")
+
+	log.Info().Str("grpc_address", address).Msg("serving the gRPC API")
+
+	err = grpcServer.Serve(listener)
+	panicOnError(err, "cannot serve the gRPC API")
+}
+
+// serveMcpStdio serves the enabled categories as MCP tools over stdio, until
+// in is closed. There is no authentication, rate limiting or quota, the client
+// is the process that started the server.
+func serveMcpStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	configureApiErrors()
+	r.Use(middleware.RequestID())
+	r.Use(middleware.Tracing())
+	r.Use(middleware.Recovery())
+	r.NoRoute(middleware.NotFound)
+
+	categories := mountCategories(r.Group("/v1"), nil)
+	server := mcp.NewServer(apiTitle, apiVersion, mcp.ToolsFor(categories), r)
 
-	p := programminglib.ProgrammingFunctions{}
-	programming.SetRouterGroup(&p, base)
+	log.Debug().Msg("serving mcp over stdio")
 
-	useDefaultUrl := ""
-	apiKey := getRequiredEnv(CURRCONV_API_KEY)
-	f := financelib.NewFinanceFunctions(useDefaultUrl, apiKey)
-	finance.SetRouterGroup(&f, base)
+	return server.ServeStdio(ctx, in, out)
+}
+
+// mountCategories sets up the function categories enabled in
+// ENABLED_CATEGORIES, as in "finance,programming" or "-finance". All the
+// registered categories are enabled by default. Their gRPC services are
+// registered unless services is nil.
+func mountCategories(base *gin.RouterGroup, services grpc.ServiceRegistrar) []registry.Mounted {
+	enabled, err := registry.Enabled(getEnv(ENABLED_CATEGORIES, ""))
+	panicOnError(err, "invalid ENABLED_CATEGORIES value")
+
+	mounted, err := registry.Mount(enabled, base, services, os.LookupEnv)
+	panicOnError(err, "cannot mount the function categories")
+
+	return mounted
+}
+
+// metricsEnabled tells if the metrics are served, disabled unless
+// METRICS_ENABLED is true.
+func metricsEnabled() bool {
+	enabled, err := strconv.ParseBool(getEnv(METRICS_ENABLED, "false"))
+	panicOnError(err, "invalid METRICS_ENABLED value")
+
+	return enabled
+}
+
+// configureApiErrors sets if the error responses keep the "message" field of
+// the previous error model, enabled unless APIERROR_INCLUDE_MESSAGE is false.
+func configureApiErrors() {
+	includeMessage, err := strconv.ParseBool(getEnv(APIERROR_INCLUDE_MESSAGE, "true"))
+	panicOnError(err, "invalid APIERROR_INCLUDE_MESSAGE value")
+
+	apierror.IncludeMessage = includeMessage
+}
+
+// loadAuthorizationPolicy merges the policy in the optional AUTHZ_POLICY_FILE
+// into the registered one.
+func loadAuthorizationPolicy(policy *middleware.AuthorizationPolicy) {
+	policyFile := getEnv(AUTHZ_POLICY_FILE, "")
+	if policyFile == "" {
+		return
+	}
+
+	data, err := ioutil.ReadFile(policyFile)
+	panicOnError(err, "cannot read the authorization policy file")
+
+	filePolicy, err := middleware.ParseAuthorizationPolicy(data)
+	panicOnError(err, "invalid authorization policy file")
 
-	return r
+	policy.Merge(filePolicy)
+
+	log.Debug().
+		Str("authz_policy_file", policyFile).
+		Int("routes", len(filePolicy.Routes)).
+		Msg("authorization policy file loaded")
 }
 
 func getRequiredEnv(key string) string {
@@ -66,44 +450,198 @@ func getRequiredEnv(key string) string {
 	return value
 }
 
+func getEnv(key, defaultValue string) string {
+	value, exists := os.LookupEnv(key)
+
+	if !exists {
+		return defaultValue
+	}
+
+	return value
+}
+
+// newRateLimiterConfig reads the rate limits from the optional RATELIMIT_FILE,
+// a JSON file with the default limit and the per client and per route group
+// rules (see middleware.RateLimiterConfig). Without it requests are not
+// limited.
+func newRateLimiterConfig() *middleware.RateLimiterConfig {
+	rateLimitFile := getEnv(RATELIMIT_FILE, "")
+	if rateLimitFile == "" {
+		return &middleware.RateLimiterConfig{}
+	}
+
+	data, err := ioutil.ReadFile(rateLimitFile)
+	panicOnError(err, "cannot read the rate limit file")
+
+	config, err := middleware.ParseRateLimiterConfig(data)
+	panicOnError(err, "invalid rate limit file")
+
+	log.Debug().
+		Str("ratelimit_file", rateLimitFile).
+		Int("rules", len(config.Rules)).
+		Msg("rate limiter config loaded")
+
+	return config
+}
+
+// newBatchConfig reads the maximum operations of a batch from the optional
+// BATCH_MAX_OPERATIONS and how many run at the same time from the optional
+// BATCH_CONCURRENCY (see batch.Config).
+func newBatchConfig() batch.Config {
+	maxOperations, err := strconv.Atoi(
+		getEnv(BATCH_MAX_OPERATIONS, strconv.Itoa(batch.DefaultMaxOperations)))
+	panicOnError(err, "invalid BATCH_MAX_OPERATIONS value")
+
+	concurrency, err := strconv.Atoi(
+		getEnv(BATCH_CONCURRENCY, strconv.Itoa(batch.DefaultConcurrency)))
+	panicOnError(err, "invalid BATCH_CONCURRENCY value")
+
+	return batch.Config{MaxOperations: maxOperations, Concurrency: concurrency}
+}
+
+// newQuotaConfig reads the quotas from the optional QUOTA_FILE, a JSON file
+// with the daily and monthly limits per function and client (see
+// usage.QuotaConfig). Without it the usage is only metered.
+func newQuotaConfig() *usage.QuotaConfig {
+	quotaFile := getEnv(QUOTA_FILE, "")
+	if quotaFile == "" {
+		return &usage.QuotaConfig{}
+	}
+
+	data, err := ioutil.ReadFile(quotaFile)
+	panicOnError(err, "cannot read the quota file")
+
+	config, err := usage.ParseQuotaConfig(data)
+	panicOnError(err, "invalid quota file")
+
+	log.Debug().
+		Str("quota_file", quotaFile).
+		Int("rules", len(config.Rules)).
+		Msg("quota config loaded")
+
+	return config
+}
+
+// newUsageStore opens the usage database at USAGE_DB_PATH, defaulting to
+// "usage.db" in the working directory.
+func newUsageStore() usage.Store {
+	path := getEnv(USAGE_DB_PATH, "usage.db")
+	store, err := usage.NewBoltStore(path)
+	panicOnError(err, "cannot open the usage store")
+
+	log.Debug().Str("usage_db_path", path).Msg("usage store opened")
+
+	return store
+}
+
+// panicOnError logs the error and panics with the message, as the service
+// cannot start with an invalid configuration.
+func panicOnError(err error, msg string) {
+	if err != nil {
+		log.Error().Err(err).Msg(msg)
+		panic(msg)
+	}
+}
+
 // newAuthenticatorConfig gathers all authentication related information to set
 // up the Authenticator middleware configuration.
 //
-// It requires the definition two environment variables:
-//
-// AUTH_JWKS_LOCATION: https://cognito-idp.$AWS_REGION.amazonaws.com/$POOL_ID/.well-known/jwks.json
+// To trust a single Cognito user pool it requires the definition of the
+// AUTH_TOKEN_ISS environment variable, for instance:
 //
 // AUTH_TOKEN_ISS: https://cognito-idp.$AWS_REGION.amazonaws.com/$POOL_ID
+//
+// The JWKS location and the accepted algorithms are discovered from the
+// issuer's /.well-known/openid-configuration, unless AUTH_JWKS_LOCATION is
+// set.
+//
+// To trust several issuers, AUTH_ISSUERS_FILE must point to a JSON file with
+// the list of trusted issuers (see middleware.TrustedIssuer) instead.
+//
+// Optionally AUTH_JWKS_REFRESH_INTERVAL sets how often the JWKS is refreshed
+// in the background (e.g. "30m"), defaulting to one hour, and
+// AUTH_TOKEN_SOURCES lists where tokens are accepted from (e.g.
+// "bearer,authentication,query,form"), defaulting to "bearer,authentication".
+//
+// The root and the metrics path, if enabled, do not require a token. The
+// metrics are protected by the METRICS_TOKEN instead, if defined.
 func newAuthenticatorConfig() *middleware.AuthenticatorConfig {
 
-	// Gets the JSON Web Key Set download URL
-	jwksLocation := getRequiredEnv(AUTH_JWKS_LOCATION)
-	r, err := http.Get(jwksLocation)
-	if err != nil {
-		msg := "cannot get the JWKS content for authentication"
-		log.Error().Err(err).Msg(msg)
-		panic(msg)
+	// Gets the JSON Web Key Set refresh interval
+	refreshInterval, err := time.ParseDuration(
+		getEnv(AUTH_JWKS_REFRESH_INTERVAL, middleware.DefaultKeySetRefreshInterval.String()))
+	if err == nil && refreshInterval <= 0 {
+		err = fmt.Errorf("the interval must be positive: %s", refreshInterval)
 	}
-	defer r.Body.Close()
+	panicOnError(err, "invalid JWKS refresh interval for authentication")
 
-	// Downloads the JSON Web Key Set
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		msg := "cannot read the JWKS content for authentication"
-		log.Error().Err(err).Msg(msg)
-		panic(msg)
+	// Gets the trusted issuers
+	issuers := newTrustedIssuers()
+
+	// Downloads the JSON Web Key Sets and keeps them refreshed
+	discoveryClient := &http.Client{Timeout: middleware.DiscoveryTimeout}
+	for _, issuer := range issuers {
+		if issuer.JwksLocation == "" {
+			err = issuer.Discover(discoveryClient)
+			panicOnError(err, "cannot discover the OpenID provider metadata for authentication")
+		}
+
+		issuer.KeySet = middleware.NewKeySetCache(issuer.JwksLocation, refreshInterval)
+		err = issuer.KeySet.Refresh()
+		panicOnError(err, "cannot get the JWKS content for authentication")
+		issuer.KeySet.Start(context.Background())
+
+		log.Debug().
+			Str("auth_token_iss", issuer.Issuer).
+			Str("auth_jwks_location", issuer.JwksLocation).
+			Strs("auth_algorithms", issuer.Algorithms).
+			Msg("trusted issuer loaded")
 	}
 
+	// Gets the allowed token sources
+	tokenSources, err := middleware.ParseTokenSources(getEnv(AUTH_TOKEN_SOURCES, ""))
+	panicOnError(err, "invalid token sources for authentication")
+
 	// Creates the AuthenticatorConfig structure
 	config := middleware.AuthenticatorConfig{
-		KeySetJSON: body,
-		Issuer:     getRequiredEnv(AUTH_TOKEN_ISS),
+		Issuers:      issuers,
+		TokenSources: tokenSources,
+		PublicPaths:  []string{"/", healthPath, openapiPath, docsPath, docsAssetsPath + "/*file"},
+	}
+	if metricsEnabled() {
+		config.PublicPaths = append(config.PublicPaths, metricsPath)
 	}
 
 	log.Debug().
-		Str("auth_token_iss", config.Issuer).
-		Str("auth_jwks_location", jwksLocation).
+		Int("auth_issuers", len(config.Issuers)).
+		Str("auth_jwks_refresh_interval", refreshInterval.String()).
 		Msg("authenticator config loaded")
 
 	return &config
 }
+
+// newTrustedIssuers reads the trusted issuers from the AUTH_ISSUERS_FILE or,
+// if not defined, creates the Cognito one from AUTH_TOKEN_ISS and the optional
+// AUTH_JWKS_LOCATION. The Cognito issuer only accepts client credentials
+// tokens unless AUTH_CLIENT_CREDENTIALS_ONLY is false, which is required for
+// the user tokens of the roles in the authorization policy.
+func newTrustedIssuers() []*middleware.TrustedIssuer {
+	issuersFile := getEnv(AUTH_ISSUERS_FILE, "")
+	if issuersFile == "" {
+		clientCredentialsOnly, err := strconv.ParseBool(getEnv(AUTH_CLIENT_CREDENTIALS_ONLY, "true"))
+		panicOnError(err, "invalid AUTH_CLIENT_CREDENTIALS_ONLY value")
+
+		issuer := middleware.NewCognitoIssuer(getRequiredEnv(AUTH_TOKEN_ISS), nil)
+		issuer.JwksLocation = getEnv(AUTH_JWKS_LOCATION, "")
+		issuer.ClientCredentialsOnly = clientCredentialsOnly
+		return []*middleware.TrustedIssuer{issuer}
+	}
+
+	data, err := ioutil.ReadFile(issuersFile)
+	panicOnError(err, "cannot read the trusted issuers file")
+
+	issuers, err := middleware.ParseTrustedIssuers(data)
+	panicOnError(err, "invalid trusted issuers file")
+
+	return issuers
+}
@@ -1,13 +1,42 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/lestrrat-go/jwx/jwa"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/lestrrat-go/jwx/jwt"
+	apiv1 "github.com/renato0307/learning-go-api/api/v1"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/apitesting"
+	"github.com/renato0307/learning-go-api/internal/mcp"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/openapi"
+	"github.com/renato0307/learning-go-api/internal/registry"
+	"github.com/renato0307/learning-go-api/internal/requestid"
+	"github.com/renato0307/learning-go-api/pkg/finance"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 func TestGetRequiredEnv(t *testing.T) {
@@ -33,14 +62,117 @@ func TestGetRequiredEnvWithMissingEnvironment(t *testing.T) {
 
 func TestNewAuthenticator(t *testing.T) {
 	// arrange
-	issuer, sampleJwks := setupFakeAuthServer()
+	issuer, _ := setupFakeAuthServer()
 
 	// act
 	config := newAuthenticatorConfig()
 
 	// assert
-	assert.Equal(t, []byte(sampleJwks), config.KeySetJSON)
-	assert.Equal(t, issuer, config.Issuer)
+	assert.Len(t, config.Issuers, 1)
+	assert.Equal(t, 2, config.Issuers[0].KeySet.KeySet().Len())
+	assert.Equal(t, issuer, config.Issuers[0].Issuer)
+	assert.True(t, config.Issuers[0].ClientCredentialsOnly)
+}
+
+func TestNewAuthenticatorWithUserTokens(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(AUTH_CLIENT_CREDENTIALS_ONLY, "false")
+	defer os.Unsetenv(AUTH_CLIENT_CREDENTIALS_ONLY)
+
+	// act
+	config := newAuthenticatorConfig()
+
+	// assert
+	assert.False(t, config.Issuers[0].ClientCredentialsOnly)
+}
+
+func TestNewAuthenticatorWithInvalidClientCredentialsOnly(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(AUTH_CLIENT_CREDENTIALS_ONLY, "maybe")
+	defer os.Unsetenv(AUTH_CLIENT_CREDENTIALS_ONLY)
+
+	// act & assert
+	assert.Panics(t, func() {
+		newAuthenticatorConfig()
+	})
+}
+
+func TestNewAuthenticatorWithIssuersFile(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	issuersFile := filepath.Join(t.TempDir(), "issuers.json")
+	issuers := fmt.Sprintf(`[
+		{"issuer": "https://cognito.example.com", "jwks_location": "%s", "token_use": "access"},
+		{"issuer": "https://keycloak.example.com", "jwks_location": "%s", "audiences": ["learning-go-api"]}
+	]`, os.Getenv(AUTH_JWKS_LOCATION), os.Getenv(AUTH_JWKS_LOCATION))
+	ioutil.WriteFile(issuersFile, []byte(issuers), 0600)
+	os.Setenv(AUTH_ISSUERS_FILE, issuersFile)
+	defer os.Unsetenv(AUTH_ISSUERS_FILE)
+
+	// act
+	config := newAuthenticatorConfig()
+
+	// assert
+	assert.Len(t, config.Issuers, 2)
+	assert.Equal(t, "https://cognito.example.com", config.Issuers[0].Issuer)
+	assert.Equal(t, "https://keycloak.example.com", config.Issuers[1].Issuer)
+	assert.Equal(t, []string{"learning-go-api"}, config.Issuers[1].Audiences)
+	assert.Equal(t, 2, config.Issuers[1].KeySet.KeySet().Len())
+}
+
+func TestNewAuthenticatorWithMissingIssuersFile(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(AUTH_ISSUERS_FILE, filepath.Join(t.TempDir(), "missing.json"))
+	defer os.Unsetenv(AUTH_ISSUERS_FILE)
+
+	// act & assert
+	assert.Panics(t, func() {
+		newAuthenticatorConfig()
+	})
+}
+
+func TestNewAuthenticatorWithInvalidRefreshInterval(t *testing.T) {
+	for _, interval := range []string{"invalid_interval", "0s", "-1m"} {
+		t.Run(interval, func(t *testing.T) {
+			// arrange
+			setupFakeAuthServer()
+			os.Setenv(AUTH_JWKS_REFRESH_INTERVAL, interval)
+			defer os.Unsetenv(AUTH_JWKS_REFRESH_INTERVAL)
+
+			// act & assert
+			assert.Panics(t, func() {
+				newAuthenticatorConfig()
+			})
+		})
+	}
+}
+
+func TestNewAuthenticatorWithDiscovery(t *testing.T) {
+	// arrange
+	issuer := setupFakeOidcServer("")
+
+	// act
+	config := newAuthenticatorConfig()
+
+	// assert
+	assert.Len(t, config.Issuers, 1)
+	assert.Equal(t, issuer, config.Issuers[0].Issuer)
+	assert.Equal(t, issuer+"/.well-known/jwks.json", config.Issuers[0].JwksLocation)
+	assert.Equal(t, []string{"RS256"}, config.Issuers[0].Algorithms)
+	assert.Equal(t, 2, config.Issuers[0].KeySet.KeySet().Len())
+}
+
+func TestNewAuthenticatorWithDiscoveryIssuerMismatch(t *testing.T) {
+	// arrange
+	setupFakeOidcServer("https://another-issuer.example.com")
+
+	// act & assert
+	assert.Panics(t, func() {
+		newAuthenticatorConfig()
+	})
 }
 
 func TestNewAuthenticatorWithInvalidTokenUrl(t *testing.T) {
@@ -55,24 +187,195 @@ func TestNewAuthenticatorWithInvalidTokenUrl(t *testing.T) {
 
 }
 
+func TestNewAuthenticatorWithTokenSources(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(AUTH_TOKEN_SOURCES, "bearer,query")
+	defer os.Unsetenv(AUTH_TOKEN_SOURCES)
+
+	// act
+	config := newAuthenticatorConfig()
+
+	// assert
+	assert.Equal(t,
+		[]middleware.TokenSource{middleware.TokenSourceBearer, middleware.TokenSourceQuery},
+		config.TokenSources)
+}
+
+func TestNewAuthenticatorWithInvalidTokenSources(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(AUTH_TOKEN_SOURCES, "cookie")
+	defer os.Unsetenv(AUTH_TOKEN_SOURCES)
+
+	// act & assert
+	assert.Panics(t, func() {
+		newAuthenticatorConfig()
+	})
+}
+
 func TestConfigureGin(t *testing.T) {
 	// arrange
 	setupFakeAuthServer()
-	os.Setenv(CURRCONV_API_KEY, "fake_key")
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
 
 	// act
-	r := configureGin()
+	r, _ := configureServers()
 
 	// assert
 	assert.NotNil(t, r)
-	assert.Len(t, r.RouterGroup.Handlers, 4)
+	assert.Len(t, r.RouterGroup.Handlers, 9)
+}
+
+func TestConfigureGinWithPolicyFile(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	policy := `{
+		"routes": [
+			{"method": "GET", "path": "/v1/finance/currconv", "scopes": ["finance-*"]}
+		]
+	}`
+	ioutil.WriteFile(policyFile, []byte(policy), 0600)
+	os.Setenv(AUTHZ_POLICY_FILE, policyFile)
+	defer os.Unsetenv(AUTHZ_POLICY_FILE)
+
+	// act
+	r, _ := configureServers()
+
+	// assert
+	assert.NotNil(t, r)
+}
+
+func TestConfigureGinWithInvalidPolicyFile(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	ioutil.WriteFile(policyFile, []byte("invalid json"), 0600)
+	os.Setenv(AUTHZ_POLICY_FILE, policyFile)
+	defer os.Unsetenv(AUTHZ_POLICY_FILE)
+
+	// act & assert
+	assert.Panics(t, func() {
+		configureServers()
+	})
+}
+
+func TestConfigureApiErrors(t *testing.T) {
+	// arrange
+	defer func() { apierror.IncludeMessage = true }()
+	os.Setenv(APIERROR_INCLUDE_MESSAGE, "false")
+	defer os.Unsetenv(APIERROR_INCLUDE_MESSAGE)
+
+	// act
+	configureApiErrors()
+
+	// assert
+	assert.False(t, apierror.IncludeMessage)
+}
+
+func TestConfigureApiErrorsWithInvalidValue(t *testing.T) {
+	// arrange
+	os.Setenv(APIERROR_INCLUDE_MESSAGE, "sometimes")
+	defer os.Unsetenv(APIERROR_INCLUDE_MESSAGE)
+
+	// act & assert
+	assert.Panics(t, func() { configureApiErrors() })
+}
+
+func TestGetUnknownRouteWithoutToken(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	r, _ := configureServers()
+
+	// act
+	w := apitesting.PerformRequest(r, "GET", "/v1/unknown")
+
+	// assert
+	apierror.AssertIsProblem(t, w, apierror.CodeUnauthorized)
+	assert.NotEmpty(t, w.Header().Get(requestid.Header))
+}
+
+func TestNewRateLimiterConfig(t *testing.T) {
+	// arrange
+	rateLimitFile := filepath.Join(t.TempDir(), "ratelimit.json")
+	rateLimits := `{
+		"default": {"requests": 60, "period": "1m"},
+		"rules": [{"group": "/v1/finance", "requests": 10, "period": "1m"}]
+	}`
+	ioutil.WriteFile(rateLimitFile, []byte(rateLimits), 0600)
+	os.Setenv(RATELIMIT_FILE, rateLimitFile)
+	defer os.Unsetenv(RATELIMIT_FILE)
+
+	// act
+	config := newRateLimiterConfig()
+
+	// assert
+	assert.Equal(t, 60, config.Default.Requests)
+	assert.Len(t, config.Rules, 1)
+}
+
+func TestNewRateLimiterConfigWithInvalidLimit(t *testing.T) {
+	// arrange
+	rateLimitFile := filepath.Join(t.TempDir(), "ratelimit.json")
+	ioutil.WriteFile(rateLimitFile, []byte(`{"default": {"requests": 0, "period": "1m"}}`), 0600)
+	os.Setenv(RATELIMIT_FILE, rateLimitFile)
+	defer os.Unsetenv(RATELIMIT_FILE)
+
+	// act & assert
+	assert.Panics(t, func() {
+		newRateLimiterConfig()
+	})
+}
+
+func TestNewRateLimiterConfigWithoutFile(t *testing.T) {
+	// act
+	config := newRateLimiterConfig()
+
+	// assert
+	assert.True(t, config.Default.Unlimited())
+	assert.Empty(t, config.Rules)
+}
+
+func TestNewQuotaConfig(t *testing.T) {
+	// arrange
+	quotaFile := filepath.Join(t.TempDir(), "quota.json")
+	quotas := `{"rules": [{"function": "/v1/finance/currconv", "monthly": 1000}]}`
+	ioutil.WriteFile(quotaFile, []byte(quotas), 0600)
+	os.Setenv(QUOTA_FILE, quotaFile)
+	defer os.Unsetenv(QUOTA_FILE)
+
+	// act
+	config := newQuotaConfig()
+
+	// assert
+	assert.Len(t, config.Rules, 1)
+	assert.Equal(t, uint64(1000), config.Find("client-a", "/v1/finance/currconv").Monthly)
+}
+
+func TestNewUsageStoreWithInvalidPath(t *testing.T) {
+	// arrange
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "missing", "usage.db"))
+
+	// act & assert
+	assert.Panics(t, func() {
+		newUsageStore()
+	})
 }
 
 func TestGetRoot(t *testing.T) {
 	// arrange
 	setupFakeAuthServer()
-	os.Setenv(CURRCONV_API_KEY, "fake_key")
-	r := configureGin()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	r, _ := configureServers()
 
 	w := httptest.NewRecorder()
 
@@ -87,24 +390,7 @@ func TestGetRoot(t *testing.T) {
 
 func setupFakeAuthServer() (string, string) {
 	issuer := "https://cognito-idp.$AWS_REGION.amazonaws.com/$POOL_ID"
-	sampleJwks := `
-	{
-		"keys": [{
-			"kid": "1234example=",
-			"alg": "RS256",
-			"kty": "RSA",
-			"e": "AQAB",
-			"n": "1234567890",
-			"use": "sig"
-		}, {
-			"kid": "5678example=",
-			"alg": "RS256",
-			"kty": "RSA",
-			"e": "AQAB",
-			"n": "987654321",
-			"use": "sig"
-		}]
-	}`
+	sampleJwks := newSampleJwks("1234example=", "5678example=")
 
 	svr := httptest.NewServer(
 		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -115,3 +401,504 @@ func setupFakeAuthServer() (string, string) {
 	os.Setenv(AUTH_JWKS_LOCATION, svr.URL)
 	return issuer, sampleJwks
 }
+
+// setupSigningAuthServer serves the JWKS of a new key, returning a function
+// signing access tokens with it for the client and scopes.
+func setupSigningAuthServer(t *testing.T) func(clientId string, scopes ...string) string {
+	raw, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := jwk.New(raw)
+	key.Set(jwk.KeyIDKey, "1234example=")
+	publicKey, _ := key.(jwk.RSAPrivateKey).PublicKey()
+	publicKey.Set(jwk.AlgorithmKey, "RS256")
+	set := jwk.NewSet()
+	set.Add(publicKey)
+	sampleJwks, _ := json.Marshal(set)
+
+	svr := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(sampleJwks)
+		}))
+	t.Cleanup(svr.Close)
+
+	issuer := "https://cognito-idp.$AWS_REGION.amazonaws.com/$POOL_ID"
+	os.Setenv(AUTH_TOKEN_ISS, issuer)
+	os.Setenv(AUTH_JWKS_LOCATION, svr.URL)
+
+	return func(clientId string, scopes ...string) string {
+		token := jwt.New()
+		token.Set("sub", clientId)
+		token.Set("client_id", clientId)
+		token.Set("token_use", "access")
+		token.Set("scope", strings.Join(scopes, " "))
+		token.Set("iss", issuer)
+		token.Set("exp", time.Now().Unix()+1000)
+
+		signed, err := jwt.Sign(token, jwa.RS256, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(signed)
+	}
+}
+
+// setupFakeOidcServer starts an OpenID provider serving its metadata and JWKS,
+// configuring only the issuer so the JWKS location is discovered. The
+// metadata issuer can be overridden to simulate a misconfigured provider.
+func setupFakeOidcServer(metadataIssuer string) string {
+	sampleJwks := newSampleJwks("1234example=", "5678example=")
+
+	mux := http.NewServeMux()
+	svr := httptest.NewServer(mux)
+	if metadataIssuer == "" {
+		metadataIssuer = svr.URL
+	}
+
+	mux.HandleFunc("/.well-known/openid-configuration",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+				"issuer": "%s",
+				"jwks_uri": "%s/.well-known/jwks.json",
+				"id_token_signing_alg_values_supported": ["RS256"]
+			}`, metadataIssuer, svr.URL)
+		})
+	mux.HandleFunc("/.well-known/jwks.json",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, sampleJwks)
+		})
+
+	os.Setenv(AUTH_TOKEN_ISS, svr.URL)
+	os.Unsetenv(AUTH_JWKS_LOCATION)
+	return svr.URL
+}
+
+// newSampleJwks generates a JSON Web Key Set with one RSA public key for each
+// of the key identifiers
+func newSampleJwks(kids ...string) string {
+	set := jwk.NewSet()
+	for _, kid := range kids {
+		raw, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(err)
+		}
+
+		key, _ := jwk.New(raw.PublicKey)
+		key.Set(jwk.KeyIDKey, kid)
+		key.Set(jwk.AlgorithmKey, "RS256")
+		key.Set(jwk.KeyUsageKey, "sig")
+		set.Add(key)
+	}
+
+	buf, _ := json.Marshal(set)
+	return string(buf)
+}
+
+func TestGetMetrics(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	os.Setenv(METRICS_ENABLED, "true")
+	defer os.Unsetenv(METRICS_ENABLED)
+	r, _ := configureServers()
+
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "learning_go_api_jwks_refreshes_total")
+}
+
+func TestGetMetricsDisabled(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	os.Unsetenv(METRICS_ENABLED)
+	r, _ := configureServers()
+
+	// act
+	w := apitesting.PerformRequest(r, "GET", metricsPath)
+
+	// assert - the path is not public, like any unknown path
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	apierror.AssertIsProblem(t, w, apierror.CodeUnauthorized)
+}
+
+func TestConfigureServersWithInvalidMetricsEnabled(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	os.Setenv(METRICS_ENABLED, "sometimes")
+	defer os.Unsetenv(METRICS_ENABLED)
+
+	// act & assert
+	assert.Panics(t, func() {
+		configureServers()
+	})
+}
+
+func TestGetOpenAPI(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	os.Setenv(OPENAPI_TOKEN_URL, "https://auth.example.com/oauth2/token")
+	defer os.Unsetenv(OPENAPI_TOKEN_URL)
+	r, _ := configureServers()
+
+	// act
+	w := apitesting.PerformRequest(r, "GET", openapiPath)
+
+	// assert - every route is in the served document
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	doc := openapi.Document{}
+	err := json.Unmarshal(w.Body.Bytes(), &doc)
+	assert.NoError(t, err)
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	for _, route := range r.Routes() {
+		specPath := regexp.MustCompile(`[:*](\w+)`).ReplaceAllString(route.Path, "{$1}")
+		assert.Contains(t, doc.Paths[specPath], strings.ToLower(route.Method),
+			"route %s %s missing from the OpenAPI document", route.Method, route.Path)
+	}
+
+	currconv := doc.Paths["/v1/finance/currconv"]["get"]
+	assert.Equal(t,
+		[]map[string][]string{{"oauth2": {middleware.DefaultScopePrefix + "finance-currconv"}}},
+		*currconv.Security)
+}
+
+func TestGetDocs(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	r, _ := configureServers()
+
+	// act
+	w := apitesting.PerformRequest(r, "GET", docsPath)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "swagger-ui")
+
+	// act & assert - the docs UI files are served without a token
+	w = apitesting.PerformRequest(r, "GET", docsAssetsPath+"/swagger-ui-bundle.js")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGetOpenAPIWithoutTokenUrl(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	r, _ := configureServers()
+
+	// act
+	w := apitesting.PerformRequest(r, "GET", openapiPath)
+
+	// assert - no OAuth flow pointing to a token URL the API does not serve
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	doc := openapi.Document{}
+	err := json.Unmarshal(w.Body.Bytes(), &doc)
+	assert.NoError(t, err)
+	assert.NotContains(t, doc.Components.SecuritySchemes, "oauth2")
+	assert.Equal(t, "bearer", doc.Components.SecuritySchemes["bearer"].Scheme)
+}
+
+func TestConfigureGinWithEnabledCategories(t *testing.T) {
+	// arrange - finance is disabled, so its API key is not required
+	setupFakeAuthServer()
+	os.Unsetenv(finance.CURRCONV_API_KEY)
+	os.Setenv(ENABLED_CATEGORIES, "programming")
+	defer os.Unsetenv(ENABLED_CATEGORIES)
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+
+	// act
+	r, _ := configureServers()
+
+	// assert
+	paths := []string{}
+	for _, route := range r.Routes() {
+		paths = append(paths, route.Path)
+	}
+	assert.Contains(t, paths, "/v1/programming/uuid")
+	assert.NotContains(t, paths, "/v1/finance/currconv")
+}
+
+func TestConfigureGinWithMissingCategoryConfig(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Unsetenv(finance.CURRCONV_API_KEY)
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+
+	// act & assert
+	assert.Panics(t, func() {
+		configureServers()
+	})
+}
+
+func TestConfigureGinWithUnknownCategory(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(ENABLED_CATEGORIES, "weather")
+	defer os.Unsetenv(ENABLED_CATEGORIES)
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+
+	// act & assert
+	assert.Panics(t, func() {
+		configureServers()
+	})
+}
+
+func TestGetHealth(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	r, _ := configureServers()
+
+	// act
+	w := apitesting.PerformRequest(r, "GET", healthPath)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	output := registry.HealthOutput{}
+	err := json.Unmarshal(w.Body.Bytes(), &output)
+	assert.NoError(t, err)
+	assert.Equal(t, registry.StatusOK, output.Status)
+	assert.Contains(t, output.Categories, "finance")
+	assert.Contains(t, output.Categories, "programming")
+}
+
+func TestPostMcpWithoutToken(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	r, _ := configureServers()
+
+	// act
+	w := apitesting.PerformRequest(r, "POST", mcp.Path)
+
+	// assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	apierror.AssertIsProblem(t, w, apierror.CodeUnauthorized)
+}
+
+func TestPostBatchWithoutToken(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	r, _ := configureServers()
+
+	// act
+	w := apitesting.PerformRequest(r, "POST", "/v1/batch")
+
+	// assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	apierror.AssertIsProblem(t, w, apierror.CodeUnauthorized)
+}
+
+func TestPostBatchWithQueryToken(t *testing.T) {
+	// arrange - the token is only in the query string, so the operations
+	// dispatched in process must be authenticated as the caller
+	sign := setupSigningAuthServer(t)
+	os.Setenv(AUTH_TOKEN_SOURCES, "query")
+	defer os.Unsetenv(AUTH_TOKEN_SOURCES)
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	r, _ := configureServers()
+
+	token := sign("client_id_1234567890", middleware.DefaultScopePrefix+"programming-uuid")
+	body := `{"operations":[{"method":"POST","path":"/v1/programming/uuid"}]}`
+	req, _ := http.NewRequest("POST", "/v1/batch?access_token="+token, strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	output := struct {
+		Results []struct {
+			Status int `json:"status"`
+		} `json:"results"`
+	}{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &output))
+	assert.Equal(t, 1, len(output.Results))
+	assert.Equal(t, http.StatusOK, output.Results[0].Status)
+}
+
+func TestServeMcpStdio(t *testing.T) {
+	// arrange
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	in := strings.NewReader(
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}` + "\n" +
+			`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"programming_uuid","arguments":{"no-hyphens":true}}}` + "\n")
+	out := new(bytes.Buffer)
+
+	// act
+	err := serveMcpStdio(context.Background(), in, out)
+
+	// assert
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"name":"finance_currconv"`)
+	assert.Contains(t, lines[0], `"name":"programming_jwt"`)
+	assert.Regexp(t, `"structuredContent":\{"uuid":"[0-9a-f]{32}"\}`, lines[1])
+	assert.Contains(t, lines[1], `"isError":false`)
+}
+
+func TestServeHttp(t *testing.T) {
+	// arrange - a slow request is in flight when the server is stopped
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	address := listener.Addr().String()
+	listener.Close()
+
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+		}
+		fmt.Fprint(w, "done")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- serveHttp(ctx, &http.Server{Addr: address, Handler: handler}, nil, "")
+	}()
+	for i := 0; i < 50; i++ {
+		if resp, err := http.Get("http://" + address + "/"); err == nil {
+			resp.Body.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	slow := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + address + "/slow")
+		if err != nil {
+			slow <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		slow <- string(body)
+	}()
+	<-started
+
+	// act
+	cancel()
+
+	// assert
+	assert.NoError(t, <-served)
+	assert.Equal(t, "done", <-slow)
+}
+
+func TestServeHttpWithInvalidAddress(t *testing.T) {
+	// act
+	err := serveHttp(context.Background(), &http.Server{Addr: "invalid address"}, nil, "")
+
+	// assert
+	assert.Error(t, err)
+}
+
+func TestConfigureServersGrpc(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	os.Setenv(GRPC_ADDRESS, ":9090")
+	defer os.Unsetenv(GRPC_ADDRESS)
+	_, grpcServer := configureServers()
+
+	listener := bufconn.Listen(1024 * 1024)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	// act
+	health, healthErr := grpc_health_v1.NewHealthClient(conn).
+		Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	_, convertErr := apiv1.NewFinanceServiceClient(conn).
+		ConvertCurrency(context.Background(), &apiv1.ConvertCurrencyRequest{From: "EUR", To: "USD"})
+
+	// assert
+	assert.NoError(t, healthErr)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, health.Status)
+	assert.Equal(t, codes.Unauthenticated, status.Code(convertErr))
+
+	services := grpcServer.GetServiceInfo()
+	assert.Contains(t, services, "learninggoapi.v1.FinanceService")
+	assert.Contains(t, services, "learninggoapi.v1.ProgrammingService")
+	assert.Contains(t, services, "grpc.reflection.v1alpha.ServerReflection")
+}
+
+func TestConfigureServersWithoutGrpc(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+	os.Unsetenv(GRPC_ADDRESS)
+
+	// act
+	r, grpcServer := configureServers()
+
+	// assert
+	assert.NotNil(t, r)
+	assert.Nil(t, grpcServer)
+}
+
+func TestConfigureServersWithEcbProvider(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Unsetenv(finance.CURRCONV_API_KEY)
+	defer os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(finance.CURRCONV_PROVIDER, finance.ProviderECB)
+	defer os.Unsetenv(finance.CURRCONV_PROVIDER)
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+
+	// act
+	r, _ := configureServers()
+
+	// assert
+	assert.NotNil(t, r)
+}
+
+func TestConfigureServersWithoutApiKey(t *testing.T) {
+	// arrange
+	setupFakeAuthServer()
+	os.Unsetenv(finance.CURRCONV_API_KEY)
+	defer os.Setenv(finance.CURRCONV_API_KEY, "fake_key")
+	os.Setenv(USAGE_DB_PATH, filepath.Join(t.TempDir(), "usage.db"))
+
+	// act & assert
+	assert.Panics(t, func() {
+		configureServers()
+	})
+}
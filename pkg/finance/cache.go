@@ -0,0 +1,167 @@
+package finance
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/renato0307/learning-go-lib/finance"
+)
+
+// DefaultCacheTTL is how long the exchange rates are cached by default
+const DefaultCacheTTL = time.Minute
+
+// Rate is an exchange rate with the time it is from
+type Rate struct {
+	Value     float64
+	Timestamp time.Time
+
+	// Cached is true if the rate was not looked up upstream for this call
+	Cached bool
+
+	// Provider names the provider of the rate, if known
+	Provider string
+
+	// Disagreement is true if other providers gave a different rate
+	Disagreement bool
+}
+
+// RateProvider is a finance.Interface telling the exchange rates it uses,
+// with their time.
+type RateProvider interface {
+	finance.Interface
+	Rate(from, to string) (Rate, error)
+}
+
+// Cache decorates a finance.Interface caching the exchange rate of each pair
+// of currencies for the TTL. Concurrent lookups of the same pair share a
+// single call upstream. With StaleOnError, expired rates are served while
+// the upstream fails.
+type Cache struct {
+	Next         finance.Interface
+	TTL          time.Duration
+	StaleOnError bool
+
+	mu    sync.Mutex
+	rates map[currencyPair]cachedRate
+	calls map[currencyPair]*rateCall
+	now   func() time.Time
+}
+
+// cachedRate is a rate with the time it was looked up, which sets when it
+// expires
+type cachedRate struct {
+	rate     Rate
+	lookedUp time.Time
+}
+
+// rateCall is a lookup in flight, shared by the concurrent lookups of a pair
+type rateCall struct {
+	done chan struct{}
+	rate Rate
+	err  error
+}
+
+// NewCache creates a new Cache in front of the next finance.Interface.
+func NewCache(next finance.Interface, ttl time.Duration, staleOnError bool) *Cache {
+	return &Cache{
+		Next:         next,
+		TTL:          ttl,
+		StaleOnError: staleOnError,
+		rates:        map[currencyPair]cachedRate{},
+		calls:        map[currencyPair]*rateCall{},
+		now:          time.Now,
+	}
+}
+
+// ConvertCurrency converts the amount with the cached rate of the currencies.
+func (c *Cache) ConvertCurrency(from string, to string, amount float64) (float64, error) {
+	rate, err := c.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return rate.Value * amount, nil
+}
+
+// Rate returns the cached rate of the currencies, looking it up if it is
+// missing or expired.
+func (c *Cache) Rate(from, to string) (Rate, error) {
+	pair := currencyPair{From: from, To: to}
+
+	c.mu.Lock()
+	cached, found := c.rates[pair]
+	if found && c.now().Sub(cached.lookedUp) < c.TTL {
+		c.mu.Unlock()
+		cached.rate.Cached = true
+		return cached.rate, nil
+	}
+
+	call, inFlight := c.calls[pair]
+	if inFlight {
+		c.mu.Unlock()
+		<-call.done
+		rate := call.rate
+		rate.Cached = true
+		return rate, call.err
+	}
+
+	call = &rateCall{done: make(chan struct{})}
+	c.calls[pair] = call
+	c.mu.Unlock()
+
+	c.lookup(pair, call)
+
+	return call.rate, call.err
+}
+
+// lookup gets the rate of the pair upstream for the call, falling back to the
+// expired rate if the upstream fails and StaleOnError is set. A panic upstream
+// fails the call, so the lookups waiting for it do not block forever.
+func (c *Cache) lookup(pair currencyPair, call *rateCall) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.rate = Rate{}
+			call.err = fmt.Errorf("error looking up the rate: %v", r)
+		}
+
+		c.mu.Lock()
+		delete(c.calls, pair)
+		c.mu.Unlock()
+		close(call.done)
+	}()
+
+	rate, err := lookupRate(c.Next, pair.From, pair.To)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err == nil {
+		c.rates[pair] = cachedRate{rate: rate, lookedUp: c.now()}
+		call.rate = rate
+		return
+	}
+
+	if stale, found := c.rates[pair]; found && c.StaleOnError {
+		call.rate = stale.rate
+		call.rate.Cached = true
+		return
+	}
+
+	call.err = err
+}
+
+// lookupRate gets the rate of the currencies from the provider, or by
+// converting a unit amount if it does not tell the time of its rates.
+func lookupRate(f finance.Interface, from, to string) (Rate, error) {
+	if provider, ok := f.(RateProvider); ok {
+		return provider.Rate(from, to)
+	}
+
+	value, err := f.ConvertCurrency(from, to, 1)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	return Rate{Value: value, Timestamp: time.Now()}, nil
+}
@@ -0,0 +1,242 @@
+package finance
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/renato0307/learning-go-api/internal/registry"
+	financelib "github.com/renato0307/learning-go-lib/finance"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRateProvider returns a rate from the day before
+type fakeRateProvider struct {
+	financelib.MockInterface
+}
+
+func (f *fakeRateProvider) Rate(from, to string) (Rate, error) {
+	return Rate{Value: 1.1, Timestamp: time.Date(2022, 2, 1, 16, 0, 0, 0, time.UTC)}, nil
+}
+
+// newTestCache creates a cache with a clock moved by the returned function
+func newTestCache(next financelib.Interface, staleOnError bool) (*Cache, func(time.Duration)) {
+	now := time.Date(2022, 2, 2, 10, 0, 0, 0, time.UTC)
+	cache := NewCache(next, time.Minute, staleOnError)
+	cache.now = func() time.Time { return now }
+
+	return cache, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestCacheRate(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil).Once()
+	cache, _ := newTestCache(&mockInterface, false)
+
+	// act
+	first, errFirst := cache.Rate("EUR", "USD")
+	second, errSecond := cache.Rate("EUR", "USD")
+
+	// assert
+	assert.NoError(t, errFirst)
+	assert.Equal(t, 1.1, first.Value)
+	assert.False(t, first.Cached)
+	assert.NoError(t, errSecond)
+	assert.Equal(t, 1.1, second.Value)
+	assert.True(t, second.Cached)
+	assert.Equal(t, first.Timestamp, second.Timestamp)
+	mockInterface.AssertExpectations(t)
+}
+
+func TestCacheRateExpired(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil).Once()
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.2, nil).Once()
+	cache, advance := newTestCache(&mockInterface, false)
+	cache.Rate("EUR", "USD")
+	advance(time.Minute)
+
+	// act
+	rate, err := cache.Rate("EUR", "USD")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1.2, rate.Value)
+	assert.False(t, rate.Cached)
+	mockInterface.AssertExpectations(t)
+}
+
+func TestCacheRateConcurrentLookups(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).
+		After(20*time.Millisecond).
+		Return(1.1, nil).
+		Once()
+	cache, _ := newTestCache(&mockInterface, false)
+
+	// act
+	wg := sync.WaitGroup{}
+	rates := make([]Rate, 10)
+	for i := range rates {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rates[i], _ = cache.Rate("EUR", "USD")
+		}(i)
+	}
+	wg.Wait()
+
+	// assert
+	for _, rate := range rates {
+		assert.Equal(t, 1.1, rate.Value)
+	}
+	mockInterface.AssertNumberOfCalls(t, "ConvertCurrency", 1)
+}
+
+func TestCacheRateWithUpstreamError(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		StaleOnError bool
+		Err          error
+		Value        float64
+	}{
+		{Name: "stale on error", StaleOnError: true, Value: 1.1},
+		{Name: "fail on error", StaleOnError: false, Err: errors.New("fake error")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			mockInterface := financelib.MockInterface{}
+			mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil).Once()
+			mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(0.0, errors.New("fake error")).Once()
+			cache, advance := newTestCache(&mockInterface, tc.StaleOnError)
+			cache.Rate("EUR", "USD")
+			advance(time.Hour)
+
+			// act
+			rate, err := cache.Rate("EUR", "USD")
+
+			// assert
+			assert.Equal(t, tc.Err, err)
+			assert.Equal(t, tc.Value, rate.Value)
+			assert.Equal(t, tc.StaleOnError, rate.Cached)
+			mockInterface.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCacheRateWithUpstreamPanic(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).
+		After(20 * time.Millisecond).
+		Panic("fake panic").
+		Once()
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil).Once()
+	cache, _ := newTestCache(&mockInterface, false)
+
+	// act
+	wg := sync.WaitGroup{}
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = cache.Rate("EUR", "USD")
+		}(i)
+	}
+	wg.Wait()
+	rate, err := cache.Rate("EUR", "USD")
+
+	// assert
+	for _, err := range errs {
+		assert.EqualError(t, err, "error looking up the rate: fake panic")
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, 1.1, rate.Value)
+	mockInterface.AssertExpectations(t)
+}
+
+func TestCacheRateWithRateProvider(t *testing.T) {
+	// arrange
+	cache, _ := newTestCache(&fakeRateProvider{}, false)
+
+	// act
+	rate, err := cache.Rate("EUR", "USD")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 2, 1, 16, 0, 0, 0, time.UTC), rate.Timestamp)
+}
+
+func TestCacheConvertCurrency(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.5, nil).Once()
+	cache, _ := newTestCache(&mockInterface, false)
+
+	// act
+	first, errFirst := cache.ConvertCurrency("EUR", "USD", 10)
+	second, errSecond := cache.ConvertCurrency("EUR", "USD", 2)
+
+	// assert
+	assert.NoError(t, errFirst)
+	assert.Equal(t, 15.0, first)
+	assert.NoError(t, errSecond)
+	assert.Equal(t, 3.0, second)
+	mockInterface.AssertExpectations(t)
+}
+
+func TestNewCacheConfig(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		Config       registry.Config
+		TTL          time.Duration
+		StaleOnError bool
+		Err          string
+	}{
+		{Name: "defaults", Config: registry.Config{}, TTL: DefaultCacheTTL},
+		{
+			Name:         "values",
+			Config:       registry.Config{CURRCONV_CACHE_TTL: "5m", CURRCONV_CACHE_STALE_ON_ERROR: "true"},
+			TTL:          5 * time.Minute,
+			StaleOnError: true,
+		},
+		{
+			Name:   "invalid ttl",
+			Config: registry.Config{CURRCONV_CACHE_TTL: "5 minutes"},
+			Err:    `invalid CURRCONV_CACHE_TTL value: "5 minutes"`,
+		},
+		{
+			Name:   "negative ttl",
+			Config: registry.Config{CURRCONV_CACHE_TTL: "-5m"},
+			Err:    `invalid CURRCONV_CACHE_TTL value: "-5m"`,
+		},
+		{
+			Name:   "invalid stale on error",
+			Config: registry.Config{CURRCONV_CACHE_STALE_ON_ERROR: "sometimes"},
+			Err:    `invalid CURRCONV_CACHE_STALE_ON_ERROR value: "sometimes"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// act
+			cache, err := newCacheConfig(tc.Config)
+
+			// assert
+			if tc.Err != "" {
+				assert.EqualError(t, err, tc.Err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.TTL, cache.TTL)
+			assert.Equal(t, tc.StaleOnError, cache.StaleOnError)
+		})
+	}
+}
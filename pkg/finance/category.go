@@ -0,0 +1,313 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apiv1 "github.com/renato0307/learning-go-api/api/v1"
+	"github.com/renato0307/learning-go-api/internal/registry"
+	"github.com/renato0307/learning-go-lib/finance"
+	"google.golang.org/grpc"
+)
+
+const (
+	// CURRCONV_PROVIDER lists the exchange rates providers, ProviderFcsapi by
+	// default or ProviderECB, separated by commas in failover order, as in
+	// "fcsapi,ecb"
+	CURRCONV_PROVIDER = "CURRCONV_PROVIDER"
+
+	// CURRCONV_FAILOVER_TIMEOUT is how long a provider has to answer before
+	// failing over to the next one, as in "2s". Defaults to
+	// DefaultFailoverTimeout.
+	CURRCONV_FAILOVER_TIMEOUT = "CURRCONV_FAILOVER_TIMEOUT"
+
+	// CURRCONV_CONSENSUS_TOLERANCE enables asking all the providers at once,
+	// flagging the rates differing by more than this fraction, as in "0.01"
+	// for 1%. Disabled by default.
+	CURRCONV_CONSENSUS_TOLERANCE = "CURRCONV_CONSENSUS_TOLERANCE"
+
+	// CURRCONV_API_KEY is the key of the currency converter API, required by
+	// the ProviderFcsapi provider
+	CURRCONV_API_KEY = "CURRCONV_API_KEY"
+
+	// CURRCONV_FCSAPI_URL overrides the URL of the ProviderFcsapi API, as in
+	// a local stand-in
+	CURRCONV_FCSAPI_URL = "CURRCONV_FCSAPI_URL"
+
+	// CURRCONV_ECB_FILE is the XML or CSV file with the ECB reference rates
+	// used by the ProviderECB provider. Defaults to an embedded snapshot.
+	CURRCONV_ECB_FILE = "CURRCONV_ECB_FILE"
+
+	// CURRCONV_CACHE_TTL is how long the exchange rates are cached, as in
+	// "5m". Defaults to DefaultCacheTTL.
+	CURRCONV_CACHE_TTL = "CURRCONV_CACHE_TTL"
+
+	// CURRCONV_CACHE_STALE_ON_ERROR enables serving expired rates while the
+	// currency converter fails. Defaults to false.
+	CURRCONV_CACHE_STALE_ON_ERROR = "CURRCONV_CACHE_STALE_ON_ERROR"
+
+	// CURRCONV_ROUNDING is how the converted amounts are rounded to the
+	// decimal places of their currency, RoundHalfEven by default, RoundHalfUp
+	// or RoundDown
+	CURRCONV_ROUNDING = "CURRCONV_ROUNDING"
+
+	// CURRCONV_HISTORY_DB_PATH is the bbolt file keeping the history of the
+	// exchange rates. Without it, the history is kept in memory.
+	CURRCONV_HISTORY_DB_PATH = "CURRCONV_HISTORY_DB_PATH"
+
+	// CURRCONV_HISTORY_FILES lists XML or CSV files with ECB reference rates
+	// imported into the history on startup, separated by commas
+	CURRCONV_HISTORY_FILES = "CURRCONV_HISTORY_FILES"
+
+	// upstream names the currency converter in the metrics
+	upstream = "fcsapi"
+)
+
+const (
+	// ProviderFcsapi converts currencies with the https://fcsapi.com/ API
+	ProviderFcsapi = upstream
+
+	// ProviderECB converts currencies offline with the ECB reference rates
+	ProviderECB = "ecb"
+)
+
+func init() {
+	registry.Register(registry.Category{
+		Name:        "finance",
+		Description: "Finance functions, like currency conversion",
+		OptionalEnv: []string{
+			CURRCONV_PROVIDER,
+			CURRCONV_FAILOVER_TIMEOUT,
+			CURRCONV_CONSENSUS_TOLERANCE,
+			CURRCONV_API_KEY,
+			CURRCONV_FCSAPI_URL,
+			CURRCONV_ECB_FILE,
+			CURRCONV_CACHE_TTL,
+			CURRCONV_CACHE_STALE_ON_ERROR,
+			CURRCONV_ROUNDING,
+			CURRCONV_HISTORY_DB_PATH,
+			CURRCONV_HISTORY_FILES,
+		},
+		Policies:    RoutePolicies,
+		Docs:        RouteDocs,
+		GrpcMethods: GrpcMethods,
+		Setup:       setup,
+	})
+}
+
+// setup creates the finance functions and defines their routes and gRPC
+// service. The exchange rates are cached in front of the provider, and
+// recorded into the history as they are looked up.
+func setup(
+	config registry.Config,
+	base *gin.RouterGroup,
+	services grpc.ServiceRegistrar) (*gin.RouterGroup, registry.HealthCheck, error) {
+
+	cache, err := newCacheConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rounding := RoundHalfEven
+	if value, found := config[CURRCONV_ROUNDING]; found {
+		rounding, err = ParseRoundingMode(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid %s value: %q", CURRCONV_ROUNDING, value)
+		}
+	}
+
+	history, err := newHistory(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider, healthCheck, err := newProviders(config, history)
+	if err != nil {
+		history.Close()
+		return nil, nil, err
+	}
+	cache.Next = NewRecorder(provider, history)
+
+	if services != nil {
+		apiv1.RegisterFinanceServiceServer(services, &grpcServer{f: cache, history: history, rounding: rounding})
+	}
+
+	return SetRouterGroup(cache, history, rounding, base), degraded(healthCheck), nil
+}
+
+// degraded reports the failures of the providers as degrading the category,
+// as the cached and historical rates are still served.
+func degraded(healthCheck registry.HealthCheck) registry.HealthCheck {
+	if healthCheck == nil {
+		return nil
+	}
+
+	return func(ctx context.Context) error {
+		return registry.Degraded(healthCheck(ctx))
+	}
+}
+
+// newHistory opens the store of the exchange rates history in the config,
+// importing the files listed in it.
+func newHistory(config registry.Config) (RateStore, error) {
+	var history RateStore = NewMemoryRateStore()
+	if path := config[CURRCONV_HISTORY_DB_PATH]; path != "" {
+		var err error
+		history, err = NewBoltRateStore(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open the exchange rates history: %s", err)
+		}
+	}
+
+	files := strings.FieldsFunc(config[CURRCONV_HISTORY_FILES], func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	for _, file := range files {
+		ecb, err := LoadECB(file)
+		if err == nil {
+			err = ImportECB(history, ecb)
+		}
+		if err != nil {
+			history.Close()
+			return nil, fmt.Errorf("invalid %s value: %s", CURRCONV_HISTORY_FILES, err)
+		}
+	}
+
+	return history, nil
+}
+
+// newProviders creates the exchange rates providers listed in the config,
+// failing over between them if there are several. The providers are healthy
+// while any of them is.
+func newProviders(config registry.Config, history RateStore) (finance.Interface, registry.HealthCheck, error) {
+	names := strings.FieldsFunc(config[CURRCONV_PROVIDER], func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	if len(names) == 0 {
+		names = []string{ProviderFcsapi}
+	}
+
+	providers := []Provider{}
+	healthChecks := []registry.HealthCheck{}
+	for _, name := range names {
+		provider, healthCheck, err := newProvider(name, config, history)
+		if err != nil {
+			return nil, nil, err
+		}
+		providers = append(providers, provider)
+		healthChecks = append(healthChecks, healthCheck)
+	}
+
+	if len(providers) == 1 {
+		return providers[0], healthChecks[0], nil
+	}
+
+	timeout := DefaultFailoverTimeout
+	if value, found := config[CURRCONV_FAILOVER_TIMEOUT]; found {
+		var err error
+		timeout, err = time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, nil, fmt.Errorf("invalid %s value: %q", CURRCONV_FAILOVER_TIMEOUT, value)
+		}
+	}
+
+	tolerance := 0.0
+	if value, found := config[CURRCONV_CONSENSUS_TOLERANCE]; found {
+		var err error
+		tolerance, err = strconv.ParseFloat(value, 64)
+		if err != nil || tolerance < 0 {
+			return nil, nil, fmt.Errorf("invalid %s value: %q", CURRCONV_CONSENSUS_TOLERANCE, value)
+		}
+	}
+
+	return NewFailover(providers, timeout, tolerance), anyHealthy(healthChecks), nil
+}
+
+// newProvider creates an exchange rates provider with its health check. The
+// online provider is unhealthy while most of its recent calls fail to reach
+// the upstream, while the offline one is always healthy and has its rates
+// imported into the history.
+func newProvider(
+	name string,
+	config registry.Config,
+	history RateStore) (Provider, registry.HealthCheck, error) {
+
+	switch name {
+	case ProviderFcsapi:
+		apiKey, found := config[CURRCONV_API_KEY]
+		if !found {
+			return Provider{}, nil, fmt.Errorf("the %s provider requires the %s environment variable",
+				ProviderFcsapi, CURRCONV_API_KEY)
+		}
+
+		f := finance.NewFinanceFunctions(config[CURRCONV_FCSAPI_URL], apiKey)
+		fi := NewInstrumented(&f, upstream)
+		return Provider{Name: name, Interface: fi}, fi.HealthCheck, nil
+
+	case ProviderECB:
+		ecb, err := LoadECB(config[CURRCONV_ECB_FILE])
+		if err != nil {
+			return Provider{}, nil, err
+		}
+		if err := ImportECB(history, ecb); err != nil {
+			return Provider{}, nil, err
+		}
+		return Provider{Name: name, Interface: ecb}, nil, nil
+
+	default:
+		return Provider{}, nil, fmt.Errorf("invalid %s value: unknown provider %q",
+			CURRCONV_PROVIDER, name)
+	}
+}
+
+// anyHealthy combines the health checks of the providers, failing only if
+// all of them fail. Nil checks are always healthy.
+func anyHealthy(healthChecks []registry.HealthCheck) registry.HealthCheck {
+	for _, healthCheck := range healthChecks {
+		if healthCheck == nil {
+			return nil
+		}
+	}
+
+	return func(ctx context.Context) error {
+		errs := []string{}
+		for _, healthCheck := range healthChecks {
+			err := healthCheck(ctx)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err.Error())
+		}
+
+		return errors.New(strings.Join(errs, "; "))
+	}
+}
+
+// newCacheConfig creates the cache of the exchange rates with the config,
+// without the decorated finance.Interface.
+func newCacheConfig(config registry.Config) (*Cache, error) {
+	ttl := DefaultCacheTTL
+	if value, found := config[CURRCONV_CACHE_TTL]; found {
+		var err error
+		ttl, err = time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			return nil, fmt.Errorf("invalid %s value: %q", CURRCONV_CACHE_TTL, value)
+		}
+	}
+
+	staleOnError := false
+	if value, found := config[CURRCONV_CACHE_STALE_ON_ERROR]; found {
+		var err error
+		staleOnError, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %q", CURRCONV_CACHE_STALE_ON_ERROR, value)
+		}
+	}
+
+	return NewCache(nil, ttl, staleOnError), nil
+}
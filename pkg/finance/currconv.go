@@ -1,80 +1,357 @@
 package finance
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/logging"
+	"github.com/renato0307/learning-go-api/internal/tracing"
 	"github.com/renato0307/learning-go-lib/finance"
-	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// getCurrConvOutput is a conversion, with the converted amount rounded to
+// the decimal places of its currency
 type getCurrConvOutput struct {
-	From            string  `json:"from"`
-	To              string  `json:"to"`
-	Amount          float64 `json:"amount"`
-	ConvertedAmount float64 `json:"converted_amount"`
+	From            string    `json:"from"`
+	To              string    `json:"to"`
+	Amount          Decimal   `json:"amount"`
+	ConvertedAmount Decimal   `json:"converted_amount"`
+	RateDate        string    `json:"rate_date"`
+	RateTimestamp   time.Time `json:"rate_timestamp"`
+	Cached          bool      `json:"cached"`
+	Provider        string    `json:"provider,omitempty"`
+	Disagreement    bool      `json:"disagreement,omitempty"`
 }
 
+// currConvListOutput holds the conversions of a list or of several target
+// currencies, in order
+type currConvListOutput struct {
+	Results []getCurrConvOutput `json:"results"`
+}
+
+// currConvItem is a conversion in the list of postCurrConvInput. The amount is
+// a pointer to tell a missing amount from zero.
+type currConvItem struct {
+	From   string   `json:"from"`
+	To     string   `json:"to"`
+	Amount *Decimal `json:"amount"`
+	Date   string   `json:"date,omitempty"`
+}
+
+type postCurrConvInput struct {
+	Items []currConvItem `json:"items"`
+}
+
+// maxCurrConvItems limits the conversions of one request, counting the
+// target currencies of a GET or the items of a POST
+const maxCurrConvItems = 100
+
 // getCurrConv handles the currency conversion request.
 //
 // The request requires the from, to and amount parameters in the query string.
+// The to parameter may list several currencies separated by commas, as in
+// "USD,GBP,JPY", returning the conversions in a currConvListOutput. The
+// optional date parameter converts with the historical rate of the day.
+// The currency codes may be in any case and are returned in uppercase.
+// The converted amounts are rounded with the rounding mode.
 // It returns HTTP 200 on success.
-// Returns HTTP 400 if there is a missing parameter.
+// Returns HTTP 400 listing the missing or invalid parameters, suggesting the
+// currencies meant by unknown codes.
+// Returns HTTP 404 if there is no historical rate for the date.
 // Returns HTTP 500 if there is another error.
-func getCurrConv(f finance.Interface) gin.HandlerFunc {
+func getCurrConv(f finance.Interface, history RateStore, rounding RoundingMode) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		from := c.Query("from")
 		to := c.Query("to")
 		amount := c.Query("amount")
+		date := c.Query("date")
 
-		log.Debug().
+		logger := logging.FromContext(c.Request.Context())
+		logger.Debug().
 			Str("from", from).
 			Str("to", to).
 			Str("amount", amount).
+			Str("date", date).
 			Msg("running currency converter")
 
-		if from == "" {
-			msg := "error: 'from' parameter is required"
-			c.JSON(http.StatusBadRequest, apierror.New(msg))
-			return
+		targets := strings.FieldsFunc(to, func(r rune) bool { return r == ',' })
+		fieldErrors := validateCurrConv(from, targets, amount != "")
+		if len(targets) > maxCurrConvItems {
+			msg := fmt.Sprintf("error: 'to' must have at most %d currencies", maxCurrConvItems)
+			fieldErrors = append(fieldErrors, apierror.InvalidField("to", msg))
 		}
-
-		if to == "" {
-			msg := "error: 'to' parameter is required"
-			c.JSON(http.StatusBadRequest, apierror.New(msg))
-			return
+		var amountDecimal Decimal
+		if amount != "" {
+			var err error
+			amountDecimal, err = ParseDecimal(amount)
+			if err != nil {
+				msg := "error: 'amount' is not a valid number"
+				fieldErrors = append(fieldErrors, apierror.InvalidField("amount", msg))
+			}
 		}
+		fieldErrors = append(fieldErrors, validateRateDate("date", date)...)
 
-		if amount == "" {
-			msg := "error: 'amount' parameter is required"
-			c.JSON(http.StatusBadRequest, apierror.New(msg))
+		if len(fieldErrors) > 0 {
+			apierror.Respond(c, apierror.Invalid(c, fieldErrors...))
 			return
 		}
 
-		amountFloat, err := strconv.ParseFloat(amount, 64)
-		if err != nil {
-			msg := "error: 'amount' is not a valid number"
-			c.JSON(http.StatusBadRequest, apierror.New(msg))
+		from = strings.ToUpper(from)
+		for i := range targets {
+			targets[i] = strings.ToUpper(targets[i])
+		}
+		if len(targets) > 1 {
+			pairs := []currencyPair{}
+			amounts := []Decimal{}
+			for _, target := range targets {
+				pairs = append(pairs, currencyPair{From: from, To: target, Date: date})
+				amounts = append(amounts, amountDecimal)
+			}
+			respondCurrConvList(c, f, history, rounding, pairs, amounts)
 			return
 		}
 
-		convertAmount, err := f.ConvertCurrency(from, to, amountFloat)
+		to = targets[0]
+		pair := currencyPair{From: from, To: to, Date: date}
+		rate, err := pairRate(c.Request.Context(), f, history, pair)
 		if err != nil {
-			msg := fmt.Sprintf("error converting the currency: %s", err.Error())
-			c.JSON(http.StatusInternalServerError, apierror.New(msg))
+			respondConversionError(c, err)
 			return
 		}
 
 		output := getCurrConvOutput{
 			From:            from,
 			To:              to,
-			Amount:          amountFloat,
-			ConvertedAmount: convertAmount,
+			Amount:          amountDecimal,
+			ConvertedAmount: convertAmount(amountDecimal, rate, to, rounding),
+			RateDate:        rate.Timestamp.UTC().Format(dateLayout),
+			RateTimestamp:   rate.Timestamp,
+			Cached:          rate.Cached,
+			Provider:        rate.Provider,
+			Disagreement:    rate.Disagreement,
 		}
 
 		c.JSON(http.StatusOK, output)
 	}
 }
+
+// postCurrConv handles the request converting a list of amounts.
+//
+// The request requires a JSON body with the items to convert, each with the
+// from, to and amount fields, and an optional date. Items converting between
+// the same currencies on the same date share a single rate lookup.
+// It returns HTTP 200 with the conversions in the order of the items.
+// Returns HTTP 400 if the body is not valid JSON, or listing the missing
+// fields of the items, as in "items[1].to".
+// Returns HTTP 404 if there is no historical rate for a date.
+// Returns HTTP 500 if there is another error.
+func postCurrConv(f finance.Interface, history RateStore, rounding RoundingMode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		input := postCurrConvInput{}
+		if err := json.NewDecoder(c.Request.Body).Decode(&input); err != nil {
+			msg := fmt.Sprintf("error: the body is not a valid list of conversions: %s", err.Error())
+			apierror.Respond(c, apierror.New(c, apierror.CodeInvalidBody, msg))
+			return
+		}
+
+		logger := logging.FromContext(c.Request.Context())
+		logger.Debug().
+			Int("items", len(input.Items)).
+			Msg("running currency converter for a list")
+
+		fieldErrors := []apierror.FieldError{}
+		if len(input.Items) == 0 {
+			msg := "error: 'items' must have at least one conversion"
+			fieldErrors = append(fieldErrors, apierror.InvalidField("items", msg))
+		}
+		if len(input.Items) > maxCurrConvItems {
+			msg := fmt.Sprintf("error: 'items' must have at most %d conversions", maxCurrConvItems)
+			fieldErrors = append(fieldErrors, apierror.InvalidField("items", msg))
+		}
+
+		pairs := []currencyPair{}
+		amounts := []Decimal{}
+		for i, item := range input.Items {
+			for _, fieldError := range validateCurrConv(item.From, singleTarget(item.To), item.Amount != nil) {
+				fieldError.Field = fmt.Sprintf("items[%d].%s", i, fieldError.Field)
+				fieldErrors = append(fieldErrors, fieldError)
+			}
+			dateField := fmt.Sprintf("items[%d].date", i)
+			fieldErrors = append(fieldErrors, validateRateDate(dateField, item.Date)...)
+			if item.Amount != nil {
+				pairs = append(pairs, currencyPair{
+					From: strings.ToUpper(item.From),
+					To:   strings.ToUpper(item.To),
+					Date: item.Date,
+				})
+				amounts = append(amounts, *item.Amount)
+			}
+		}
+
+		if len(fieldErrors) > 0 {
+			apierror.Respond(c, apierror.Invalid(c, fieldErrors...))
+			return
+		}
+
+		respondCurrConvList(c, f, history, rounding, pairs, amounts)
+	}
+}
+
+// respondCurrConvList converts the amounts between the currencies of their
+// pairs, looking up the rate of each distinct pair once.
+func respondCurrConvList(
+	c *gin.Context,
+	f finance.Interface,
+	history RateStore,
+	rounding RoundingMode,
+	pairs []currencyPair,
+	amounts []Decimal) {
+
+	rates, err := lookupRates(c.Request.Context(), f, history, pairs)
+	if err != nil {
+		respondConversionError(c, err)
+		return
+	}
+
+	output := currConvListOutput{Results: []getCurrConvOutput{}}
+	for i, pair := range pairs {
+		output.Results = append(output.Results, getCurrConvOutput{
+			From:            pair.From,
+			To:              pair.To,
+			Amount:          amounts[i],
+			ConvertedAmount: convertAmount(amounts[i], rates[pair], pair.To, rounding),
+			RateDate:        rates[pair].Timestamp.UTC().Format(dateLayout),
+			RateTimestamp:   rates[pair].Timestamp,
+			Cached:          rates[pair].Cached,
+			Provider:        rates[pair].Provider,
+			Disagreement:    rates[pair].Disagreement,
+		})
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// respondConversionError responds with the error of a conversion, telling a
+// missing historical rate from a failure of the provider.
+func respondConversionError(c *gin.Context, err error) {
+	if errors.Is(err, ErrRateNotFound) {
+		msg := fmt.Sprintf("error: %s", err.Error())
+		apierror.Respond(c, apierror.New(c, apierror.CodeRateNotFound, msg))
+		return
+	}
+
+	msg := fmt.Sprintf("error converting the currency: %s", err.Error())
+	apierror.Respond(c, apierror.New(c, apierror.CodeConversionFailed, msg))
+}
+
+// validateRateDate checks the optional date of the rate of a conversion,
+// which must be a day not in the future, as in "2022-02-04".
+func validateRateDate(field, date string) []apierror.FieldError {
+	if date == "" {
+		return nil
+	}
+
+	day, err := time.Parse(dateLayout, date)
+	if err != nil {
+		msg := fmt.Sprintf("error: '%s' is not a valid date, as in \"2022-02-04\"", field)
+		return []apierror.FieldError{apierror.InvalidField(field, msg)}
+	}
+	if day.After(time.Now().UTC()) {
+		msg := fmt.Sprintf("error: '%s' must not be in the future", field)
+		return []apierror.FieldError{apierror.InvalidField(field, msg)}
+	}
+
+	return nil
+}
+
+// validateCurrConv checks the parameters required by the currency conversion,
+// with ISO 4217 currency codes, converting to one or several targets.
+func validateCurrConv(from string, targets []string, hasAmount bool) []apierror.FieldError {
+	fieldErrors := []apierror.FieldError{}
+	if from == "" {
+		fieldErrors = append(fieldErrors, apierror.RequiredField("from"))
+	} else {
+		fieldErrors = append(fieldErrors, validateCurrency("from", from)...)
+	}
+
+	if len(targets) == 0 {
+		fieldErrors = append(fieldErrors, apierror.RequiredField("to"))
+	}
+	for _, target := range targets {
+		fieldErrors = append(fieldErrors, validateCurrency("to", target)...)
+	}
+
+	if !hasAmount {
+		fieldErrors = append(fieldErrors, apierror.RequiredField("amount"))
+	}
+
+	return fieldErrors
+}
+
+// singleTarget lists the to parameter as the only target of a conversion, or
+// none if it is empty.
+func singleTarget(to string) []string {
+	if to == "" {
+		return nil
+	}
+
+	return []string{to}
+}
+
+// convertAmount converts the amount with the rate, exactly, rounding the
+// result to the decimal places of the target currency.
+func convertAmount(amount Decimal, rate Rate, to string, rounding RoundingMode) Decimal {
+	return RoundMoney(amount.Mul(NewDecimalFromFloat(rate.Value)), to, rounding)
+}
+
+// pairRate gets the historical rate of the pair if it has a date, or the
+// current one otherwise.
+func pairRate(
+	ctx context.Context,
+	f finance.Interface,
+	history RateStore,
+	pair currencyPair) (Rate, error) {
+
+	if pair.Date == "" {
+		return currentRate(ctx, f, pair.From, pair.To)
+	}
+
+	date, err := time.Parse(dateLayout, pair.Date)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	return rateOn(history, pair.From, pair.To, date)
+}
+
+// currentRate gets the current rate from the currency converter in a client
+// span. The rate has its time if the converter is a RateProvider, or the
+// current time otherwise.
+func currentRate(ctx context.Context, f finance.Interface, from, to string) (Rate, error) {
+	_, span := tracing.Tracer.Start(ctx, "finance.ConvertCurrency",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("from", from),
+			attribute.String("to", to)))
+	defer span.End()
+
+	rate, err := lookupRate(f, from, to)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return Rate{}, err
+	}
+	span.SetAttributes(attribute.Bool("cached", rate.Cached))
+
+	return rate, nil
+}
@@ -6,28 +6,43 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/renato0307/learning-go-api/internal/apierror"
 	financelib "github.com/renato0307/learning-go-lib/finance"
 	"github.com/stretchr/testify/assert"
 )
 
+// conversionsOf formats the conversions to compare them, as in
+// "10 EUR = 11.00 USD"
+func conversionsOf(results []getCurrConvOutput) []string {
+	conversions := []string{}
+	for _, result := range results {
+		conversions = append(conversions, fmt.Sprintf("%s %s = %s %s",
+			result.Amount, result.From, result.ConvertedAmount, result.To))
+	}
+
+	return conversions
+}
+
 func TestGetCurrConv(t *testing.T) {
 	// arrange
 	from := "EUR"
 	to := "USD"
-	amount := 10.0
-	amountConverted := 11.0
+	amount := "10"
+	amountConverted := "11.00"
 
 	mockInterface := financelib.MockInterface{}
-	mockCall := mockInterface.On("ConvertCurrency", from, to, amount)
-	mockCall.Return(amountConverted, nil)
+	mockCall := mockInterface.On("ConvertCurrency", from, to, 1.0)
+	mockCall.Return(1.1, nil)
 
 	r := setupGin(&mockInterface)
 	w := httptest.NewRecorder()
 
-	url := fmt.Sprintf("/v1/finance/currconv?from=%s&to=%s&amount=%f", from, to, amount)
+	url := fmt.Sprintf("/v1/finance/currconv?from=%s&to=%s&amount=%s", from, to, amount)
 	req, _ := http.NewRequest("GET", url, nil)
 
 	// act
@@ -42,8 +57,8 @@ func TestGetCurrConv(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, from, output.From)
 	assert.Equal(t, to, output.To)
-	assert.Equal(t, amount, output.Amount)
-	assert.Equal(t, amountConverted, output.ConvertedAmount)
+	assert.Equal(t, amount, output.Amount.String())
+	assert.Equal(t, amountConverted, output.ConvertedAmount.String())
 
 	mockInterface.AssertExpectations(t)
 }
@@ -65,7 +80,8 @@ func TestGetCurrConvWithMissingFrom(t *testing.T) {
 
 	// assert
 	assert.Equal(t, w.Code, http.StatusBadRequest)
-	apierror.AssertIsValid(t, w.Body.Bytes())
+	apierror.AssertIsProblem(t, w, apierror.CodeInvalidParameters)
+	apierror.AssertHasFieldError(t, w.Body.Bytes(), "from")
 }
 
 func TestGetCurrConvWithMissingTo(t *testing.T) {
@@ -85,7 +101,8 @@ func TestGetCurrConvWithMissingTo(t *testing.T) {
 
 	// assert
 	assert.Equal(t, w.Code, http.StatusBadRequest)
-	apierror.AssertIsValid(t, w.Body.Bytes())
+	apierror.AssertIsProblem(t, w, apierror.CodeInvalidParameters)
+	apierror.AssertHasFieldError(t, w.Body.Bytes(), "to")
 }
 
 func TestGetCurrConvWithMissingAmount(t *testing.T) {
@@ -105,7 +122,8 @@ func TestGetCurrConvWithMissingAmount(t *testing.T) {
 
 	// assert
 	assert.Equal(t, w.Code, http.StatusBadRequest)
-	apierror.AssertIsValid(t, w.Body.Bytes())
+	apierror.AssertIsProblem(t, w, apierror.CodeInvalidParameters)
+	apierror.AssertHasFieldError(t, w.Body.Bytes(), "amount")
 }
 
 func TestGetCurrConvWithInvalidAmount(t *testing.T) {
@@ -126,7 +144,26 @@ func TestGetCurrConvWithInvalidAmount(t *testing.T) {
 
 	// assert
 	assert.Equal(t, w.Code, http.StatusBadRequest)
-	apierror.AssertIsValid(t, w.Body.Bytes())
+	apierror.AssertIsProblem(t, w, apierror.CodeInvalidParameters)
+	apierror.AssertHasFieldError(t, w.Body.Bytes(), "amount")
+}
+
+func TestGetCurrConvWithAllParametersMissing(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	r := setupGin(&mockInterface)
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest("GET", "/v1/finance/currconv", nil)
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	apierror.AssertIsProblem(t, w, apierror.CodeInvalidParameters)
+	apierror.AssertHasFieldError(t, w.Body.Bytes(), "from")
+	apierror.AssertHasFieldError(t, w.Body.Bytes(), "to")
+	apierror.AssertHasFieldError(t, w.Body.Bytes(), "amount")
 }
 
 func TestGetCurrConvWithLibraryError(t *testing.T) {
@@ -136,7 +173,7 @@ func TestGetCurrConvWithLibraryError(t *testing.T) {
 	amount := 10.0
 
 	mockInterface := financelib.MockInterface{}
-	mockCall := mockInterface.On("ConvertCurrency", from, to, amount)
+	mockCall := mockInterface.On("ConvertCurrency", from, to, 1.0)
 	mockCall.Return(0.0, errors.New("fake error"))
 
 	r := setupGin(&mockInterface)
@@ -150,6 +187,444 @@ func TestGetCurrConvWithLibraryError(t *testing.T) {
 
 	// assert
 	assert.Equal(t, w.Code, http.StatusInternalServerError)
-	apierror.AssertIsValid(t, w.Body.Bytes())
+	apierror.AssertIsProblem(t, w, apierror.CodeConversionFailed)
+	mockInterface.AssertExpectations(t)
+}
+
+func TestGetCurrConvWithSeveralTargets(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil).Once()
+	mockInterface.On("ConvertCurrency", "EUR", "JPY", 1.0).Return(130.0, nil).Once()
+
+	r := setupGin(&mockInterface)
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest("GET", "/v1/finance/currconv?from=EUR&to=USD,JPY,USD&amount=10", nil)
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	output := currConvListOutput{}
+	err := json.Unmarshal(w.Body.Bytes(), &output)
+
+	assert.Nil(t, err)
+	for i := range output.Results {
+		assert.False(t, output.Results[i].RateTimestamp.IsZero())
+		assert.Equal(t, output.Results[i].RateTimestamp.UTC().Format(dateLayout), output.Results[i].RateDate)
+		output.Results[i].RateTimestamp = time.Time{}
+		output.Results[i].RateDate = ""
+	}
+	assert.Equal(t, []string{
+		"10 EUR = 11.00 USD",
+		"10 EUR = 1300 JPY",
+		"10 EUR = 11.00 USD",
+	}, conversionsOf(output.Results))
+	mockInterface.AssertExpectations(t)
+}
+
+func TestGetCurrConvWithTrailingComma(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil)
+
+	r := setupGin(&mockInterface)
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest("GET", "/v1/finance/currconv?from=EUR&to=USD,&amount=10", nil)
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	output := getCurrConvOutput{}
+	err := json.Unmarshal(w.Body.Bytes(), &output)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "USD", output.To)
+	assert.Equal(t, "11.00", output.ConvertedAmount.String())
 	mockInterface.AssertExpectations(t)
 }
+
+func TestGetCurrConvWithLowercaseCurrencies(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil)
+
+	r := setupGin(&mockInterface)
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest("GET", "/v1/finance/currconv?from=eur&to=Usd&amount=10", nil)
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	output := getCurrConvOutput{}
+	err := json.Unmarshal(w.Body.Bytes(), &output)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "EUR", output.From)
+	assert.Equal(t, "USD", output.To)
+	mockInterface.AssertExpectations(t)
+}
+
+func TestGetCurrConvWithSeveralLowercaseTargets(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil).Once()
+
+	r := setupGin(&mockInterface)
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest("GET", "/v1/finance/currconv?from=eur&to=usd,USD&amount=10", nil)
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	output := currConvListOutput{}
+	err := json.Unmarshal(w.Body.Bytes(), &output)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"10 EUR = 11.00 USD",
+		"10 EUR = 11.00 USD",
+	}, conversionsOf(output.Results))
+	mockInterface.AssertExpectations(t)
+}
+
+func TestPostCurrConv(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.5, nil).Once()
+	mockInterface.On("ConvertCurrency", "GBP", "USD", 1.0).Return(2.0, nil).Once()
+
+	r := setupGin(&mockInterface)
+	w := httptest.NewRecorder()
+
+	body := `{"items":[
+		{"from":"EUR","to":"USD","amount":10},
+		{"from":"GBP","to":"USD","amount":5},
+		{"from":"EUR","to":"USD","amount":0}
+	]}`
+	req, _ := http.NewRequest("POST", "/v1/finance/currconv", strings.NewReader(body))
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	output := currConvListOutput{}
+	err := json.Unmarshal(w.Body.Bytes(), &output)
+
+	assert.Nil(t, err)
+	for i := range output.Results {
+		assert.False(t, output.Results[i].RateTimestamp.IsZero())
+		assert.Equal(t, output.Results[i].RateTimestamp.UTC().Format(dateLayout), output.Results[i].RateDate)
+		output.Results[i].RateTimestamp = time.Time{}
+		output.Results[i].RateDate = ""
+	}
+	assert.Equal(t, []string{
+		"10 EUR = 15.00 USD",
+		"5 GBP = 10.00 USD",
+		"0 EUR = 0.00 USD",
+	}, conversionsOf(output.Results))
+	mockInterface.AssertExpectations(t)
+}
+
+func TestPostCurrConvWithLowercaseCurrencies(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.5, nil).Once()
+
+	r := setupGin(&mockInterface)
+	w := httptest.NewRecorder()
+
+	body := `{"items":[
+		{"from":"eur","to":"usd","amount":10},
+		{"from":"EUR","to":"USD","amount":5}
+	]}`
+	req, _ := http.NewRequest("POST", "/v1/finance/currconv", strings.NewReader(body))
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	output := currConvListOutput{}
+	err := json.Unmarshal(w.Body.Bytes(), &output)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"10 EUR = 15.00 USD",
+		"5 EUR = 7.50 USD",
+	}, conversionsOf(output.Results))
+	mockInterface.AssertExpectations(t)
+}
+
+func TestPostCurrConvWithInvalidInput(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Body   string
+		Code   apierror.Code
+		Fields []string
+	}{
+		{Name: "not json", Body: `{"items":`, Code: apierror.CodeInvalidBody},
+		{Name: "no items", Body: `{"items":[]}`, Code: apierror.CodeInvalidParameters, Fields: []string{"items"}},
+		{
+			Name:   "missing fields",
+			Body:   `{"items":[{"from":"EUR","to":"USD","amount":1},{"from":"EUR"}]}`,
+			Code:   apierror.CodeInvalidParameters,
+			Fields: []string{"items[1].to", "items[1].amount"},
+		},
+		{
+			Name:   "several targets",
+			Body:   `{"items":[{"from":"EUR","to":"USD,GBP","amount":1}]}`,
+			Code:   apierror.CodeInvalidParameters,
+			Fields: []string{"items[0].to"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			mockInterface := financelib.MockInterface{}
+			r := setupGin(&mockInterface)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/v1/finance/currconv", strings.NewReader(tc.Body))
+
+			// act
+			r.ServeHTTP(w, req)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			apierror.AssertIsProblem(t, w, tc.Code)
+			for _, field := range tc.Fields {
+				apierror.AssertHasFieldError(t, w.Body.Bytes(), field)
+			}
+			mockInterface.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostCurrConvWithLibraryError(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "CHF", 1.0).Return(0.0, errors.New("fake error"))
+
+	r := setupGin(&mockInterface)
+	w := httptest.NewRecorder()
+
+	body := `{"items":[{"from":"EUR","to":"CHF","amount":10}]}`
+	req, _ := http.NewRequest("POST", "/v1/finance/currconv", strings.NewReader(body))
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	apierror.AssertIsProblem(t, w, apierror.CodeConversionFailed)
+	mockInterface.AssertExpectations(t)
+}
+
+func TestGetCurrConvWithCache(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil).Once()
+
+	r := setupGin(NewCache(&mockInterface, time.Minute, false))
+	url := "/v1/finance/currconv?from=EUR&to=USD&amount=10"
+
+	// act
+	outputs := []getCurrConvOutput{}
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", url, nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		output := getCurrConvOutput{}
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &output))
+		outputs = append(outputs, output)
+	}
+
+	// assert
+	assert.False(t, outputs[0].Cached)
+	assert.True(t, outputs[1].Cached)
+	assert.Equal(t, outputs[0].RateTimestamp, outputs[1].RateTimestamp)
+	assert.Equal(t, "11.00", outputs[1].ConvertedAmount.String())
+	mockInterface.AssertExpectations(t)
+}
+
+func newTestHistory(t *testing.T) RateStore {
+	history := NewMemoryRateStore()
+	friday := time.Date(2022, 2, 4, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, history.Save(friday, "EUR", map[string]float64{"USD": 1.1, "JPY": 130}))
+
+	return history
+}
+
+func TestGetCurrConvWithDate(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		Query      string
+		StatusCode int
+		Code       apierror.Code
+		Conversion string
+		RateDate   string
+	}{
+		{
+			Name:       "day with rates",
+			Query:      "from=EUR&to=USD&amount=10&date=2022-02-04",
+			StatusCode: http.StatusOK,
+			Conversion: "10 EUR = 11.00 USD",
+			RateDate:   "2022-02-04",
+		},
+		{
+			Name:       "weekend",
+			Query:      "from=USD&to=JPY&amount=1.1&date=2022-02-06",
+			StatusCode: http.StatusOK,
+			Conversion: "1.1 USD = 130 JPY",
+			RateDate:   "2022-02-04",
+		},
+		{
+			Name:       "too long before",
+			Query:      "from=EUR&to=USD&amount=10&date=2022-02-14",
+			StatusCode: http.StatusNotFound,
+			Code:       apierror.CodeRateNotFound,
+		},
+		{
+			Name:       "invalid date",
+			Query:      "from=EUR&to=USD&amount=10&date=04/02/2022",
+			StatusCode: http.StatusBadRequest,
+			Code:       apierror.CodeInvalidParameters,
+		},
+		{
+			Name:       "future date",
+			Query:      "from=EUR&to=USD&amount=10&date=" + time.Now().AddDate(0, 0, 2).Format(dateLayout),
+			StatusCode: http.StatusBadRequest,
+			Code:       apierror.CodeInvalidParameters,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			mockInterface := financelib.MockInterface{}
+			r := setupGinWithHistory(&mockInterface, newTestHistory(t))
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/v1/finance/currconv?"+tc.Query, nil)
+
+			// act
+			r.ServeHTTP(w, req)
+
+			// assert
+			assert.Equal(t, tc.StatusCode, w.Code)
+			mockInterface.AssertNotCalled(t, "ConvertCurrency")
+			if tc.Code != "" {
+				apierror.AssertIsProblem(t, w, tc.Code)
+				return
+			}
+
+			output := getCurrConvOutput{}
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &output))
+			assert.True(t, output.Cached)
+			assert.Equal(t, []string{tc.Conversion}, conversionsOf([]getCurrConvOutput{output}))
+			assert.Equal(t, tc.RateDate, output.RateDate)
+		})
+	}
+}
+
+func TestPostCurrConvWithDate(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.5, nil).Once()
+
+	r := setupGinWithHistory(&mockInterface, newTestHistory(t))
+	w := httptest.NewRecorder()
+
+	body := `{"items":[
+		{"from":"EUR","to":"USD","amount":10,"date":"2022-02-05"},
+		{"from":"EUR","to":"USD","amount":10}
+	]}`
+	req, _ := http.NewRequest("POST", "/v1/finance/currconv", strings.NewReader(body))
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	output := currConvListOutput{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &output))
+	assert.Len(t, output.Results, 2)
+	assert.Equal(t, []string{"10 EUR = 11.00 USD", "10 EUR = 15.00 USD"}, conversionsOf(output.Results))
+	assert.Equal(t, "2022-02-04", output.Results[0].RateDate)
+	mockInterface.AssertExpectations(t)
+}
+
+func TestPostCurrConvWithInvalidDate(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	r := setupGin(&mockInterface)
+	w := httptest.NewRecorder()
+
+	body := `{"items":[{"from":"EUR","to":"USD","amount":10,"date":"yesterday"}]}`
+	req, _ := http.NewRequest("POST", "/v1/finance/currconv", strings.NewReader(body))
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "items[0].date")
+	mockInterface.AssertNotCalled(t, "ConvertCurrency")
+}
+
+func TestGetCurrConvRounding(t *testing.T) {
+	testCases := []struct {
+		Mode      RoundingMode
+		To        string
+		Rate      float64
+		Converted string
+	}{
+		{Mode: RoundHalfEven, To: "USD", Rate: 1.0025, Converted: "10.02"},
+		{Mode: RoundHalfUp, To: "USD", Rate: 1.0025, Converted: "10.03"},
+		{Mode: RoundDown, To: "USD", Rate: 1.0029, Converted: "10.02"},
+		{Mode: RoundHalfEven, To: "JPY", Rate: 130.05, Converted: "1300"},
+		{Mode: RoundHalfUp, To: "JPY", Rate: 130.05, Converted: "1301"},
+		{Mode: RoundHalfUp, To: "KWD", Rate: 0.33335, Converted: "3.334"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.Mode)+" "+tc.To, func(t *testing.T) {
+			// arrange
+			mockInterface := financelib.MockInterface{}
+			mockInterface.On("ConvertCurrency", "EUR", tc.To, 1.0).Return(tc.Rate, nil)
+
+			r := gin.New()
+			SetRouterGroup(&mockInterface, NewMemoryRateStore(), tc.Mode, r.Group("/v1"))
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/v1/finance/currconv?from=EUR&to="+tc.To+"&amount=10", nil)
+
+			// act
+			r.ServeHTTP(w, req)
+
+			// assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), `"converted_amount":"`+tc.Converted+`"`)
+		})
+	}
+}
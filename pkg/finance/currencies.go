@@ -0,0 +1,156 @@
+package finance
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+)
+
+// maxCurrencySuggestions limits the currencies suggested for an unknown code
+const maxCurrencySuggestions = 3
+
+// maxSuggestionDistance is how many letters an unknown code may have added,
+// removed, changed or swapped to suggest a currency, as in "EURO" for "EUR"
+const maxSuggestionDistance = 1
+
+// Currency is an ISO 4217 currency
+type Currency struct {
+	Code       string `json:"code"`
+	Numeric    string `json:"numeric_code"`
+	Name       string `json:"name"`
+	MinorUnits int32  `json:"minor_units"`
+	Symbol     string `json:"symbol,omitempty"`
+
+	// Withdrawn is the day the currency was replaced, as in "2023-01-01",
+	// for currencies only found in historical rates
+	Withdrawn string `json:"withdrawn,omitempty"`
+}
+
+type getCurrenciesOutput struct {
+	Currencies []Currency `json:"currencies"`
+}
+
+// currenciesByCode indexes the currencyCatalog
+var currenciesByCode = func() map[string]Currency {
+	byCode := map[string]Currency{}
+	for _, currency := range currencyCatalog {
+		byCode[currency.Code] = currency
+	}
+
+	return byCode
+}()
+
+// LookupCurrency finds the ISO 4217 currency of the code, in any case.
+func LookupCurrency(code string) (Currency, bool) {
+	currency, found := currenciesByCode[strings.ToUpper(code)]
+	return currency, found
+}
+
+// getCurrencies handles the request listing the currencies.
+//
+// It returns HTTP 200 with the ISO 4217 currencies, sorted by code.
+func getCurrencies() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, getCurrenciesOutput{Currencies: currencyCatalog})
+	}
+}
+
+// validateCurrency checks the currency code in the field is an ISO 4217
+// one, suggesting the closest codes if it is not.
+func validateCurrency(field, code string) []apierror.FieldError {
+	if _, found := LookupCurrency(code); found {
+		return nil
+	}
+
+	detail := fmt.Sprintf("error: '%s' is not an ISO 4217 currency code", field)
+	suggestions := suggestCurrencies(code)
+	if len(suggestions) > 0 {
+		detail = fmt.Sprintf("%s, did you mean %s?", detail, strings.Join(suggestions, " or "))
+	}
+
+	fieldError := apierror.InvalidField(field, detail)
+	fieldError.Suggestions = suggestions
+	return []apierror.FieldError{fieldError}
+}
+
+// suggestCurrencies finds the currencies an unknown code was meant to be,
+// by their name, as in "yen", or else by the codes closest to it.
+func suggestCurrencies(code string) []string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	suggestions := []string{}
+	for _, currency := range currencyCatalog {
+		if strings.ToUpper(currency.Name) == code {
+			suggestions = append(suggestions, currency.Code)
+		}
+	}
+	if len(suggestions) > 0 {
+		return suggestions
+	}
+
+	best := maxSuggestionDistance + 1
+	for _, currency := range currencyCatalog {
+		distance := editDistance(code, currency.Code)
+		switch {
+		case distance < best:
+			best, suggestions = distance, []string{currency.Code}
+		case distance == best && best <= maxSuggestionDistance:
+			suggestions = append(suggestions, currency.Code)
+		}
+	}
+
+	if len(suggestions) > maxCurrencySuggestions {
+		suggestions = suggestions[:maxCurrencySuggestions]
+	}
+
+	return suggestions
+}
+
+// editDistance counts the letters added, removed, changed or swapped with
+// the next one to turn a into b, as in the optimal string alignment
+// distance.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// d[i][j] is the distance between the first i letters of a and the
+	// first j letters of b
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+// minInt returns the smallest of the values.
+func minInt(first int, others ...int) int {
+	smallest := first
+	for _, value := range others {
+		if value < smallest {
+			smallest = value
+		}
+	}
+
+	return smallest
+}
@@ -0,0 +1,157 @@
+package finance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	financelib "github.com/renato0307/learning-go-lib/finance"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCurrencies(t *testing.T) {
+	// arrange
+	r := setupGin(&financelib.MockInterface{})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/finance/currencies", nil)
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	output := getCurrenciesOutput{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &output))
+	assert.Equal(t, len(currencyCatalog), len(output.Currencies))
+	assert.Contains(t, output.Currencies,
+		Currency{Code: "EUR", Numeric: "978", Name: "Euro", MinorUnits: 2, Symbol: "€"})
+	assert.True(t, sort.SliceIsSorted(output.Currencies, func(i, j int) bool {
+		return output.Currencies[i].Code < output.Currencies[j].Code
+	}))
+}
+
+func TestCurrencyCatalog(t *testing.T) {
+	numerics := map[string]string{}
+	for _, currency := range currencyCatalog {
+		assert.Len(t, currency.Code, 3, currency.Code)
+		assert.Len(t, currency.Numeric, 3, currency.Code)
+		assert.NotEmpty(t, currency.Name, currency.Code)
+		if other, found := numerics[currency.Numeric]; found {
+			t.Errorf("%s and %s have the same numeric code %s", other, currency.Code, currency.Numeric)
+		}
+		numerics[currency.Numeric] = currency.Code
+	}
+
+	for _, code := range []string{"USD", "JPY", "GBP", "CHF", "HRK", "RUB", "ZAR"} {
+		_, found := LookupCurrency(code)
+		assert.True(t, found, code)
+	}
+}
+
+func TestLookupCurrency(t *testing.T) {
+	// act
+	currency, found := LookupCurrency("kwd")
+	_, foundUnknown := LookupCurrency("XXX")
+
+	// assert
+	assert.True(t, found)
+	assert.Equal(t, "Kuwaiti Dinar", currency.Name)
+	assert.Equal(t, int32(3), currency.MinorUnits)
+	assert.False(t, foundUnknown)
+}
+
+func TestSuggestCurrencies(t *testing.T) {
+	testCases := []struct {
+		Code        string
+		Suggestions []string
+	}{
+		{Code: "EURO", Suggestions: []string{"EUR"}},
+		{Code: "usdd", Suggestions: []string{"USD"}},
+		{Code: "UDS", Suggestions: []string{"USD", "UZS"}},
+		{Code: "yen", Suggestions: []string{"JPY"}},
+		{Code: "Pound Sterling", Suggestions: []string{"GBP"}},
+		{Code: "GPB", Suggestions: []string{"GBP"}},
+		{Code: "DOLLARS", Suggestions: []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Code, func(t *testing.T) {
+			// act
+			suggestions := suggestCurrencies(tc.Code)
+
+			// assert
+			assert.Equal(t, tc.Suggestions, suggestions)
+		})
+	}
+}
+
+func TestSuggestCurrenciesLimit(t *testing.T) {
+	// act
+	suggestions := suggestCurrencies("XXF")
+
+	// assert
+	assert.Len(t, suggestions, maxCurrencySuggestions)
+	for _, suggestion := range suggestions {
+		assert.Equal(t, 1, editDistance("XXF", suggestion))
+	}
+}
+
+func TestGetCurrConvWithUnknownCurrency(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Query       string
+		Field       string
+		Suggestions []string
+	}{
+		{Name: "from", Query: "from=EURO&to=USD&amount=1", Field: "from", Suggestions: []string{"EUR"}},
+		{Name: "one of several targets", Query: "from=EUR&to=USD,GPB&amount=1", Field: "to", Suggestions: []string{"GBP"}},
+		{Name: "without suggestions", Query: "from=EUR&to=DOLLARS&amount=1", Field: "to"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			mockInterface := financelib.MockInterface{}
+			r := setupGin(&mockInterface)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/v1/finance/currconv?"+tc.Query, nil)
+
+			// act
+			r.ServeHTTP(w, req)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			apierror.AssertIsProblem(t, w, apierror.CodeInvalidParameters)
+
+			apiError := apierror.ApiError{}
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &apiError))
+			assert.Len(t, apiError.Errors, 1)
+			assert.Equal(t, tc.Field, apiError.Errors[0].Field)
+			assert.Equal(t, tc.Suggestions, apiError.Errors[0].Suggestions)
+			mockInterface.AssertNotCalled(t, "ConvertCurrency")
+		})
+	}
+}
+
+func TestPostCurrConvWithUnknownCurrency(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	r := setupGin(&mockInterface)
+	w := httptest.NewRecorder()
+
+	body := `{"items":[{"from":"EUR","to":"USD","amount":1},{"from":"EURO","to":"USD","amount":1}]}`
+	req, _ := http.NewRequest("POST", "/v1/finance/currconv", strings.NewReader(body))
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	apierror.AssertHasFieldError(t, w.Body.Bytes(), "items[1].from")
+	assert.Contains(t, w.Body.String(), `did you mean EUR?`)
+}
@@ -0,0 +1,235 @@
+package finance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number, used for money amounts so they do not
+// get binary rounding artifacts, as in 0.1+0.2. The zero value is 0.
+//
+// It is encoded in JSON as a string, as in "10.50", so no precision is lost
+// in transit, and decoded from either a string or a number.
+type Decimal struct {
+	// unscaled is the value times 10^scale, nil for 0
+	unscaled *big.Int
+	scale    int32
+}
+
+// RoundingMode tells how to round a Decimal to fewer decimal places. The
+// zero value rounds as RoundHalfEven.
+type RoundingMode string
+
+const (
+	// RoundHalfEven rounds to the nearest, and ties to the even neighbour,
+	// as in banking
+	RoundHalfEven RoundingMode = "half-even"
+
+	// RoundHalfUp rounds to the nearest, and ties away from zero
+	RoundHalfUp RoundingMode = "half-up"
+
+	// RoundDown truncates towards zero
+	RoundDown RoundingMode = "down"
+)
+
+// maxDecimalExponent bounds the exponents parsed, as in "1e100", so huge ones
+// do not take huge numbers
+const maxDecimalExponent = 100
+
+var bigTen = big.NewInt(10)
+
+// ParseRoundingMode parses the name of a RoundingMode, as in "half-even".
+func ParseRoundingMode(value string) (RoundingMode, error) {
+	switch mode := RoundingMode(value); mode {
+	case RoundHalfEven, RoundHalfUp, RoundDown:
+		return mode, nil
+	}
+
+	return "", fmt.Errorf("unknown rounding mode %q", value)
+}
+
+// ParseDecimal parses a decimal number, as in "-10.50" or "1e3".
+func ParseDecimal(value string) (Decimal, error) {
+	invalid := fmt.Errorf("%q is not a valid decimal number", value)
+
+	mantissa, exponent := value, int64(0)
+	if i := strings.IndexAny(value, "eE"); i >= 0 {
+		var err error
+		mantissa = value[:i]
+		exponent, err = strconv.ParseInt(value[i+1:], 10, 32)
+		if err != nil || exponent > maxDecimalExponent || exponent < -maxDecimalExponent {
+			return Decimal{}, invalid
+		}
+	}
+
+	sign := ""
+	if strings.HasPrefix(mantissa, "-") || strings.HasPrefix(mantissa, "+") {
+		sign, mantissa = mantissa[:1], mantissa[1:]
+	}
+
+	integer, fraction := mantissa, ""
+	if i := strings.Index(mantissa, "."); i >= 0 {
+		integer, fraction = mantissa[:i], mantissa[i+1:]
+	}
+	digits := integer + fraction
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, invalid
+	}
+
+	unscaled, _ := new(big.Int).SetString(sign+digits, 10)
+	scale := int64(len(fraction)) - exponent
+	if scale < 0 {
+		unscaled.Mul(unscaled, pow10(-scale))
+		scale = 0
+	}
+
+	return Decimal{unscaled: unscaled, scale: int32(scale)}, nil
+}
+
+// NewDecimalFromFloat creates a Decimal with the shortest decimal that
+// reads back as the float, so 0.1 is exactly 0.1.
+func NewDecimalFromFloat(value float64) Decimal {
+	d, err := ParseDecimal(strconv.FormatFloat(value, 'f', -1, 64))
+	if err != nil {
+		// NaN and infinities have no decimal
+		return Decimal{}
+	}
+
+	return d
+}
+
+// Add returns d + other, exactly.
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b := d.int(), other.int()
+	scale := d.scale
+	if other.scale > scale {
+		scale = other.scale
+	}
+	a = new(big.Int).Mul(a, pow10(int64(scale-d.scale)))
+	b = new(big.Int).Mul(b, pow10(int64(scale-other.scale)))
+
+	return Decimal{unscaled: a.Add(a, b), scale: scale}
+}
+
+// Mul returns d * other, exactly.
+func (d Decimal) Mul(other Decimal) Decimal {
+	unscaled := new(big.Int).Mul(d.int(), other.int())
+	return Decimal{unscaled: unscaled, scale: d.scale + other.scale}
+}
+
+// Round returns d with the places decimal places, rounding with the mode
+// when it has more, or adding trailing zeros when it has fewer.
+func (d Decimal) Round(places int32, mode RoundingMode) Decimal {
+	if d.scale <= places {
+		unscaled := new(big.Int).Mul(d.int(), pow10(int64(places-d.scale)))
+		return Decimal{unscaled: unscaled, scale: places}
+	}
+
+	divisor := pow10(int64(d.scale - places))
+	quotient, remainder := new(big.Int).QuoRem(d.int(), divisor, new(big.Int))
+
+	// compares the remainder with half the divisor
+	half := new(big.Int).Abs(remainder)
+	half.Mul(half, big.NewInt(2))
+	tie := half.Cmp(divisor)
+
+	awayFromZero := false
+	switch mode {
+	case RoundDown:
+	case RoundHalfUp:
+		awayFromZero = tie >= 0
+	default:
+		awayFromZero = tie > 0 || (tie == 0 && quotient.Bit(0) == 1)
+	}
+	if awayFromZero {
+		quotient.Add(quotient, big.NewInt(int64(d.int().Sign())))
+	}
+
+	return Decimal{unscaled: quotient, scale: places}
+}
+
+// Cmp compares d and other, returning -1, 0 or +1 as in big.Int.
+func (d Decimal) Cmp(other Decimal) int {
+	return d.Add(other.neg()).int().Sign()
+}
+
+// Float64 returns the float nearest to d.
+func (d Decimal) Float64() float64 {
+	value, _ := strconv.ParseFloat(d.String(), 64)
+	return value
+}
+
+// String formats d with all its decimal places, as in "10.50".
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.int()).String()
+	sign := ""
+	if d.int().Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale <= 0 {
+		return sign + digits + strings.Repeat("0", int(-d.scale))
+	}
+
+	if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	point := len(digits) - int(d.scale)
+
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// MarshalText encodes d as its String, which JSON quotes.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes d from its String.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+// UnmarshalJSON decodes d from a JSON string or number, keeping all the
+// digits of the number. Null leaves d unchanged, as in encoding/json.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		return d.UnmarshalText([]byte(text))
+	}
+
+	return d.UnmarshalText(data)
+}
+
+// int returns the unscaled value, with 0 for the zero value of Decimal.
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+
+	return d.unscaled
+}
+
+// neg returns -d.
+func (d Decimal) neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// pow10 returns 10^n.
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(n), nil)
+}
@@ -0,0 +1,149 @@
+package finance
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustDecimal(t *testing.T, value string) Decimal {
+	d, err := ParseDecimal(value)
+	assert.NoError(t, err)
+
+	return d
+}
+
+func TestParseDecimal(t *testing.T) {
+	testCases := []struct {
+		Value  string
+		String string
+		Err    string
+	}{
+		{Value: "10", String: "10"},
+		{Value: "10.50", String: "10.50"},
+		{Value: "-0.05", String: "-0.05"},
+		{Value: "+.5", String: "0.5"},
+		{Value: "1.5e3", String: "1500"},
+		{Value: "15E-3", String: "0.015"},
+		{Value: "", Err: `"" is not a valid decimal number`},
+		{Value: "1,5", Err: `"1,5" is not a valid decimal number`},
+		{Value: "NaN", Err: `"NaN" is not a valid decimal number`},
+		{Value: "1e1000", Err: `"1e1000" is not a valid decimal number`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Value, func(t *testing.T) {
+			// act
+			d, err := ParseDecimal(tc.Value)
+
+			// assert
+			if tc.Err != "" {
+				assert.EqualError(t, err, tc.Err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.String, d.String())
+		})
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	// arrange
+	a := NewDecimalFromFloat(0.1)
+	b := NewDecimalFromFloat(0.2)
+
+	// act
+	sum := a.Add(b)
+	product := mustDecimal(t, "19.99").Mul(mustDecimal(t, "3"))
+
+	// assert
+	assert.Equal(t, "0.3", sum.String())
+	assert.Equal(t, 0, sum.Cmp(mustDecimal(t, "0.30")))
+	assert.Equal(t, "59.97", product.String())
+	assert.Equal(t, "0", Decimal{}.String())
+}
+
+func TestDecimalRound(t *testing.T) {
+	testCases := []struct {
+		Value  string
+		Places int32
+		Mode   RoundingMode
+		String string
+	}{
+		{Value: "2.345", Places: 2, Mode: RoundHalfEven, String: "2.34"},
+		{Value: "2.355", Places: 2, Mode: RoundHalfEven, String: "2.36"},
+		{Value: "2.3451", Places: 2, Mode: RoundHalfEven, String: "2.35"},
+		{Value: "2.345", Places: 2, Mode: RoundHalfUp, String: "2.35"},
+		{Value: "-2.345", Places: 2, Mode: RoundHalfUp, String: "-2.35"},
+		{Value: "2.349", Places: 2, Mode: RoundDown, String: "2.34"},
+		{Value: "-2.349", Places: 2, Mode: RoundDown, String: "-2.34"},
+		{Value: "0.5", Places: 0, Mode: RoundHalfEven, String: "0"},
+		{Value: "0.05", Places: 1, Mode: "", String: "0.0"},
+		{Value: "7", Places: 3, Mode: RoundHalfEven, String: "7.000"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Value+" "+string(tc.Mode), func(t *testing.T) {
+			// act
+			rounded := mustDecimal(t, tc.Value).Round(tc.Places, tc.Mode)
+
+			// assert
+			assert.Equal(t, tc.String, rounded.String())
+		})
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	// arrange
+	var input struct {
+		Number  Decimal  `json:"number"`
+		String  Decimal  `json:"string"`
+		Missing *Decimal `json:"missing"`
+	}
+
+	// act
+	err := json.Unmarshal([]byte(`{"number":0.1000000000000000055,"string":"10.50","missing":null}`), &input)
+	output, errOutput := json.Marshal(input)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "0.1000000000000000055", input.Number.String())
+	assert.Nil(t, input.Missing)
+	assert.NoError(t, errOutput)
+	assert.JSONEq(t, `{"number":"0.1000000000000000055","string":"10.50","missing":null}`, string(output))
+	assert.Error(t, json.Unmarshal([]byte(`{"number":"ten"}`), &input))
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	for _, mode := range []RoundingMode{RoundHalfEven, RoundHalfUp, RoundDown} {
+		parsed, err := ParseRoundingMode(string(mode))
+		assert.NoError(t, err)
+		assert.Equal(t, mode, parsed)
+	}
+
+	_, err := ParseRoundingMode("ceiling")
+	assert.EqualError(t, err, `unknown rounding mode "ceiling"`)
+}
+
+func TestRoundMoney(t *testing.T) {
+	testCases := []struct {
+		Currency string
+		String   string
+	}{
+		{Currency: "EUR", String: "1234.57"},
+		{Currency: "jpy", String: "1235"},
+		{Currency: "KWD", String: "1234.568"},
+		{Currency: "CLF", String: "1234.5675"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Currency, func(t *testing.T) {
+			// act
+			rounded := RoundMoney(mustDecimal(t, "1234.5675"), tc.Currency, RoundHalfUp)
+
+			// assert
+			assert.Equal(t, tc.String, rounded.String())
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package finance
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ecbSnapshot is a copy of the daily euro foreign exchange reference rates,
+// used when no file is configured. Refresh it from
+// https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml
+//
+//go:embed data/eurofxref-daily.xml
+var ecbSnapshot []byte
+
+// ecbBase is the currency of the ECB reference rates
+const ecbBase = "EUR"
+
+// ECB converts currencies with the euro foreign exchange reference rates of
+// the European Central Bank, without calling any API. Pairs without the euro
+// are cross-rated through it.
+type ECB struct {
+	// days holds the euro reference rates of each day, the latest first
+	days []RateDay
+}
+
+// ecbEnvelope is the XML format of the ECB reference rates, as in
+// eurofxref-daily.xml or eurofxref-hist.xml
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string  `xml:"currency,attr"`
+			Rate     float64 `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// ecbDateLayouts are the date formats of the ECB CSV files, as in
+// "04 February 2022" in eurofxref.csv and "2022-02-04" in eurofxref-hist.csv
+var ecbDateLayouts = []string{"2006-01-02", "02 January 2006"}
+
+// LoadECB reads the ECB reference rates from a XML or CSV file, or from the
+// embedded snapshot if the path is empty.
+func LoadECB(path string) (*ECB, error) {
+	if path == "" {
+		return NewECB(ecbSnapshot)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the ECB rates file: %s", err)
+	}
+
+	return NewECB(data)
+}
+
+// NewECB parses the ECB reference rates, in the XML or CSV format published
+// by the ECB. Fails if there are no rates.
+func NewECB(data []byte) (*ECB, error) {
+	var days []RateDay
+	var err error
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		days, err = parseECBXml(data)
+	} else {
+		days, err = parseECBCsv(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(days) == 0 {
+		return nil, errors.New("the ECB rates have no days")
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.After(days[j].Date) })
+
+	return &ECB{days: days}, nil
+}
+
+func parseECBXml(data []byte) ([]RateDay, error) {
+	envelope := ecbEnvelope{}
+	if err := xml.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid ECB rates XML: %s", err)
+	}
+
+	days := []RateDay{}
+	for _, cube := range envelope.Days {
+		date, err := parseECBDate(cube.Time)
+		if err != nil {
+			return nil, err
+		}
+
+		rates := map[string]float64{}
+		for _, rate := range cube.Rates {
+			rates[rate.Currency] = rate.Rate
+		}
+		days = append(days, newECBDay(date, rates))
+	}
+
+	return days, nil
+}
+
+// parseECBCsv reads a header with the currencies and a row of rates for each
+// day. Rates missing on a day are "N/A".
+func parseECBCsv(data []byte) ([]RateDay, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid ECB rates CSV: %s", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	currencies := records[0]
+	days := []RateDay{}
+	for _, record := range records[1:] {
+		date, err := parseECBDate(record[0])
+		if err != nil {
+			return nil, err
+		}
+
+		rates := map[string]float64{}
+		for i := 1; i < len(record) && i < len(currencies); i++ {
+			currency, value := strings.TrimSpace(currencies[i]), strings.TrimSpace(record[i])
+			if currency == "" || value == "" || value == "N/A" {
+				continue
+			}
+
+			rate, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ECB rate for %s on %s: %q", currency, record[0], value)
+			}
+			rates[currency] = rate
+		}
+		days = append(days, newECBDay(date, rates))
+	}
+
+	return days, nil
+}
+
+// newECBDay creates the day with the rates of one euro.
+func newECBDay(date time.Time, rates map[string]float64) RateDay {
+	return RateDay{Date: date, Rates: map[string]map[string]float64{ecbBase: rates}}
+}
+
+func parseECBDate(value string) (time.Time, error) {
+	for _, layout := range ecbDateLayouts {
+		if date, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid ECB rates date: %q", value)
+}
+
+// ConvertCurrency converts the amount with the latest reference rates.
+func (e *ECB) ConvertCurrency(from string, to string, amount float64) (float64, error) {
+	rate, err := e.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return rate.Value * amount, nil
+}
+
+// Rate returns the latest reference rate of the currencies, with the date of
+// the rates as its time.
+func (e *ECB) Rate(from, to string) (Rate, error) {
+	day := e.days[0]
+
+	value, found := day.crossRate(from, to)
+	if !found {
+		return Rate{}, fmt.Errorf("no ECB reference rate from %q to %q on %s",
+			from, to, day.Date.Format(dateLayout))
+	}
+
+	return Rate{Value: value, Timestamp: day.Date}, nil
+}
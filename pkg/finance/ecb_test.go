@@ -0,0 +1,160 @@
+package finance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testECBXml = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time="2022-02-03">
+			<Cube currency="USD" rate="1.1000"/>
+			<Cube currency="GBP" rate="0.8000"/>
+		</Cube>
+		<Cube time="2022-02-04">
+			<Cube currency="USD" rate="1.2000"/>
+			<Cube currency="GBP" rate="0.8000"/>
+			<Cube currency="JPY" rate="130.00"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+const testECBCsv = `Date, USD, GBP, JPY, 
+04 February 2022, 1.2000, 0.8000, 130.00, 
+`
+
+const testECBHistCsv = `Date,USD,GBP,JPY,CYP,
+2022-02-04,1.2000,0.8000,130.00,N/A,
+2022-02-03,1.1000,0.8000,N/A,N/A,
+`
+
+func TestNewECB(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Data string
+	}{
+		{Name: "xml", Data: testECBXml},
+		{Name: "csv", Data: testECBCsv},
+		{Name: "historical csv", Data: testECBHistCsv},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			ecb, err := NewECB([]byte(tc.Data))
+			assert.NoError(t, err)
+
+			// act
+			rate, err := ecb.Rate("USD", "JPY")
+
+			// assert
+			assert.NoError(t, err)
+			assert.InDelta(t, 130.0/1.2, rate.Value, 1e-9)
+			assert.Equal(t, time.Date(2022, 2, 4, 0, 0, 0, 0, time.UTC), rate.Timestamp)
+			assert.False(t, rate.Cached)
+		})
+	}
+}
+
+func TestNewECBWithInvalidData(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Data string
+		Err  string
+	}{
+		{Name: "invalid xml", Data: "<Envelope>", Err: "invalid ECB rates XML: XML syntax error on line 1: unexpected EOF"},
+		{Name: "no days", Data: "<Envelope></Envelope>", Err: "the ECB rates have no days"},
+		{Name: "empty csv", Data: "", Err: "the ECB rates have no days"},
+		{Name: "invalid date", Data: "Date,USD\nyesterday,1.1\n", Err: `invalid ECB rates date: "yesterday"`},
+		{Name: "invalid rate", Data: "Date,USD\n2022-02-04,x\n", Err: `invalid ECB rate for USD on 2022-02-04: "x"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// act
+			_, err := NewECB([]byte(tc.Data))
+
+			// assert
+			assert.EqualError(t, err, tc.Err)
+		})
+	}
+}
+
+func TestECBConvertCurrency(t *testing.T) {
+	testCases := []struct {
+		From   string
+		To     string
+		Amount float64
+		Result float64
+	}{
+		{From: "EUR", To: "USD", Amount: 10, Result: 12},
+		{From: "USD", To: "EUR", Amount: 12, Result: 10},
+		{From: "GBP", To: "USD", Amount: 10, Result: 15},
+		{From: "gbp", To: "eur", Amount: 8, Result: 10},
+		{From: "EUR", To: "EUR", Amount: 10, Result: 10},
+	}
+
+	ecb, err := NewECB([]byte(testECBXml))
+	assert.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.From+"/"+tc.To, func(t *testing.T) {
+			// act
+			result, err := ecb.ConvertCurrency(tc.From, tc.To, tc.Amount)
+
+			// assert
+			assert.NoError(t, err)
+			assert.InDelta(t, tc.Result, result, 1e-9)
+		})
+	}
+}
+
+func TestECBConvertCurrencyWithUnknownCurrency(t *testing.T) {
+	// arrange
+	ecb, err := NewECB([]byte(testECBXml))
+	assert.NoError(t, err)
+
+	// act
+	_, err = ecb.ConvertCurrency("EUR", "XXX", 10)
+
+	// assert
+	assert.EqualError(t, err, `no ECB reference rate from "EUR" to "XXX" on 2022-02-04`)
+}
+
+func TestLoadECB(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "eurofxref.csv")
+	os.WriteFile(path, []byte(testECBCsv), 0600)
+
+	// act
+	ecb, err := LoadECB(path)
+
+	// assert
+	assert.NoError(t, err)
+	rate, err := ecb.Rate("EUR", "GBP")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.8, rate.Value)
+}
+
+func TestLoadECBSnapshot(t *testing.T) {
+	// act
+	ecb, err := LoadECB("")
+
+	// assert
+	assert.NoError(t, err)
+	_, err = ecb.Rate("USD", "JPY")
+	assert.NoError(t, err)
+}
+
+func TestLoadECBWithMissingFile(t *testing.T) {
+	// act
+	_, err := LoadECB(filepath.Join(t.TempDir(), "missing.xml"))
+
+	// assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,182 @@
+package finance
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/renato0307/learning-go-lib/finance"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultFailoverTimeout is how long a provider has to answer by default,
+// before failing over to the next one
+const DefaultFailoverTimeout = 5 * time.Second
+
+// Provider is an exchange rates provider named in the rates it provides
+type Provider struct {
+	Name string
+	finance.Interface
+}
+
+// ConvertCurrency converts the amount with the rate of the provider.
+func (p Provider) ConvertCurrency(from string, to string, amount float64) (float64, error) {
+	rate, err := p.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return rate.Value * amount, nil
+}
+
+// Rate returns the rate of the currencies, naming the provider.
+func (p Provider) Rate(from, to string) (Rate, error) {
+	rate, err := lookupRate(p.Interface, from, to)
+	if err != nil {
+		return Rate{}, err
+	}
+	rate.Provider = p.Name
+
+	return rate, nil
+}
+
+// Failover decorates an ordered list of providers, answering with the first
+// one not failing within the timeout. With a tolerance, all the providers are
+// asked at once, and the answer is flagged if another rate differs from it by
+// more than the tolerance, relative to the answer, as in 0.01 for 1%.
+type Failover struct {
+	Providers []Provider
+	Timeout   time.Duration
+	Tolerance float64
+}
+
+// rateResult is the answer of a provider
+type rateResult struct {
+	rate Rate
+	err  error
+}
+
+// NewFailover creates a new Failover for the providers.
+func NewFailover(providers []Provider, timeout time.Duration, tolerance float64) *Failover {
+	return &Failover{Providers: providers, Timeout: timeout, Tolerance: tolerance}
+}
+
+// ConvertCurrency converts the amount with the rate of the first provider
+// answering.
+func (f *Failover) ConvertCurrency(from string, to string, amount float64) (float64, error) {
+	rate, err := f.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return rate.Value * amount, nil
+}
+
+// Rate returns the rate of the first provider answering. Fails listing the
+// errors of all the providers if none answers.
+func (f *Failover) Rate(from, to string) (Rate, error) {
+	var results []rateResult
+	if f.Tolerance > 0 {
+		results = f.askAll(from, to)
+	} else {
+		results = f.askInOrder(from, to)
+	}
+
+	errs := []string{}
+	for i, result := range results {
+		if result.err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", f.Providers[i].Name, result.err))
+			continue
+		}
+
+		rate := result.rate
+		rate.Disagreement = f.disagree(rate, results[i+1:])
+		return rate, nil
+	}
+
+	return Rate{}, fmt.Errorf("all the providers failed: %s", strings.Join(errs, "; "))
+}
+
+// askInOrder asks the providers one at a time, until one answers.
+func (f *Failover) askInOrder(from, to string) []rateResult {
+	results := []rateResult{}
+	for _, provider := range f.Providers {
+		result := <-f.ask(provider, from, to)
+		results = append(results, result)
+		if result.err == nil {
+			break
+		}
+	}
+
+	return results
+}
+
+// askAll asks all the providers at once, returning their answers in order.
+func (f *Failover) askAll(from, to string) []rateResult {
+	answers := []<-chan rateResult{}
+	for _, provider := range f.Providers {
+		answers = append(answers, f.ask(provider, from, to))
+	}
+
+	results := []rateResult{}
+	for _, answer := range answers {
+		results = append(results, <-answer)
+	}
+
+	return results
+}
+
+// ask gets the rate from the provider, failing if it takes longer than the
+// timeout or panics. The provider call is left running, as finance.Interface
+// cannot be cancelled.
+func (f *Failover) ask(provider Provider, from, to string) <-chan rateResult {
+	answer := make(chan rateResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				answer <- rateResult{err: fmt.Errorf("panicked: %v", r)}
+			}
+		}()
+
+		rate, err := provider.Rate(from, to)
+		answer <- rateResult{rate: rate, err: err}
+	}()
+
+	result := make(chan rateResult, 1)
+	go func() {
+		timer := time.NewTimer(f.Timeout)
+		defer timer.Stop()
+
+		select {
+		case r := <-answer:
+			result <- r
+		case <-timer.C:
+			result <- rateResult{err: fmt.Errorf("timed out after %s", f.Timeout)}
+		}
+	}()
+
+	return result
+}
+
+// disagree checks if any of the other rates differs from the rate by more
+// than the tolerance.
+func (f *Failover) disagree(rate Rate, others []rateResult) bool {
+	for _, other := range others {
+		if other.err != nil {
+			continue
+		}
+
+		if math.Abs(other.rate.Value-rate.Value) > f.Tolerance*math.Abs(rate.Value) {
+			log.Warn().
+				Str("provider", rate.Provider).
+				Float64("rate", rate.Value).
+				Str("other_provider", other.rate.Provider).
+				Float64("other_rate", other.rate.Value).
+				Msg("exchange rate providers disagree")
+			return true
+		}
+	}
+
+	return false
+}
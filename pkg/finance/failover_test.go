@@ -0,0 +1,210 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/renato0307/learning-go-api/internal/registry"
+	financelib "github.com/renato0307/learning-go-lib/finance"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFcsapiStandIn creates a provider calling a local stand-in of the fcsapi
+// API, answering with the rate after the delay, or failing if the rate is
+// empty.
+func newFcsapiStandIn(t *testing.T, name, rate string, delay time.Duration) Provider {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		if rate == "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"code":200,"response":[{"c":"%s"}]}`, rate)
+	}))
+	t.Cleanup(server.Close)
+
+	f := financelib.NewFinanceFunctions(server.URL, "fake_key")
+	return Provider{Name: name, Interface: &f}
+}
+
+func TestProviderRate(t *testing.T) {
+	// arrange
+	provider := newFcsapiStandIn(t, "fcsapi", "1.1", 0)
+
+	// act
+	rate, err := provider.Rate("EUR", "USD")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1.1, rate.Value)
+	assert.Equal(t, "fcsapi", rate.Provider)
+}
+
+func TestFailoverRate(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		First    Provider
+		Provider string
+	}{
+		{Name: "first answers", First: newFcsapiStandIn(t, "first", "1.1", 0), Provider: "first"},
+		{Name: "first fails", First: newFcsapiStandIn(t, "first", "", 0), Provider: "second"},
+		{Name: "first times out", First: newFcsapiStandIn(t, "first", "1.1", 200*time.Millisecond), Provider: "second"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			second := newFcsapiStandIn(t, "second", "1.1", 0)
+			failover := NewFailover([]Provider{tc.First, second}, 50*time.Millisecond, 0)
+
+			// act
+			rate, err := failover.Rate("EUR", "USD")
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, 1.1, rate.Value)
+			assert.Equal(t, tc.Provider, rate.Provider)
+			assert.False(t, rate.Disagreement)
+		})
+	}
+}
+
+func TestFailoverRateWithAllFailing(t *testing.T) {
+	// arrange
+	providers := []Provider{
+		newFcsapiStandIn(t, "first", "", 0),
+		newFcsapiStandIn(t, "second", "1.1", 200*time.Millisecond),
+	}
+	failover := NewFailover(providers, 50*time.Millisecond, 0)
+
+	// act
+	_, err := failover.Rate("EUR", "USD")
+
+	// assert
+	assert.EqualError(t, err, "all the providers failed: "+
+		"first: error getting the conversion data: 503; "+
+		"second: timed out after 50ms")
+}
+
+func TestFailoverRateWithPanickingProvider(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Panic("fake panic")
+	providers := []Provider{
+		{Name: "first", Interface: &mockInterface},
+		newFcsapiStandIn(t, "second", "1.1", 0),
+	}
+	failover := NewFailover(providers, 50*time.Millisecond, 0)
+
+	// act
+	rate, err := failover.Rate("EUR", "USD")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1.1, rate.Value)
+	assert.Equal(t, "second", rate.Provider)
+}
+
+func TestFailoverRateWithAllPanicking(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Panic("fake panic")
+	failover := NewFailover([]Provider{{Name: "first", Interface: &mockInterface}}, 50*time.Millisecond, 0.01)
+
+	// act
+	_, err := failover.Rate("EUR", "USD")
+
+	// assert
+	assert.EqualError(t, err, "all the providers failed: first: panicked: fake panic")
+}
+
+func TestFailoverRateWithTolerance(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		Rates        []string
+		Value        float64
+		Disagreement bool
+	}{
+		{Name: "agree", Rates: []string{"1.100", "1.105"}, Value: 1.1},
+		{Name: "disagree", Rates: []string{"1.100", "1.200"}, Value: 1.1, Disagreement: true},
+		{Name: "first fails", Rates: []string{"", "1.100", "1.200"}, Value: 1.1, Disagreement: true},
+		{Name: "other fails", Rates: []string{"1.100", ""}, Value: 1.1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			providers := []Provider{}
+			for i, rate := range tc.Rates {
+				providers = append(providers, newFcsapiStandIn(t, fmt.Sprint(i), rate, 0))
+			}
+			failover := NewFailover(providers, time.Second, 0.01)
+
+			// act
+			rate, err := failover.Rate("EUR", "USD")
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Value, rate.Value)
+			assert.Equal(t, tc.Disagreement, rate.Disagreement)
+		})
+	}
+}
+
+func TestFailoverRateWithECB(t *testing.T) {
+	// arrange
+	ecb, err := NewECB([]byte(testECBXml))
+	assert.NoError(t, err)
+	providers := []Provider{
+		newFcsapiStandIn(t, ProviderFcsapi, "", 0),
+		{Name: ProviderECB, Interface: ecb},
+	}
+	failover := NewFailover(providers, time.Second, 0)
+
+	// act
+	converted, err := failover.ConvertCurrency("EUR", "USD", 10)
+
+	// assert
+	assert.NoError(t, err)
+	assert.InDelta(t, 12.0, converted, 1e-9)
+}
+
+func TestAnyHealthy(t *testing.T) {
+	healthy := func(ctx context.Context) error { return nil }
+	unhealthy := func(ctx context.Context) error { return errors.New("fake error") }
+
+	testCases := []struct {
+		Name         string
+		HealthChecks []registry.HealthCheck
+		Nil          bool
+		Err          string
+	}{
+		{Name: "one healthy", HealthChecks: []registry.HealthCheck{unhealthy, healthy}},
+		{Name: "all unhealthy", HealthChecks: []registry.HealthCheck{unhealthy, unhealthy}, Err: "fake error; fake error"},
+		{Name: "always healthy", HealthChecks: []registry.HealthCheck{unhealthy, nil}, Nil: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// act
+			healthCheck := anyHealthy(tc.HealthChecks)
+
+			// assert
+			if tc.Nil {
+				assert.Nil(t, healthCheck)
+				return
+			}
+			err := healthCheck(context.Background())
+			if tc.Err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.Err)
+			}
+		})
+	}
+}
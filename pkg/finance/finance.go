@@ -2,18 +2,109 @@ package finance
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/openapi"
 	"github.com/renato0307/learning-go-lib/finance"
 
 	"github.com/rs/zerolog/log"
 )
 
-// SetRouterGroup defines all the routes for the finance functions
-func SetRouterGroup(f finance.Interface, base *gin.RouterGroup) *gin.RouterGroup {
+// RoutePolicies defines the scopes required by the finance functions, with
+// paths relative to the router group
+var RoutePolicies = []middleware.RoutePolicy{
+	{Method: "GET", Path: "/currconv", Scopes: []string{"finance-currconv"}},
+	{Method: "POST", Path: "/currconv", Scopes: []string{"finance-currconv"}},
+	{Method: "GET", Path: "/rates/:base/history", Scopes: []string{"finance-rates"}},
+	{Method: "GET", Path: "/currencies", Public: true},
+}
+
+// RouteDocs documents the finance functions in the OpenAPI document, with
+// paths relative to the router group
+var RouteDocs = []openapi.Route{
+	{
+		Method:      "GET",
+		Path:        "/currconv",
+		Summary:     "Converts an amount between currencies",
+		Description: "Uses the current exchange rate of the currencies, or the historical one with a date. The amounts are decimal strings, with the converted amount rounded to the ISO 4217 decimal places of its currency. With several currencies in \"to\", returns the conversions as in the POST route.",
+		Tags:        []string{"finance"},
+		Parameters: []openapi.Parameter{
+			openapi.QueryParameter("from",
+				openapi.String("Currency to convert from, as in \"EUR\""), true),
+			openapi.QueryParameter("to",
+				openapi.String("Currency to convert to, as in \"USD\", or several separated by commas, as in \"USD,GBP,JPY\""), true),
+			openapi.QueryParameter("amount",
+				openapi.String("Amount to convert, as a decimal number, as in \"10.50\""), true),
+			openapi.QueryParameter("date",
+				openapi.String("Day of the historical exchange rate, as in \"2022-02-04\". Without a rate on the day, uses the last day before it with one."), false),
+		},
+		Output: openapi.OneOf{getCurrConvOutput{}, currConvListOutput{}},
+		Errors: []apierror.Code{
+			apierror.CodeInvalidParameters,
+			apierror.CodeRateNotFound,
+			apierror.CodeConversionFailed,
+		},
+	},
+	{
+		Method:      "POST",
+		Path:        "/currconv",
+		Summary:     "Converts a list of amounts between currencies",
+		Description: "Returns the conversions in the order of the items. Items converting between the same currencies share a single exchange rate lookup.",
+		Tags:        []string{"finance"},
+		RequestBody: openapi.JSONBody("The amounts to convert", postCurrConvInput{}),
+		Output:      currConvListOutput{},
+		Errors: []apierror.Code{
+			apierror.CodeInvalidBody,
+			apierror.CodeInvalidParameters,
+			apierror.CodeRateNotFound,
+			apierror.CodeConversionFailed,
+		},
+	},
+	{
+		Method:      "GET",
+		Path:        "/rates/:base/history",
+		Summary:     "Gets the exchange rates of a currency on past days",
+		Description: "Returns the rates of one unit of the base currency on each day with rates in the range, as looked up or imported.",
+		Tags:        []string{"finance"},
+		Parameters: []openapi.Parameter{
+			openapi.PathParameter("base",
+				openapi.String("Base currency of the rates, as in \"EUR\"")),
+			openapi.QueryParameter("from",
+				openapi.String("First day of the range, as in \"2022-01-01\""), true),
+			openapi.QueryParameter("to",
+				openapi.String("Last day of the range, as in \"2022-01-31\""), true),
+		},
+		Output: getRatesHistoryOutput{},
+		Errors: []apierror.Code{
+			apierror.CodeInvalidParameters,
+		},
+	},
+	{
+		Method:      "GET",
+		Path:        "/currencies",
+		Summary:     "Lists the currencies",
+		Description: "Returns the ISO 4217 currencies accepted by the conversions, with their names, numeric codes, minor units and symbols.",
+		Tags:        []string{"finance"},
+		Output:      getCurrenciesOutput{},
+	},
+}
+
+// SetRouterGroup defines all the routes for the finance functions, with the
+// history of the exchange rates and the rounding mode of the conversions
+func SetRouterGroup(
+	f finance.Interface,
+	history RateStore,
+	rounding RoundingMode,
+	base *gin.RouterGroup) *gin.RouterGroup {
+
 	log.Debug().Msg("setting router group for: finance")
 
 	financeGroup := base.Group("/finance")
 	{
-		financeGroup.GET("/currconv", getCurrConv(f))
+		financeGroup.GET("/currconv", getCurrConv(f, history, rounding))
+		financeGroup.POST("/currconv", postCurrConv(f, history, rounding))
+		financeGroup.GET("/rates/:base/history", getRatesHistory(history))
+		financeGroup.GET("/currencies", getCurrencies())
 		// Add here more functions in the finance category
 	}
 
@@ -1,14 +1,255 @@
 package finance
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
 	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apitesting"
+	"github.com/renato0307/learning-go-api/internal/openapi"
+	"github.com/renato0307/learning-go-api/internal/registry"
 	financelib "github.com/renato0307/learning-go-lib/finance"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 )
 
-func setupGin(mockInterface *financelib.MockInterface) *gin.Engine {
+func setupGin(f financelib.Interface) *gin.Engine {
+	return setupGinWithHistory(f, NewMemoryRateStore())
+}
+
+func setupGinWithHistory(f financelib.Interface, history RateStore) *gin.Engine {
 	r := gin.Default()
 	v1 := r.Group("/v1")
-	SetRouterGroup(mockInterface, v1)
+	SetRouterGroup(f, history, RoundHalfEven, v1)
 
 	return r
 }
+
+func TestRouteDocs(t *testing.T) {
+	// arrange
+	r := gin.New()
+	fg := SetRouterGroup(&financelib.MockInterface{}, NewMemoryRateStore(), RoundHalfEven, r.Group("/v1"))
+	doc := openapi.NewDocument(openapi.Info{Title: "test", Version: "1"})
+
+	// act
+	doc.Register(fg.BasePath(), RouteDocs...)
+
+	// assert
+	assert.NoError(t, doc.CheckRoutes(r.Routes()))
+}
+
+func TestRouteDocsDescribeCurrConvOutputs(t *testing.T) {
+	testCases := []struct {
+		Name string
+		To   string
+	}{
+		{Name: "single target", To: "USD"},
+		{Name: "several targets", To: "USD,GBP"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			mockInterface := financelib.MockInterface{}
+			mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil)
+			mockInterface.On("ConvertCurrency", "EUR", "GBP", 1.0).Return(0.8, nil)
+			r := setupGin(&mockInterface)
+			schema := openapi.InlineSchemaOf(RouteDocs[0].Output)
+
+			// act
+			w := apitesting.PerformRequest(r, "GET", "/v1/finance/currconv?from=EUR&amount=10&to="+tc.To)
+
+			// assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			openapi.AssertMatchesSchema(t, schema, w.Body.Bytes())
+		})
+	}
+}
+
+func TestCategory(t *testing.T) {
+	// arrange
+	categories, err := registry.Enabled("finance")
+	assert.NoError(t, err)
+	r := gin.New()
+	services := grpc.NewServer()
+
+	// act
+	mounted, err := registry.Mount(categories, r.Group("/v1"), services, func(key string) (string, bool) {
+		return "fake_key", key == CURRCONV_API_KEY
+	})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "/v1/finance", mounted[0].Group.BasePath())
+	assert.NotNil(t, mounted[0].HealthCheck)
+	assert.NoError(t, mounted[0].HealthCheck(context.Background()))
+	assert.Contains(t, services.GetServiceInfo(), "learninggoapi.v1.FinanceService")
+}
+
+func TestCategoryWithProviders(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Env         map[string]string
+		HealthCheck bool
+		Err         string
+	}{
+		{
+			Name:        "fcsapi",
+			Env:         map[string]string{CURRCONV_PROVIDER: ProviderFcsapi, CURRCONV_API_KEY: "fake_key"},
+			HealthCheck: true,
+		},
+		{
+			Name: "ecb without api key",
+			Env:  map[string]string{CURRCONV_PROVIDER: ProviderECB},
+		},
+		{
+			Name: "fcsapi without api key",
+			Env:  map[string]string{},
+			Err:  `category "finance": the fcsapi provider requires the CURRCONV_API_KEY environment variable`,
+		},
+		{
+			Name: "failover",
+			Env:  map[string]string{CURRCONV_PROVIDER: "fcsapi, ecb", CURRCONV_API_KEY: "fake_key"},
+		},
+		{
+			Name: "invalid failover timeout",
+			Env:  map[string]string{CURRCONV_PROVIDER: "ecb,ecb", CURRCONV_FAILOVER_TIMEOUT: "0s"},
+			Err:  `category "finance": invalid CURRCONV_FAILOVER_TIMEOUT value: "0s"`,
+		},
+		{
+			Name: "invalid consensus tolerance",
+			Env:  map[string]string{CURRCONV_PROVIDER: "ecb,ecb", CURRCONV_CONSENSUS_TOLERANCE: "-1"},
+			Err:  `category "finance": invalid CURRCONV_CONSENSUS_TOLERANCE value: "-1"`,
+		},
+		{
+			Name: "invalid rounding",
+			Env:  map[string]string{CURRCONV_PROVIDER: ProviderECB, CURRCONV_ROUNDING: "up"},
+			Err:  `category "finance": invalid CURRCONV_ROUNDING value: "up"`,
+		},
+		{
+			Name: "unknown provider",
+			Env:  map[string]string{CURRCONV_PROVIDER: "bank"},
+			Err:  `category "finance": invalid CURRCONV_PROVIDER value: unknown provider "bank"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			categories, err := registry.Enabled("finance")
+			assert.NoError(t, err)
+			r := gin.New()
+
+			// act
+			mounted, err := registry.Mount(categories, r.Group("/v1"), nil, func(key string) (string, bool) {
+				value, found := tc.Env[key]
+				return value, found
+			})
+
+			// assert
+			if tc.Err != "" {
+				assert.EqualError(t, err, tc.Err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.HealthCheck, mounted[0].HealthCheck != nil)
+		})
+	}
+}
+
+func TestCategoryWithFcsapiStandIn(t *testing.T) {
+	// arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":200,"response":[{"c":"1.5"}]}`))
+	}))
+	defer server.Close()
+	env := map[string]string{CURRCONV_API_KEY: "fake_key", CURRCONV_FCSAPI_URL: server.URL}
+
+	categories, err := registry.Enabled("finance")
+	assert.NoError(t, err)
+	r := gin.New()
+	_, err = registry.Mount(categories, r.Group("/v1"), nil, func(key string) (string, bool) {
+		value, found := env[key]
+		return value, found
+	})
+	assert.NoError(t, err)
+
+	// act
+	w := apitesting.PerformRequest(r, "GET", "/v1/finance/currconv?from=EUR&to=USD&amount=10")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	output := getCurrConvOutput{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &output))
+	assert.Equal(t, "15.00", output.ConvertedAmount.String())
+	assert.Equal(t, ProviderFcsapi, output.Provider)
+}
+
+func TestCategoryWithHistory(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	file := filepath.Join(dir, "eurofxref-hist.csv")
+	assert.NoError(t, os.WriteFile(file, []byte(testECBHistCsv), 0600))
+	env := map[string]string{
+		CURRCONV_PROVIDER:        ProviderECB,
+		CURRCONV_HISTORY_DB_PATH: filepath.Join(dir, "rates.db"),
+		CURRCONV_HISTORY_FILES:   file,
+	}
+
+	categories, err := registry.Enabled("finance")
+	assert.NoError(t, err)
+	r := gin.New()
+	_, err = registry.Mount(categories, r.Group("/v1"), nil, func(key string) (string, bool) {
+		value, found := env[key]
+		return value, found
+	})
+	assert.NoError(t, err)
+
+	// act
+	w := apitesting.PerformRequest(r, "GET", "/v1/finance/currconv?from=EUR&to=USD&amount=10&date=2022-02-03")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	output := getCurrConvOutput{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &output))
+	assert.Equal(t, "11.00", output.ConvertedAmount.String())
+	assert.Equal(t, "2022-02-03", output.RateDate)
+}
+
+func TestCategoryWithInvalidHistoryFile(t *testing.T) {
+	// arrange
+	env := map[string]string{
+		CURRCONV_PROVIDER:      ProviderECB,
+		CURRCONV_HISTORY_FILES: filepath.Join(t.TempDir(), "missing.csv"),
+	}
+	categories, err := registry.Enabled("finance")
+	assert.NoError(t, err)
+
+	// act
+	_, err = registry.Mount(categories, gin.New().Group("/v1"), nil, func(key string) (string, bool) {
+		value, found := env[key]
+		return value, found
+	})
+
+	// assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `category "finance": invalid CURRCONV_HISTORY_FILES value`)
+}
+
+func TestDegraded(t *testing.T) {
+	// arrange
+	healthCheck := degraded(func(ctx context.Context) error { return errors.New("fake error") })
+
+	// act
+	err := healthCheck(context.Background())
+
+	// assert
+	assert.True(t, errors.Is(err, registry.ErrDegraded))
+	assert.Nil(t, degraded(nil))
+}
@@ -0,0 +1,87 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	apiv1 "github.com/renato0307/learning-go-api/api/v1"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/grpcapi"
+	"github.com/renato0307/learning-go-api/internal/logging"
+	"github.com/renato0307/learning-go-api/internal/registry"
+	"github.com/renato0307/learning-go-lib/finance"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GrpcMethods maps the FinanceService methods to the routes they mirror, with
+// paths relative to the router group
+var GrpcMethods = []registry.GrpcMethod{
+	{FullMethod: "/learninggoapi.v1.FinanceService/ConvertCurrency", Method: "GET", Path: "/currconv"},
+}
+
+// grpcServer serves the finance functions over gRPC
+type grpcServer struct {
+	apiv1.UnimplementedFinanceServiceServer
+
+	f        finance.Interface
+	history  RateStore
+	rounding RoundingMode
+}
+
+// ConvertCurrency converts the amount, like getCurrConv.
+//
+// Returns InvalidArgument listing the missing or invalid parameters.
+// Returns NotFound if there is no historical rate for the date.
+// Returns Unavailable if the conversion fails.
+func (s *grpcServer) ConvertCurrency(
+	ctx context.Context,
+	req *apiv1.ConvertCurrencyRequest) (*apiv1.ConvertCurrencyResponse, error) {
+
+	logger := logging.FromContext(ctx)
+	logger.Debug().
+		Str("from", req.From).
+		Str("to", req.To).
+		Str("date", req.Date).
+		Msg("running currency converter")
+
+	fieldErrors := validateCurrConv(req.From, singleTarget(req.To), req.Amount != nil)
+	var amount Decimal
+	if req.Amount != nil {
+		var err error
+		amount, err = ParseDecimal(req.GetAmount())
+		if err != nil {
+			msg := "error: 'amount' is not a valid number"
+			fieldErrors = append(fieldErrors, apierror.InvalidField("amount", msg))
+		}
+	}
+	fieldErrors = append(fieldErrors, validateRateDate("date", req.Date)...)
+	if len(fieldErrors) > 0 {
+		return nil, grpcapi.Invalid(fieldErrors...)
+	}
+
+	from, to := strings.ToUpper(req.From), strings.ToUpper(req.To)
+	pair := currencyPair{From: from, To: to, Date: req.Date}
+	rate, err := pairRate(ctx, s.f, s.history, pair)
+	if errors.Is(err, ErrRateNotFound) {
+		msg := fmt.Sprintf("error: %s", err.Error())
+		return nil, grpcapi.Error(apierror.CodeRateNotFound, msg)
+	}
+	if err != nil {
+		msg := fmt.Sprintf("error converting the currency: %s", err.Error())
+		return nil, grpcapi.Error(apierror.CodeConversionFailed, msg)
+	}
+
+	return &apiv1.ConvertCurrencyResponse{
+		From:            from,
+		To:              to,
+		Amount:          amount.String(),
+		ConvertedAmount: convertAmount(amount, rate, to, s.rounding).String(),
+		RateDate:        rate.Timestamp.UTC().Format(dateLayout),
+		RateTimestamp:   timestamppb.New(rate.Timestamp),
+		Cached:          rate.Cached,
+		Provider:        rate.Provider,
+		Disagreement:    rate.Disagreement,
+	}, nil
+}
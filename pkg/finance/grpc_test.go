@@ -0,0 +1,165 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apiv1 "github.com/renato0307/learning-go-api/api/v1"
+	financelib "github.com/renato0307/learning-go-lib/finance"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGrpcConvertCurrency(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil)
+	s := grpcServer{f: &mockInterface}
+
+	// act
+	resp, err := s.ConvertCurrency(context.Background(), &apiv1.ConvertCurrencyRequest{
+		From:   "EUR",
+		To:     "USD",
+		Amount: proto.String("10"),
+	})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", resp.From)
+	assert.Equal(t, "USD", resp.To)
+	assert.Equal(t, "10", resp.Amount)
+	assert.Equal(t, "11.00", resp.ConvertedAmount)
+	mockInterface.AssertExpectations(t)
+}
+
+func TestGrpcConvertCurrencyKeepsPrecision(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil)
+	s := grpcServer{f: &mockInterface}
+
+	// act
+	resp, err := s.ConvertCurrency(context.Background(), &apiv1.ConvertCurrencyRequest{
+		From:   "EUR",
+		To:     "USD",
+		Amount: proto.String("12345678901234567.89"),
+	})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "12345678901234567.89", resp.Amount)
+	assert.Equal(t, "13580246791358024.68", resp.ConvertedAmount)
+	mockInterface.AssertExpectations(t)
+}
+
+func TestGrpcConvertCurrencyWithLowercaseCurrencies(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil)
+	s := grpcServer{f: &mockInterface}
+
+	// act
+	resp, err := s.ConvertCurrency(context.Background(), &apiv1.ConvertCurrencyRequest{
+		From:   "eur",
+		To:     "Usd",
+		Amount: proto.String("10"),
+	})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", resp.From)
+	assert.Equal(t, "USD", resp.To)
+	assert.Equal(t, "11.00", resp.ConvertedAmount)
+	mockInterface.AssertExpectations(t)
+}
+
+func TestGrpcConvertCurrencyWithDate(t *testing.T) {
+	// arrange
+	s := grpcServer{f: &financelib.MockInterface{}, history: newTestHistory(t)}
+
+	// act
+	resp, err := s.ConvertCurrency(context.Background(), &apiv1.ConvertCurrencyRequest{
+		From:   "EUR",
+		To:     "USD",
+		Amount: proto.String("10"),
+		Date:   "2022-02-06",
+	})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "11.00", resp.ConvertedAmount)
+	assert.Equal(t, "2022-02-04", resp.RateDate)
+	assert.True(t, resp.Cached)
+}
+
+func TestGrpcConvertCurrencyWithErrors(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Request *apiv1.ConvertCurrencyRequest
+		Code    codes.Code
+		Message string
+	}{
+		{
+			Name:    "missing amount",
+			Request: &apiv1.ConvertCurrencyRequest{From: "EUR", To: "USD"},
+			Code:    codes.InvalidArgument,
+			Message: "error: 'amount' parameter is required",
+		},
+		{
+			Name:    "invalid amount",
+			Request: &apiv1.ConvertCurrencyRequest{From: "EUR", To: "USD", Amount: proto.String("10,5")},
+			Code:    codes.InvalidArgument,
+			Message: "error: 'amount' is not a valid number",
+		},
+		{
+			Name:    "missing all",
+			Request: &apiv1.ConvertCurrencyRequest{},
+			Code:    codes.InvalidArgument,
+			Message: "error: 3 parameters are not valid",
+		},
+		{
+			Name:    "conversion failed",
+			Request: &apiv1.ConvertCurrencyRequest{From: "EUR", To: "CHF", Amount: proto.String("0")},
+			Code:    codes.Unavailable,
+			Message: "error converting the currency: unknown currency",
+		},
+		{
+			Name:    "unknown currency",
+			Request: &apiv1.ConvertCurrencyRequest{From: "EURO", To: "USD", Amount: proto.String("1")},
+			Code:    codes.InvalidArgument,
+			Message: "error: 'from' is not an ISO 4217 currency code, did you mean EUR?",
+		},
+		{
+			Name:    "several targets",
+			Request: &apiv1.ConvertCurrencyRequest{From: "EUR", To: "USD,GBP", Amount: proto.String("1")},
+			Code:    codes.InvalidArgument,
+			Message: "error: 'to' is not an ISO 4217 currency code",
+		},
+		{
+			Name:    "rate not found",
+			Request: &apiv1.ConvertCurrencyRequest{From: "EUR", To: "USD", Amount: proto.String("1"), Date: "2022-02-14"},
+			Code:    codes.NotFound,
+			Message: "error: exchange rate not found from EUR to USD on 2022-02-14 or up to 7 days before",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			mockInterface := financelib.MockInterface{}
+			mockInterface.On("ConvertCurrency", "EUR", "CHF", 1.0).
+				Return(0.0, errors.New("unknown currency"))
+			s := grpcServer{f: &mockInterface, history: newTestHistory(t)}
+
+			// act
+			_, err := s.ConvertCurrency(context.Background(), tc.Request)
+
+			// assert
+			assert.Equal(t, tc.Code, status.Code(err))
+			assert.Equal(t, tc.Message, status.Convert(err).Message())
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/renato0307/learning-go-lib/finance"
+	"github.com/rs/zerolog/log"
+)
+
+// maxRateLookback is how many days before a date are searched for its rate,
+// covering weekends and holidays without rates
+const maxRateLookback = 7
+
+// dateLayout formats the days of the exchange rates
+const dateLayout = "2006-01-02"
+
+// ErrRateNotFound is returned when there is no exchange rate for a date
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// RateStore keeps the exchange rates of past days. Each day holds the rates
+// of one unit of some base currencies, as looked up or imported.
+type RateStore interface {
+	// Save merges the rates of one unit of the base currency into the day
+	Save(day time.Time, base string, rates map[string]float64) error
+
+	// Days returns the days with rates between first and last, inclusive,
+	// in order
+	Days(first, last time.Time) ([]RateDay, error)
+
+	Close() error
+}
+
+// RateDay holds the rates of a day, by base currency
+type RateDay struct {
+	Date  time.Time
+	Rates map[string]map[string]float64
+}
+
+// crossRate finds the rate of the currencies on the day, with a base that
+// has both, as in the euro rates of the ECB.
+func (d RateDay) crossRate(from, to string) (float64, bool) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	for _, base := range d.bases() {
+		fromRate, foundFrom := baseRate(base, d.Rates[base], from)
+		toRate, foundTo := baseRate(base, d.Rates[base], to)
+		if foundFrom && foundTo {
+			return toRate / fromRate, true
+		}
+	}
+
+	return 0, false
+}
+
+// ratesOf returns the rates of one unit of the currency against all the
+// currencies with a rate on the day.
+func (d RateDay) ratesOf(currency string) map[string]float64 {
+	currency = strings.ToUpper(currency)
+	rates := map[string]float64{}
+	for _, base := range d.bases() {
+		currencyRate, found := baseRate(base, d.Rates[base], currency)
+		if !found {
+			continue
+		}
+
+		for other := range d.Rates[base] {
+			if _, found := rates[other]; !found && other != currency {
+				otherRate, _ := baseRate(base, d.Rates[base], other)
+				rates[other] = otherRate / currencyRate
+			}
+		}
+		if _, found := rates[base]; !found && base != currency {
+			rates[base] = 1 / currencyRate
+		}
+	}
+
+	return rates
+}
+
+// bases returns the base currencies of the day, sorted so the rates are
+// deterministic.
+func (d RateDay) bases() []string {
+	bases := make([]string, 0, len(d.Rates))
+	for base := range d.Rates {
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+
+	return bases
+}
+
+// baseRate returns how much of the currency one unit of the base buys.
+func baseRate(base string, rates map[string]float64, currency string) (float64, bool) {
+	if currency == base {
+		return 1, true
+	}
+
+	rate, found := rates[currency]
+	return rate, found && rate > 0
+}
+
+// rateOn returns the rate of the currencies on the date or, if it has none,
+// on the latest day before it, up to maxRateLookback days. The timestamp of
+// the rate is the day used.
+func rateOn(store RateStore, from, to string, date time.Time) (Rate, error) {
+	days, err := store.Days(date.AddDate(0, 0, -maxRateLookback), date)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	for i := len(days) - 1; i >= 0; i-- {
+		if value, found := days[i].crossRate(from, to); found {
+			return Rate{Value: value, Timestamp: days[i].Date, Cached: true}, nil
+		}
+	}
+
+	return Rate{}, fmt.Errorf("%w from %s to %s on %s or up to %d days before",
+		ErrRateNotFound, from, to, date.Format(dateLayout), maxRateLookback)
+}
+
+// Recorder decorates a finance.Interface saving the rates it looks up into
+// the store, so they are kept as history.
+type Recorder struct {
+	Next  finance.Interface
+	Store RateStore
+}
+
+// NewRecorder creates a new Recorder saving into the store.
+func NewRecorder(next finance.Interface, store RateStore) *Recorder {
+	return &Recorder{Next: next, Store: store}
+}
+
+// ConvertCurrency converts the amount with the rate looked up.
+func (r *Recorder) ConvertCurrency(from string, to string, amount float64) (float64, error) {
+	rate, err := r.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	return rate.Value * amount, nil
+}
+
+// Rate looks up the rate and saves it on the day of its timestamp. Failing to
+// save is only logged, as the rate is still valid.
+func (r *Recorder) Rate(from, to string) (Rate, error) {
+	rate, err := lookupRate(r.Next, from, to)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	rates := map[string]float64{strings.ToUpper(to): rate.Value}
+	if err := r.Store.Save(rate.Timestamp, strings.ToUpper(from), rates); err != nil {
+		log.Warn().Err(err).Str("from", from).Str("to", to).Msg("cannot save the exchange rate")
+	}
+
+	return rate, nil
+}
+
+// ImportECB saves all the days of the ECB reference rates into the store.
+func ImportECB(store RateStore, ecb *ECB) error {
+	for _, day := range ecb.days {
+		for base, rates := range day.Rates {
+			if err := store.Save(day.Date, base, rates); err != nil {
+				return fmt.Errorf("cannot import the ECB rates of %s: %s",
+					day.Date.Format(dateLayout), err)
+			}
+		}
+	}
+
+	return nil
+}
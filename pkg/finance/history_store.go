@@ -0,0 +1,179 @@
+package finance
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// rateKeySeparator separates the day and the base currency in the store keys
+const rateKeySeparator = "\x00"
+
+var ratesBucket = []byte("rates")
+
+// BoltRateStore keeps the exchange rates in an embedded bbolt database, so
+// they survive restarts.
+type BoltRateStore struct {
+	db *bolt.DB
+}
+
+// NewBoltRateStore opens, or creates, the database at the path.
+func NewBoltRateStore(path string) (*BoltRateStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the rates store: %s", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(ratesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("cannot create the rates bucket: %s", err)
+	}
+
+	return &BoltRateStore{db: db}, nil
+}
+
+// Save merges the rates of one unit of the base into the day.
+func (s *BoltRateStore) Save(day time.Time, base string, rates map[string]float64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(ratesBucket)
+		key := []byte(day.UTC().Format(dateLayout) + rateKeySeparator + base)
+
+		merged := map[string]float64{}
+		if value := b.Get(key); value != nil {
+			if err := json.Unmarshal(value, &merged); err != nil {
+				return err
+			}
+		}
+		for currency, rate := range rates {
+			merged[currency] = rate
+		}
+
+		value, err := json.Marshal(merged)
+		if err != nil {
+			return err
+		}
+
+		return b.Put(key, value)
+	})
+}
+
+// Days returns the days with rates between first and last, in order.
+func (s *BoltRateStore) Days(first, last time.Time) ([]RateDay, error) {
+	days := []RateDay{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		start := first.UTC().Format(dateLayout)
+		end := last.UTC().Format(dateLayout)
+
+		c := tx.Bucket(ratesBucket).Cursor()
+		for k, v := c.Seek([]byte(start)); k != nil; k, v = c.Next() {
+			parts := strings.SplitN(string(k), rateKeySeparator, 2)
+			if len(parts) != 2 {
+				continue
+			}
+			if parts[0] > end {
+				break
+			}
+
+			rates := map[string]float64{}
+			if err := json.Unmarshal(v, &rates); err != nil {
+				return err
+			}
+
+			if len(days) == 0 || days[len(days)-1].Date.Format(dateLayout) != parts[0] {
+				date, err := time.Parse(dateLayout, parts[0])
+				if err != nil {
+					return err
+				}
+				days = append(days, RateDay{Date: date, Rates: map[string]map[string]float64{}})
+			}
+			days[len(days)-1].Rates[parts[1]] = rates
+		}
+
+		return nil
+	})
+
+	return days, err
+}
+
+// Close closes the database.
+func (s *BoltRateStore) Close() error {
+	return s.db.Close()
+}
+
+// MemoryRateStore keeps the exchange rates in memory, losing them on
+// restarts. Used when no database is configured.
+type MemoryRateStore struct {
+	mu   sync.RWMutex
+	days map[string]map[string]map[string]float64
+}
+
+// NewMemoryRateStore creates an empty MemoryRateStore.
+func NewMemoryRateStore() *MemoryRateStore {
+	return &MemoryRateStore{days: map[string]map[string]map[string]float64{}}
+}
+
+// Save merges the rates of one unit of the base into the day.
+func (s *MemoryRateStore) Save(day time.Time, base string, rates map[string]float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := day.UTC().Format(dateLayout)
+	if s.days[key] == nil {
+		s.days[key] = map[string]map[string]float64{}
+	}
+	if s.days[key][base] == nil {
+		s.days[key][base] = map[string]float64{}
+	}
+	for currency, rate := range rates {
+		s.days[key][base][currency] = rate
+	}
+
+	return nil
+}
+
+// Days returns the days with rates between first and last, in order.
+func (s *MemoryRateStore) Days(first, last time.Time) ([]RateDay, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	start := first.UTC().Format(dateLayout)
+	end := last.UTC().Format(dateLayout)
+
+	days := []RateDay{}
+	for key, bases := range s.days {
+		if key < start || key > end {
+			continue
+		}
+
+		date, err := time.Parse(dateLayout, key)
+		if err != nil {
+			return nil, err
+		}
+
+		day := RateDay{Date: date, Rates: map[string]map[string]float64{}}
+		for base, rates := range bases {
+			day.Rates[base] = map[string]float64{}
+			for currency, rate := range rates {
+				day.Rates[base][currency] = rate
+			}
+		}
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+
+	return days, nil
+}
+
+// Close does nothing, as there is nothing to release.
+func (s *MemoryRateStore) Close() error {
+	return nil
+}
@@ -0,0 +1,170 @@
+package finance
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseDay(date string) time.Time {
+	d, _ := time.Parse(dateLayout, date)
+	return d
+}
+
+func TestRateStores(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		NewStore func(t *testing.T) RateStore
+	}{
+		{
+			Name: "memory",
+			NewStore: func(t *testing.T) RateStore {
+				return NewMemoryRateStore()
+			},
+		},
+		{
+			Name: "bolt",
+			NewStore: func(t *testing.T) RateStore {
+				store, err := NewBoltRateStore(filepath.Join(t.TempDir(), "rates.db"))
+				assert.NoError(t, err)
+				return store
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			store := tc.NewStore(t)
+			defer store.Close()
+
+			assert.NoError(t, store.Save(parseDay("2022-02-04"), "EUR", map[string]float64{"USD": 1.1}))
+			assert.NoError(t, store.Save(parseDay("2022-02-04"), "EUR", map[string]float64{"JPY": 130}))
+			assert.NoError(t, store.Save(parseDay("2022-02-04"), "GBP", map[string]float64{"USD": 1.3}))
+			assert.NoError(t, store.Save(parseDay("2022-02-03"), "EUR", map[string]float64{"USD": 1.0}))
+			assert.NoError(t, store.Save(parseDay("2022-02-08"), "EUR", map[string]float64{"USD": 1.2}))
+
+			// act
+			days, err := store.Days(parseDay("2022-02-03"), parseDay("2022-02-07"))
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, []RateDay{
+				{
+					Date:  parseDay("2022-02-03"),
+					Rates: map[string]map[string]float64{"EUR": {"USD": 1.0}},
+				},
+				{
+					Date: parseDay("2022-02-04"),
+					Rates: map[string]map[string]float64{
+						"EUR": {"USD": 1.1, "JPY": 130},
+						"GBP": {"USD": 1.3},
+					},
+				},
+			}, days)
+		})
+	}
+}
+
+func TestBoltRateStoreKeepsRates(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "rates.db")
+	store, err := NewBoltRateStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save(parseDay("2022-02-04"), "EUR", map[string]float64{"USD": 1.1}))
+	assert.NoError(t, store.Close())
+
+	// act
+	store, err = NewBoltRateStore(path)
+	assert.NoError(t, err)
+	defer store.Close()
+	days, err := store.Days(parseDay("2022-02-04"), parseDay("2022-02-04"))
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, days, 1)
+	assert.Equal(t, 1.1, days[0].Rates["EUR"]["USD"])
+}
+
+func TestRateOn(t *testing.T) {
+	// arrange
+	store := NewMemoryRateStore()
+	store.Save(parseDay("2022-02-03"), "EUR", map[string]float64{"USD": 1.0, "GBP": 0.8})
+	store.Save(parseDay("2022-02-04"), "EUR", map[string]float64{"USD": 1.2})
+
+	testCases := []struct {
+		Name  string
+		From  string
+		To    string
+		Date  string
+		Value float64
+		Used  string
+		Err   string
+	}{
+		{Name: "same day", From: "EUR", To: "USD", Date: "2022-02-04", Value: 1.2, Used: "2022-02-04"},
+		{Name: "weekend", From: "usd", To: "eur", Date: "2022-02-06", Value: 1 / 1.2, Used: "2022-02-04"},
+		{Name: "cross rate", From: "GBP", To: "USD", Date: "2022-02-04", Value: 1.0 / 0.8, Used: "2022-02-03"},
+		{
+			Name: "too long before",
+			From: "EUR",
+			To:   "USD",
+			Date: "2022-02-12",
+			Err:  "exchange rate not found from EUR to USD on 2022-02-12 or up to 7 days before",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// act
+			rate, err := rateOn(store, tc.From, tc.To, parseDay(tc.Date))
+
+			// assert
+			if tc.Err != "" {
+				assert.EqualError(t, err, tc.Err)
+				assert.True(t, errors.Is(err, ErrRateNotFound))
+				return
+			}
+			assert.NoError(t, err)
+			assert.InDelta(t, tc.Value, rate.Value, 1e-9)
+			assert.Equal(t, parseDay(tc.Used), rate.Timestamp)
+			assert.True(t, rate.Cached)
+		})
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	// arrange
+	store := NewMemoryRateStore()
+	recorder := NewRecorder(&fakeRateProvider{}, store)
+
+	// act
+	rate, err := recorder.Rate("eur", "usd")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1.1, rate.Value)
+	recorded, err := rateOn(store, "EUR", "USD", parseDay("2022-02-02"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1.1, recorded.Value)
+	assert.Equal(t, parseDay("2022-02-01"), recorded.Timestamp)
+}
+
+func TestImportECB(t *testing.T) {
+	// arrange
+	store := NewMemoryRateStore()
+	ecb, err := NewECB([]byte(testECBXml))
+	assert.NoError(t, err)
+
+	// act
+	err = ImportECB(store, ecb)
+
+	// assert
+	assert.NoError(t, err)
+	days, err := store.Days(parseDay("2022-02-01"), parseDay("2022-02-28"))
+	assert.NoError(t, err)
+	assert.Len(t, days, 2)
+	assert.Equal(t, map[string]float64{"USD": 1.2, "GBP": 0.8, "JPY": 130}, days[1].Rates["EUR"])
+}
@@ -0,0 +1,123 @@
+package finance
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/renato0307/learning-go-api/internal/metrics"
+	"github.com/renato0307/learning-go-lib/finance"
+)
+
+// healthWindow is how far back the calls to the upstream count for its health
+const healthWindow = 5 * time.Minute
+
+// healthMinCalls is how many calls to the upstream in the healthWindow it
+// takes to judge its health, so a few failures on a quiet upstream are not
+// enough
+const healthMinCalls = 10
+
+// upstreamStatusError matches the error of the converter when the upstream
+// answers with an HTTP status other than 200, as in "...: 503"
+var upstreamStatusError = regexp.MustCompile(`^error getting the conversion data: (\d{3})$`)
+
+// Instrumented decorates a finance.Interface recording the count and latency
+// of the calls to the upstream currency converter.
+type Instrumented struct {
+	Next     finance.Interface
+	Upstream string
+
+	mu    sync.Mutex
+	calls []upstreamCall
+	now   func() time.Time
+}
+
+// upstreamCall is the outcome of a call to the upstream, for its health
+type upstreamCall struct {
+	at     time.Time
+	failed bool
+}
+
+// NewInstrumented creates a new Instrumented for the upstream.
+func NewInstrumented(next finance.Interface, upstream string) *Instrumented {
+	return &Instrumented{Next: next, Upstream: upstream, now: time.Now}
+}
+
+// ConvertCurrency calls the decorated ConvertCurrency, recording its metrics.
+func (i *Instrumented) ConvertCurrency(
+	from string,
+	to string,
+	amount float64) (float64, error) {
+
+	start := time.Now()
+	convertedAmount, err := i.Next.ConvertCurrency(from, to, amount)
+
+	result := metrics.Result(err)
+	metrics.UpstreamRequests.WithLabelValues(i.Upstream, result).Inc()
+	metrics.UpstreamRequestDuration.
+		WithLabelValues(i.Upstream, result).
+		Observe(time.Since(start).Seconds())
+
+	i.mu.Lock()
+	i.calls = append(i.recentCalls(), upstreamCall{at: i.now(), failed: isUpstreamFailure(err)})
+	i.mu.Unlock()
+
+	return convertedAmount, err
+}
+
+// HealthCheck fails if most of the calls to the upstream in the last
+// healthWindow failed, out of at least healthMinCalls calls. Only the
+// failures reaching the upstream or of its servers count, not the rejected
+// currency pairs, and without recent calls the upstream is healthy.
+func (i *Instrumented) HealthCheck(ctx context.Context) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.calls = i.recentCalls()
+	failures := 0
+	for _, call := range i.calls {
+		if call.failed {
+			failures++
+		}
+	}
+
+	if len(i.calls) >= healthMinCalls && failures*2 > len(i.calls) {
+		return fmt.Errorf("%d of the last %d calls to %s failed", failures, len(i.calls), i.Upstream)
+	}
+
+	return nil
+}
+
+// recentCalls returns the calls within the healthWindow. The caller must hold
+// the lock.
+func (i *Instrumented) recentCalls() []upstreamCall {
+	since := i.now().Add(-healthWindow)
+	for j, call := range i.calls {
+		if call.at.After(since) {
+			return i.calls[j:]
+		}
+	}
+
+	return nil
+}
+
+// isUpstreamFailure checks if the error of the converter is from not reaching
+// the upstream or from an HTTP 5xx answer, and not from a rejected request.
+func isUpstreamFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := err.Error()
+	if match := upstreamStatusError.FindStringSubmatch(msg); match != nil {
+		status, _ := strconv.Atoi(match[1])
+		return status >= 500
+	}
+
+	return strings.HasPrefix(msg, "error getting the conversion data: ") ||
+		strings.HasPrefix(msg, "error reading the conversion data: ")
+}
@@ -0,0 +1,95 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/renato0307/learning-go-api/internal/metrics"
+	financelib "github.com/renato0307/learning-go-lib/finance"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrumentedConvertCurrency(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 10.0).Return(11.0, nil)
+	mockInterface.On("ConvertCurrency", "EUR", "XXX", 10.0).Return(0.0, errors.New("fake error"))
+
+	f := NewInstrumented(&mockInterface, "fake")
+	successes := metrics.UpstreamRequests.WithLabelValues("fake", metrics.ResultSuccess)
+	failures := metrics.UpstreamRequests.WithLabelValues("fake", metrics.ResultError)
+
+	// act
+	result, err := f.ConvertCurrency("EUR", "USD", 10.0)
+	_, errFailed := f.ConvertCurrency("EUR", "XXX", 10.0)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 11.0, result)
+	assert.NotNil(t, errFailed)
+	assert.Equal(t, 1.0, testutil.ToFloat64(successes))
+	assert.Equal(t, 1.0, testutil.ToFloat64(failures))
+	mockInterface.AssertExpectations(t)
+}
+
+func TestInstrumentedHealthCheck(t *testing.T) {
+	// arrange
+	upstreamDown := errors.New(`error getting the conversion data: Get "https://fcsapi.com": dial tcp: connection refused`)
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 10.0).Return(11.0, nil)
+	mockInterface.On("ConvertCurrency", "EUR", "GBP", 10.0).Return(0.0, upstreamDown)
+	mockInterface.On("ConvertCurrency", "EUR", "CHF", 10.0).Return(0.0, errors.New("error getting the conversion data: 503"))
+
+	now := time.Now()
+	f := NewInstrumented(&mockInterface, "fake")
+	f.now = func() time.Time { return now }
+
+	// act & assert - healthy without calls and while there are few calls,
+	// even if most failed
+	assert.NoError(t, f.HealthCheck(context.Background()))
+
+	for j := 0; j < 5; j++ {
+		f.ConvertCurrency("EUR", "GBP", 10.0)
+	}
+	f.ConvertCurrency("EUR", "USD", 10.0)
+	assert.NoError(t, f.HealthCheck(context.Background()))
+
+	// act & assert - unhealthy when most of enough recent calls fail
+	f.ConvertCurrency("EUR", "CHF", 10.0)
+	f.ConvertCurrency("EUR", "USD", 10.0)
+	f.ConvertCurrency("EUR", "USD", 10.0)
+	f.ConvertCurrency("EUR", "USD", 10.0)
+	assert.EqualError(t, f.HealthCheck(context.Background()),
+		"6 of the last 10 calls to fake failed")
+
+	// act & assert - healthy again once the failures are old, even without
+	// new calls
+	now = now.Add(healthWindow)
+	assert.NoError(t, f.HealthCheck(context.Background()))
+}
+
+func TestIsUpstreamFailure(t *testing.T) {
+	testCases := []struct {
+		Err     error
+		Failure bool
+	}{
+		{Err: nil, Failure: false},
+		{Err: errors.New(`error getting the conversion data: Get "x": i/o timeout`), Failure: true},
+		{Err: errors.New("error getting the conversion data: 502"), Failure: true},
+		{Err: errors.New("error reading the conversion data: unexpected EOF"), Failure: true},
+		{Err: errors.New("error getting the conversion data: 404"), Failure: false},
+		{Err: errors.New("error parsing the conversion data: invalid syntax"), Failure: false},
+		{Err: errors.New("fake error"), Failure: false},
+	}
+
+	for _, tc := range testCases {
+		// act
+		failure := isUpstreamFailure(tc.Err)
+
+		// assert
+		assert.Equal(t, tc.Failure, failure, "%v", tc.Err)
+	}
+}
@@ -0,0 +1,181 @@
+package finance
+
+// currencyCatalog lists the ISO 4217 currencies, sorted by code. It has the
+// current currencies with minor units, without precious metals or testing
+// codes, and the ones replaced by the euro still found in the ECB reference
+// rates history.
+var currencyCatalog = []Currency{
+	{Code: "AED", Numeric: "784", MinorUnits: 2, Name: "UAE Dirham", Symbol: "د.إ"},
+	{Code: "AFN", Numeric: "971", MinorUnits: 2, Name: "Afghani", Symbol: "؋"},
+	{Code: "ALL", Numeric: "008", MinorUnits: 2, Name: "Lek", Symbol: "L"},
+	{Code: "AMD", Numeric: "051", MinorUnits: 2, Name: "Armenian Dram", Symbol: "֏"},
+	{Code: "ANG", Numeric: "532", MinorUnits: 2, Name: "Netherlands Antillean Guilder", Symbol: "ƒ"},
+	{Code: "AOA", Numeric: "973", MinorUnits: 2, Name: "Kwanza", Symbol: "Kz"},
+	{Code: "ARS", Numeric: "032", MinorUnits: 2, Name: "Argentine Peso", Symbol: "$"},
+	{Code: "AUD", Numeric: "036", MinorUnits: 2, Name: "Australian Dollar", Symbol: "A$"},
+	{Code: "AWG", Numeric: "533", MinorUnits: 2, Name: "Aruban Florin", Symbol: "ƒ"},
+	{Code: "AZN", Numeric: "944", MinorUnits: 2, Name: "Azerbaijan Manat", Symbol: "₼"},
+	{Code: "BAM", Numeric: "977", MinorUnits: 2, Name: "Convertible Mark", Symbol: "KM"},
+	{Code: "BBD", Numeric: "052", MinorUnits: 2, Name: "Barbados Dollar", Symbol: "Bds$"},
+	{Code: "BDT", Numeric: "050", MinorUnits: 2, Name: "Taka", Symbol: "৳"},
+	{Code: "BGN", Numeric: "975", MinorUnits: 2, Name: "Bulgarian Lev", Symbol: "лв"},
+	{Code: "BHD", Numeric: "048", MinorUnits: 3, Name: "Bahraini Dinar", Symbol: "BD"},
+	{Code: "BIF", Numeric: "108", MinorUnits: 0, Name: "Burundi Franc", Symbol: "FBu"},
+	{Code: "BMD", Numeric: "060", MinorUnits: 2, Name: "Bermudian Dollar", Symbol: "$"},
+	{Code: "BND", Numeric: "096", MinorUnits: 2, Name: "Brunei Dollar", Symbol: "B$"},
+	{Code: "BOB", Numeric: "068", MinorUnits: 2, Name: "Boliviano", Symbol: "Bs"},
+	{Code: "BOV", Numeric: "984", MinorUnits: 2, Name: "Mvdol"},
+	{Code: "BRL", Numeric: "986", MinorUnits: 2, Name: "Brazilian Real", Symbol: "R$"},
+	{Code: "BSD", Numeric: "044", MinorUnits: 2, Name: "Bahamian Dollar", Symbol: "$"},
+	{Code: "BTN", Numeric: "064", MinorUnits: 2, Name: "Ngultrum", Symbol: "Nu."},
+	{Code: "BWP", Numeric: "072", MinorUnits: 2, Name: "Pula", Symbol: "P"},
+	{Code: "BYN", Numeric: "933", MinorUnits: 2, Name: "Belarusian Ruble", Symbol: "Br"},
+	{Code: "BZD", Numeric: "084", MinorUnits: 2, Name: "Belize Dollar", Symbol: "BZ$"},
+	{Code: "CAD", Numeric: "124", MinorUnits: 2, Name: "Canadian Dollar", Symbol: "CA$"},
+	{Code: "CDF", Numeric: "976", MinorUnits: 2, Name: "Congolese Franc", Symbol: "FC"},
+	{Code: "CHE", Numeric: "947", MinorUnits: 2, Name: "WIR Euro"},
+	{Code: "CHF", Numeric: "756", MinorUnits: 2, Name: "Swiss Franc", Symbol: "CHF"},
+	{Code: "CHW", Numeric: "948", MinorUnits: 2, Name: "WIR Franc"},
+	{Code: "CLF", Numeric: "990", MinorUnits: 4, Name: "Unidad de Fomento", Symbol: "UF"},
+	{Code: "CLP", Numeric: "152", MinorUnits: 0, Name: "Chilean Peso", Symbol: "$"},
+	{Code: "CNY", Numeric: "156", MinorUnits: 2, Name: "Yuan Renminbi", Symbol: "¥"},
+	{Code: "COP", Numeric: "170", MinorUnits: 2, Name: "Colombian Peso", Symbol: "$"},
+	{Code: "COU", Numeric: "970", MinorUnits: 2, Name: "Unidad de Valor Real"},
+	{Code: "CRC", Numeric: "188", MinorUnits: 2, Name: "Costa Rican Colon", Symbol: "₡"},
+	{Code: "CUP", Numeric: "192", MinorUnits: 2, Name: "Cuban Peso", Symbol: "$"},
+	{Code: "CVE", Numeric: "132", MinorUnits: 2, Name: "Cabo Verde Escudo", Symbol: "Esc"},
+	{Code: "CYP", Numeric: "196", MinorUnits: 2, Name: "Cyprus Pound", Symbol: "£", Withdrawn: "2008-01-01"},
+	{Code: "CZK", Numeric: "203", MinorUnits: 2, Name: "Czech Koruna", Symbol: "Kč"},
+	{Code: "DJF", Numeric: "262", MinorUnits: 0, Name: "Djibouti Franc", Symbol: "Fdj"},
+	{Code: "DKK", Numeric: "208", MinorUnits: 2, Name: "Danish Krone", Symbol: "kr"},
+	{Code: "DOP", Numeric: "214", MinorUnits: 2, Name: "Dominican Peso", Symbol: "RD$"},
+	{Code: "DZD", Numeric: "012", MinorUnits: 2, Name: "Algerian Dinar", Symbol: "DA"},
+	{Code: "EEK", Numeric: "233", MinorUnits: 2, Name: "Kroon", Symbol: "kr", Withdrawn: "2011-01-01"},
+	{Code: "EGP", Numeric: "818", MinorUnits: 2, Name: "Egyptian Pound", Symbol: "E£"},
+	{Code: "ERN", Numeric: "232", MinorUnits: 2, Name: "Nakfa", Symbol: "Nfk"},
+	{Code: "ETB", Numeric: "230", MinorUnits: 2, Name: "Ethiopian Birr", Symbol: "Br"},
+	{Code: "EUR", Numeric: "978", MinorUnits: 2, Name: "Euro", Symbol: "€"},
+	{Code: "FJD", Numeric: "242", MinorUnits: 2, Name: "Fiji Dollar", Symbol: "FJ$"},
+	{Code: "FKP", Numeric: "238", MinorUnits: 2, Name: "Falkland Islands Pound", Symbol: "£"},
+	{Code: "GBP", Numeric: "826", MinorUnits: 2, Name: "Pound Sterling", Symbol: "£"},
+	{Code: "GEL", Numeric: "981", MinorUnits: 2, Name: "Lari", Symbol: "₾"},
+	{Code: "GHS", Numeric: "936", MinorUnits: 2, Name: "Ghana Cedi", Symbol: "GH₵"},
+	{Code: "GIP", Numeric: "292", MinorUnits: 2, Name: "Gibraltar Pound", Symbol: "£"},
+	{Code: "GMD", Numeric: "270", MinorUnits: 2, Name: "Dalasi", Symbol: "D"},
+	{Code: "GNF", Numeric: "324", MinorUnits: 0, Name: "Guinean Franc", Symbol: "FG"},
+	{Code: "GTQ", Numeric: "320", MinorUnits: 2, Name: "Quetzal", Symbol: "Q"},
+	{Code: "GYD", Numeric: "328", MinorUnits: 2, Name: "Guyana Dollar", Symbol: "G$"},
+	{Code: "HKD", Numeric: "344", MinorUnits: 2, Name: "Hong Kong Dollar", Symbol: "HK$"},
+	{Code: "HNL", Numeric: "340", MinorUnits: 2, Name: "Lempira", Symbol: "L"},
+	{Code: "HRK", Numeric: "191", MinorUnits: 2, Name: "Kuna", Symbol: "kn", Withdrawn: "2023-01-01"},
+	{Code: "HTG", Numeric: "332", MinorUnits: 2, Name: "Gourde", Symbol: "G"},
+	{Code: "HUF", Numeric: "348", MinorUnits: 2, Name: "Forint", Symbol: "Ft"},
+	{Code: "IDR", Numeric: "360", MinorUnits: 2, Name: "Rupiah", Symbol: "Rp"},
+	{Code: "ILS", Numeric: "376", MinorUnits: 2, Name: "New Israeli Sheqel", Symbol: "₪"},
+	{Code: "INR", Numeric: "356", MinorUnits: 2, Name: "Indian Rupee", Symbol: "₹"},
+	{Code: "IQD", Numeric: "368", MinorUnits: 3, Name: "Iraqi Dinar", Symbol: "ع.د"},
+	{Code: "IRR", Numeric: "364", MinorUnits: 2, Name: "Iranian Rial", Symbol: "﷼"},
+	{Code: "ISK", Numeric: "352", MinorUnits: 0, Name: "Iceland Krona", Symbol: "kr"},
+	{Code: "JMD", Numeric: "388", MinorUnits: 2, Name: "Jamaican Dollar", Symbol: "J$"},
+	{Code: "JOD", Numeric: "400", MinorUnits: 3, Name: "Jordanian Dinar", Symbol: "JD"},
+	{Code: "JPY", Numeric: "392", MinorUnits: 0, Name: "Yen", Symbol: "¥"},
+	{Code: "KES", Numeric: "404", MinorUnits: 2, Name: "Kenyan Shilling", Symbol: "KSh"},
+	{Code: "KGS", Numeric: "417", MinorUnits: 2, Name: "Som", Symbol: "с"},
+	{Code: "KHR", Numeric: "116", MinorUnits: 2, Name: "Riel", Symbol: "៛"},
+	{Code: "KMF", Numeric: "174", MinorUnits: 0, Name: "Comorian Franc", Symbol: "CF"},
+	{Code: "KPW", Numeric: "408", MinorUnits: 2, Name: "North Korean Won", Symbol: "₩"},
+	{Code: "KRW", Numeric: "410", MinorUnits: 0, Name: "Won", Symbol: "₩"},
+	{Code: "KWD", Numeric: "414", MinorUnits: 3, Name: "Kuwaiti Dinar", Symbol: "KD"},
+	{Code: "KYD", Numeric: "136", MinorUnits: 2, Name: "Cayman Islands Dollar", Symbol: "CI$"},
+	{Code: "KZT", Numeric: "398", MinorUnits: 2, Name: "Tenge", Symbol: "₸"},
+	{Code: "LAK", Numeric: "418", MinorUnits: 2, Name: "Lao Kip", Symbol: "₭"},
+	{Code: "LBP", Numeric: "422", MinorUnits: 2, Name: "Lebanese Pound", Symbol: "ل.ل"},
+	{Code: "LKR", Numeric: "144", MinorUnits: 2, Name: "Sri Lanka Rupee", Symbol: "Rs"},
+	{Code: "LRD", Numeric: "430", MinorUnits: 2, Name: "Liberian Dollar", Symbol: "L$"},
+	{Code: "LSL", Numeric: "426", MinorUnits: 2, Name: "Loti", Symbol: "L"},
+	{Code: "LTL", Numeric: "440", MinorUnits: 2, Name: "Lithuanian Litas", Symbol: "Lt", Withdrawn: "2015-01-01"},
+	{Code: "LVL", Numeric: "428", MinorUnits: 2, Name: "Latvian Lats", Symbol: "Ls", Withdrawn: "2014-01-01"},
+	{Code: "LYD", Numeric: "434", MinorUnits: 3, Name: "Libyan Dinar", Symbol: "LD"},
+	{Code: "MAD", Numeric: "504", MinorUnits: 2, Name: "Moroccan Dirham", Symbol: "DH"},
+	{Code: "MDL", Numeric: "498", MinorUnits: 2, Name: "Moldovan Leu", Symbol: "L"},
+	{Code: "MGA", Numeric: "969", MinorUnits: 2, Name: "Malagasy Ariary", Symbol: "Ar"},
+	{Code: "MKD", Numeric: "807", MinorUnits: 2, Name: "Denar", Symbol: "ден"},
+	{Code: "MMK", Numeric: "104", MinorUnits: 2, Name: "Kyat", Symbol: "K"},
+	{Code: "MNT", Numeric: "496", MinorUnits: 2, Name: "Tugrik", Symbol: "₮"},
+	{Code: "MOP", Numeric: "446", MinorUnits: 2, Name: "Pataca", Symbol: "MOP$"},
+	{Code: "MRU", Numeric: "929", MinorUnits: 2, Name: "Ouguiya", Symbol: "UM"},
+	{Code: "MTL", Numeric: "470", MinorUnits: 2, Name: "Maltese Lira", Symbol: "Lm", Withdrawn: "2008-01-01"},
+	{Code: "MUR", Numeric: "480", MinorUnits: 2, Name: "Mauritius Rupee", Symbol: "Rs"},
+	{Code: "MVR", Numeric: "462", MinorUnits: 2, Name: "Rufiyaa", Symbol: "Rf"},
+	{Code: "MWK", Numeric: "454", MinorUnits: 2, Name: "Malawi Kwacha", Symbol: "MK"},
+	{Code: "MXN", Numeric: "484", MinorUnits: 2, Name: "Mexican Peso", Symbol: "MX$"},
+	{Code: "MXV", Numeric: "979", MinorUnits: 2, Name: "Mexican Unidad de Inversion (UDI)"},
+	{Code: "MYR", Numeric: "458", MinorUnits: 2, Name: "Malaysian Ringgit", Symbol: "RM"},
+	{Code: "MZN", Numeric: "943", MinorUnits: 2, Name: "Mozambique Metical", Symbol: "MT"},
+	{Code: "NAD", Numeric: "516", MinorUnits: 2, Name: "Namibia Dollar", Symbol: "N$"},
+	{Code: "NGN", Numeric: "566", MinorUnits: 2, Name: "Naira", Symbol: "₦"},
+	{Code: "NIO", Numeric: "558", MinorUnits: 2, Name: "Cordoba Oro", Symbol: "C$"},
+	{Code: "NOK", Numeric: "578", MinorUnits: 2, Name: "Norwegian Krone", Symbol: "kr"},
+	{Code: "NPR", Numeric: "524", MinorUnits: 2, Name: "Nepalese Rupee", Symbol: "Rs"},
+	{Code: "NZD", Numeric: "554", MinorUnits: 2, Name: "New Zealand Dollar", Symbol: "NZ$"},
+	{Code: "OMR", Numeric: "512", MinorUnits: 3, Name: "Rial Omani", Symbol: "ر.ع."},
+	{Code: "PAB", Numeric: "590", MinorUnits: 2, Name: "Balboa", Symbol: "B/."},
+	{Code: "PEN", Numeric: "604", MinorUnits: 2, Name: "Sol", Symbol: "S/"},
+	{Code: "PGK", Numeric: "598", MinorUnits: 2, Name: "Kina", Symbol: "K"},
+	{Code: "PHP", Numeric: "608", MinorUnits: 2, Name: "Philippine Peso", Symbol: "₱"},
+	{Code: "PKR", Numeric: "586", MinorUnits: 2, Name: "Pakistan Rupee", Symbol: "Rs"},
+	{Code: "PLN", Numeric: "985", MinorUnits: 2, Name: "Zloty", Symbol: "zł"},
+	{Code: "PYG", Numeric: "600", MinorUnits: 0, Name: "Guarani", Symbol: "₲"},
+	{Code: "QAR", Numeric: "634", MinorUnits: 2, Name: "Qatari Rial", Symbol: "QR"},
+	{Code: "RON", Numeric: "946", MinorUnits: 2, Name: "Romanian Leu", Symbol: "lei"},
+	{Code: "RSD", Numeric: "941", MinorUnits: 2, Name: "Serbian Dinar", Symbol: "дин."},
+	{Code: "RUB", Numeric: "643", MinorUnits: 2, Name: "Russian Ruble", Symbol: "₽"},
+	{Code: "RWF", Numeric: "646", MinorUnits: 0, Name: "Rwanda Franc", Symbol: "FRw"},
+	{Code: "SAR", Numeric: "682", MinorUnits: 2, Name: "Saudi Riyal", Symbol: "SR"},
+	{Code: "SBD", Numeric: "090", MinorUnits: 2, Name: "Solomon Islands Dollar", Symbol: "SI$"},
+	{Code: "SCR", Numeric: "690", MinorUnits: 2, Name: "Seychelles Rupee", Symbol: "SR"},
+	{Code: "SDG", Numeric: "938", MinorUnits: 2, Name: "Sudanese Pound", Symbol: "LS"},
+	{Code: "SEK", Numeric: "752", MinorUnits: 2, Name: "Swedish Krona", Symbol: "kr"},
+	{Code: "SGD", Numeric: "702", MinorUnits: 2, Name: "Singapore Dollar", Symbol: "S$"},
+	{Code: "SHP", Numeric: "654", MinorUnits: 2, Name: "Saint Helena Pound", Symbol: "£"},
+	{Code: "SIT", Numeric: "705", MinorUnits: 2, Name: "Tolar", Symbol: "SIT", Withdrawn: "2007-01-01"},
+	{Code: "SKK", Numeric: "703", MinorUnits: 2, Name: "Slovak Koruna", Symbol: "Sk", Withdrawn: "2009-01-01"},
+	{Code: "SLE", Numeric: "925", MinorUnits: 2, Name: "Leone", Symbol: "Le"},
+	{Code: "SOS", Numeric: "706", MinorUnits: 2, Name: "Somali Shilling", Symbol: "Sh"},
+	{Code: "SRD", Numeric: "968", MinorUnits: 2, Name: "Surinam Dollar", Symbol: "$"},
+	{Code: "SSP", Numeric: "728", MinorUnits: 2, Name: "South Sudanese Pound", Symbol: "£"},
+	{Code: "STN", Numeric: "930", MinorUnits: 2, Name: "Dobra", Symbol: "Db"},
+	{Code: "SVC", Numeric: "222", MinorUnits: 2, Name: "El Salvador Colon", Symbol: "₡"},
+	{Code: "SYP", Numeric: "760", MinorUnits: 2, Name: "Syrian Pound", Symbol: "£S"},
+	{Code: "SZL", Numeric: "748", MinorUnits: 2, Name: "Lilangeni", Symbol: "E"},
+	{Code: "THB", Numeric: "764", MinorUnits: 2, Name: "Baht", Symbol: "฿"},
+	{Code: "TJS", Numeric: "972", MinorUnits: 2, Name: "Somoni", Symbol: "SM"},
+	{Code: "TMT", Numeric: "934", MinorUnits: 2, Name: "Turkmenistan New Manat", Symbol: "m"},
+	{Code: "TND", Numeric: "788", MinorUnits: 3, Name: "Tunisian Dinar", Symbol: "DT"},
+	{Code: "TOP", Numeric: "776", MinorUnits: 2, Name: "Pa'anga", Symbol: "T$"},
+	{Code: "TRY", Numeric: "949", MinorUnits: 2, Name: "Turkish Lira", Symbol: "₺"},
+	{Code: "TTD", Numeric: "780", MinorUnits: 2, Name: "Trinidad and Tobago Dollar", Symbol: "TT$"},
+	{Code: "TWD", Numeric: "901", MinorUnits: 2, Name: "New Taiwan Dollar", Symbol: "NT$"},
+	{Code: "TZS", Numeric: "834", MinorUnits: 2, Name: "Tanzanian Shilling", Symbol: "TSh"},
+	{Code: "UAH", Numeric: "980", MinorUnits: 2, Name: "Hryvnia", Symbol: "₴"},
+	{Code: "UGX", Numeric: "800", MinorUnits: 0, Name: "Uganda Shilling", Symbol: "USh"},
+	{Code: "USD", Numeric: "840", MinorUnits: 2, Name: "US Dollar", Symbol: "$"},
+	{Code: "USN", Numeric: "997", MinorUnits: 2, Name: "US Dollar (Next day)"},
+	{Code: "UYI", Numeric: "940", MinorUnits: 0, Name: "Uruguay Peso en Unidades Indexadas (UI)"},
+	{Code: "UYU", Numeric: "858", MinorUnits: 2, Name: "Peso Uruguayo", Symbol: "$U"},
+	{Code: "UYW", Numeric: "927", MinorUnits: 4, Name: "Unidad Previsional"},
+	{Code: "UZS", Numeric: "860", MinorUnits: 2, Name: "Uzbekistan Sum", Symbol: "so'm"},
+	{Code: "VED", Numeric: "926", MinorUnits: 2, Name: "Bolívar Soberano", Symbol: "Bs.D"},
+	{Code: "VES", Numeric: "928", MinorUnits: 2, Name: "Bolívar Soberano", Symbol: "Bs.S"},
+	{Code: "VND", Numeric: "704", MinorUnits: 0, Name: "Dong", Symbol: "₫"},
+	{Code: "VUV", Numeric: "548", MinorUnits: 0, Name: "Vatu", Symbol: "VT"},
+	{Code: "WST", Numeric: "882", MinorUnits: 2, Name: "Tala", Symbol: "WS$"},
+	{Code: "XAF", Numeric: "950", MinorUnits: 0, Name: "CFA Franc BEAC", Symbol: "FCFA"},
+	{Code: "XCD", Numeric: "951", MinorUnits: 2, Name: "East Caribbean Dollar", Symbol: "EC$"},
+	{Code: "XOF", Numeric: "952", MinorUnits: 0, Name: "CFA Franc BCEAO", Symbol: "CFA"},
+	{Code: "XPF", Numeric: "953", MinorUnits: 0, Name: "CFP Franc", Symbol: "₣"},
+	{Code: "YER", Numeric: "886", MinorUnits: 2, Name: "Yemeni Rial", Symbol: "﷼"},
+	{Code: "ZAR", Numeric: "710", MinorUnits: 2, Name: "Rand", Symbol: "R"},
+	{Code: "ZMW", Numeric: "967", MinorUnits: 2, Name: "Zambian Kwacha", Symbol: "ZK"},
+	{Code: "ZWG", Numeric: "924", MinorUnits: 2, Name: "Zimbabwe Gold", Symbol: "ZiG"},
+}
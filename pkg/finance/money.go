@@ -0,0 +1,20 @@
+package finance
+
+// defaultMinorUnits is the decimal places of the currencies not in the
+// catalog, as most ISO 4217 currencies have
+const defaultMinorUnits = 2
+
+// MinorUnits returns the ISO 4217 decimal places of the currency, as in 0
+// for JPY or 3 for KWD.
+func MinorUnits(currency string) int32 {
+	if currency, found := LookupCurrency(currency); found {
+		return currency.MinorUnits
+	}
+
+	return defaultMinorUnits
+}
+
+// RoundMoney rounds the amount to the decimal places of the currency.
+func RoundMoney(amount Decimal, currency string, mode RoundingMode) Decimal {
+	return amount.Round(MinorUnits(currency), mode)
+}
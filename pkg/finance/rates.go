@@ -0,0 +1,65 @@
+package finance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/renato0307/learning-go-lib/finance"
+)
+
+// maxConcurrentLookups bounds the rate lookups running at the same time for
+// one request
+const maxConcurrentLookups = 8
+
+// currencyPair is the source and target currencies of a conversion, with the
+// date of its historical rate, empty for the current one
+type currencyPair struct {
+	From string
+	To   string
+	Date string
+}
+
+// lookupRates gets the exchange rate of each distinct pair, so the
+// conversions of duplicate pairs share a single lookup.
+// Fails with the error of the first pair failing, in order.
+func lookupRates(
+	ctx context.Context,
+	f finance.Interface,
+	history RateStore,
+	pairs []currencyPair) (map[currencyPair]Rate, error) {
+
+	distinct := []currencyPair{}
+	seen := map[currencyPair]bool{}
+	for _, pair := range pairs {
+		if !seen[pair] {
+			seen[pair] = true
+			distinct = append(distinct, pair)
+		}
+	}
+
+	rates := make([]Rate, len(distinct))
+	errs := make([]error, len(distinct))
+	semaphore := make(chan struct{}, maxConcurrentLookups)
+	wg := sync.WaitGroup{}
+	for i, pair := range distinct {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, pair currencyPair) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			rates[i], errs[i] = pairRate(ctx, f, history, pair)
+		}(i, pair)
+	}
+	wg.Wait()
+
+	byPair := map[currencyPair]Rate{}
+	for i, pair := range distinct {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("%s to %s: %w", pair.From, pair.To, errs[i])
+		}
+		byPair[pair] = rates[i]
+	}
+
+	return byPair, nil
+}
@@ -0,0 +1,47 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	financelib "github.com/renato0307/learning-go-lib/finance"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupRates(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil).Once()
+	mockInterface.On("ConvertCurrency", "USD", "EUR", 1.0).Return(0.9, nil).Once()
+	pairs := []currencyPair{
+		{From: "EUR", To: "USD"},
+		{From: "USD", To: "EUR"},
+		{From: "EUR", To: "USD"},
+	}
+
+	// act
+	rates, err := lookupRates(context.Background(), &mockInterface, nil, pairs)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, rates, 2)
+	assert.Equal(t, 1.1, rates[currencyPair{From: "EUR", To: "USD"}].Value)
+	assert.Equal(t, 0.9, rates[currencyPair{From: "USD", To: "EUR"}].Value)
+	mockInterface.AssertExpectations(t)
+}
+
+func TestLookupRatesWithError(t *testing.T) {
+	// arrange
+	mockInterface := financelib.MockInterface{}
+	mockInterface.On("ConvertCurrency", "EUR", "USD", 1.0).Return(1.1, nil)
+	mockInterface.On("ConvertCurrency", "EUR", "XXX", 1.0).Return(0.0, errors.New("fake error"))
+	pairs := []currencyPair{{From: "EUR", To: "USD"}, {From: "EUR", To: "XXX"}}
+
+	// act
+	rates, err := lookupRates(context.Background(), &mockInterface, nil, pairs)
+
+	// assert
+	assert.EqualError(t, err, "EUR to XXX: fake error")
+	assert.Nil(t, rates)
+}
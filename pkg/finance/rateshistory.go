@@ -0,0 +1,115 @@
+package finance
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/logging"
+)
+
+// maxHistoryDays limits the days of one rates history request
+const maxHistoryDays = 366
+
+type getRatesHistoryOutput struct {
+	Base string           `json:"base"`
+	From string           `json:"from"`
+	To   string           `json:"to"`
+	Days []rateHistoryDay `json:"days"`
+}
+
+// rateHistoryDay holds the rates of one unit of the base on a day
+type rateHistoryDay struct {
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// getRatesHistory handles the request for the exchange rates of a base
+// currency on past days.
+//
+// The request requires the from and to dates in the query string, as in
+// "2022-02-04". Days without rates for the base, like weekends, are skipped.
+// It returns HTTP 200 on success.
+// Returns HTTP 400 listing the missing or invalid parameters, as an unknown
+// base currency.
+// Returns HTTP 500 if there is another error.
+func getRatesHistory(history RateStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		base := strings.ToUpper(c.Param("base"))
+		from := c.Query("from")
+		to := c.Query("to")
+
+		logger := logging.FromContext(c.Request.Context())
+		logger.Debug().
+			Str("base", base).
+			Str("from", from).
+			Str("to", to).
+			Msg("getting the exchange rates history")
+
+		first, last, fieldErrors := validateHistoryRange(from, to)
+		fieldErrors = append(fieldErrors, validateCurrency("base", base)...)
+		if len(fieldErrors) > 0 {
+			apierror.Respond(c, apierror.Invalid(c, fieldErrors...))
+			return
+		}
+
+		days, err := history.Days(first, last)
+		if err != nil {
+			msg := fmt.Sprintf("error getting the exchange rates history: %s", err.Error())
+			apierror.Respond(c, apierror.New(c, apierror.CodeInternal, msg))
+			return
+		}
+
+		output := getRatesHistoryOutput{Base: base, From: from, To: to, Days: []rateHistoryDay{}}
+		for _, day := range days {
+			rates := day.ratesOf(base)
+			if len(rates) == 0 {
+				continue
+			}
+			output.Days = append(output.Days, rateHistoryDay{
+				Date:  day.Date.Format(dateLayout),
+				Rates: rates,
+			})
+		}
+
+		c.JSON(http.StatusOK, output)
+	}
+}
+
+// validateHistoryRange checks the dates of a rates history request, returning
+// them parsed.
+func validateHistoryRange(from, to string) (time.Time, time.Time, []apierror.FieldError) {
+	fieldErrors := []apierror.FieldError{}
+	parse := func(field, value string) time.Time {
+		if value == "" {
+			fieldErrors = append(fieldErrors, apierror.RequiredField(field))
+			return time.Time{}
+		}
+
+		date, err := time.Parse(dateLayout, value)
+		if err != nil {
+			msg := fmt.Sprintf("error: '%s' is not a valid date, as in \"2022-02-04\"", field)
+			fieldErrors = append(fieldErrors, apierror.InvalidField(field, msg))
+		}
+		return date
+	}
+
+	first := parse("from", from)
+	last := parse("to", to)
+	if len(fieldErrors) > 0 {
+		return first, last, fieldErrors
+	}
+
+	if last.Before(first) {
+		msg := "error: 'to' must not be before 'from'"
+		fieldErrors = append(fieldErrors, apierror.InvalidField("to", msg))
+	} else if last.Sub(first) >= maxHistoryDays*24*time.Hour {
+		msg := fmt.Sprintf("error: the range must have at most %d days", maxHistoryDays)
+		fieldErrors = append(fieldErrors, apierror.InvalidField("to", msg))
+	}
+
+	return first, last, fieldErrors
+}
@@ -0,0 +1,72 @@
+package finance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	financelib "github.com/renato0307/learning-go-lib/finance"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRatesHistory(t *testing.T) {
+	// arrange
+	history := newTestHistory(t)
+	history.Save(parseDay("2022-02-03"), "EUR", map[string]float64{"USD": 1.0})
+	history.Save(parseDay("2022-02-03"), "GBP", map[string]float64{"JPY": 150})
+
+	r := setupGinWithHistory(&financelib.MockInterface{}, history)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/finance/rates/usd/history?from=2022-02-01&to=2022-02-06", nil)
+
+	// act
+	r.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	output := getRatesHistoryOutput{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &output))
+	assert.Equal(t, "USD", output.Base)
+	assert.Equal(t, "2022-02-01", output.From)
+	assert.Equal(t, "2022-02-06", output.To)
+	assert.Len(t, output.Days, 2)
+	assert.Equal(t, "2022-02-03", output.Days[0].Date)
+	assert.Equal(t, map[string]float64{"EUR": 1.0}, output.Days[0].Rates)
+	assert.Equal(t, "2022-02-04", output.Days[1].Date)
+	assert.InDelta(t, 1/1.1, output.Days[1].Rates["EUR"], 1e-9)
+	assert.InDelta(t, 130/1.1, output.Days[1].Rates["JPY"], 1e-9)
+}
+
+func TestGetRatesHistoryWithInvalidParameters(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Query string
+		Field string
+	}{
+		{Name: "missing from", Query: "to=2022-02-04", Field: "from"},
+		{Name: "missing to", Query: "from=2022-02-04", Field: "to"},
+		{Name: "invalid date", Query: "from=2022-02-30&to=2022-03-04", Field: "from"},
+		{Name: "reversed range", Query: "from=2022-02-04&to=2022-02-01", Field: "to"},
+		{Name: "range too long", Query: "from=2021-01-01&to=2022-02-04", Field: "to"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// arrange
+			r := setupGin(&financelib.MockInterface{})
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/v1/finance/rates/EUR/history?"+tc.Query, nil)
+
+			// act
+			r.ServeHTTP(w, req)
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			apierror.AssertIsProblem(t, w, apierror.CodeInvalidParameters)
+			assert.Contains(t, w.Body.String(), `"field":"`+tc.Field+`"`)
+		})
+	}
+}
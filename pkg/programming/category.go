@@ -0,0 +1,36 @@
+package programming
+
+import (
+	"github.com/gin-gonic/gin"
+	apiv1 "github.com/renato0307/learning-go-api/api/v1"
+	"github.com/renato0307/learning-go-api/internal/registry"
+	"github.com/renato0307/learning-go-lib/programming"
+	"google.golang.org/grpc"
+)
+
+func init() {
+	registry.Register(registry.Category{
+		Name:        "programming",
+		Description: "Functions for programmers, like UUID generation and JWT debugging",
+		Policies:    RoutePolicies,
+		Docs:        RouteDocs,
+		GrpcMethods: GrpcMethods,
+		Setup:       setup,
+	})
+}
+
+// setup creates the programming functions and defines their routes and gRPC
+// service.
+func setup(
+	config registry.Config,
+	base *gin.RouterGroup,
+	services grpc.ServiceRegistrar) (*gin.RouterGroup, registry.HealthCheck, error) {
+
+	p := programming.ProgrammingFunctions{}
+
+	if services != nil {
+		apiv1.RegisterProgrammingServiceServer(services, &grpcServer{p: &p})
+	}
+
+	return SetRouterGroup(&p, base), nil, nil
+}
@@ -0,0 +1,57 @@
+package programming
+
+import (
+	"context"
+	"fmt"
+
+	apiv1 "github.com/renato0307/learning-go-api/api/v1"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/grpcapi"
+	"github.com/renato0307/learning-go-api/internal/logging"
+	"github.com/renato0307/learning-go-api/internal/registry"
+	"github.com/renato0307/learning-go-lib/programming"
+)
+
+// GrpcMethods maps the ProgrammingService methods to the routes they mirror,
+// with paths relative to the router group
+var GrpcMethods = []registry.GrpcMethod{
+	{FullMethod: "/learninggoapi.v1.ProgrammingService/NewUuid", Method: "POST", Path: "/uuid"},
+	{FullMethod: "/learninggoapi.v1.ProgrammingService/DebugJwt", Method: "POST", Path: "/jwt"},
+}
+
+// grpcServer serves the programming functions over gRPC
+type grpcServer struct {
+	apiv1.UnimplementedProgrammingServiceServer
+
+	p programming.Interface
+}
+
+// NewUuid generates a UUID, like postUuid.
+func (s *grpcServer) NewUuid(
+	ctx context.Context,
+	req *apiv1.NewUuidRequest) (*apiv1.NewUuidResponse, error) {
+
+	logging.FromContext(ctx).Debug().
+		Bool("no-hyphens", req.NoHyphens).
+		Msg("running uuid generator")
+
+	return &apiv1.NewUuidResponse{Uuid: s.p.NewUuid(req.NoHyphens)}, nil
+}
+
+// DebugJwt decodes the token, like postJwtDebugger.
+//
+// Returns InvalidArgument if the token is not valid.
+func (s *grpcServer) DebugJwt(
+	ctx context.Context,
+	req *apiv1.DebugJwtRequest) (*apiv1.DebugJwtResponse, error) {
+
+	logging.FromContext(ctx).Debug().Msg("running jwt debugger")
+
+	header, payload, err := s.p.DebugJWT(req.Token)
+	if err != nil {
+		msg := fmt.Sprintf("invalid token: %s", err.Error())
+		return nil, grpcapi.Error(apierror.CodeInvalidBody, msg)
+	}
+
+	return &apiv1.DebugJwtResponse{Header: header, Payload: payload}, nil
+}
@@ -0,0 +1,48 @@
+package programming
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apiv1 "github.com/renato0307/learning-go-api/api/v1"
+	programminglib "github.com/renato0307/learning-go-lib/programming"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGrpcNewUuid(t *testing.T) {
+	// arrange
+	mockInterface := programminglib.MockInterface{}
+	mockInterface.On("NewUuid", true).Return("46ad9ba4f5fd4ea6aba6c5ea3dd4cd3c")
+	s := grpcServer{p: &mockInterface}
+
+	// act
+	resp, err := s.NewUuid(context.Background(), &apiv1.NewUuidRequest{NoHyphens: true})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "46ad9ba4f5fd4ea6aba6c5ea3dd4cd3c", resp.Uuid)
+	mockInterface.AssertExpectations(t)
+}
+
+func TestGrpcDebugJwt(t *testing.T) {
+	// arrange
+	mockInterface := programminglib.MockInterface{}
+	mockInterface.On("DebugJWT", "valid").Return(`{"alg":"HS256"}`, `{"sub":"1"}`, nil)
+	mockInterface.On("DebugJWT", "invalid").Return("", "", errors.New("malformed"))
+	s := grpcServer{p: &mockInterface}
+
+	// act
+	resp, err := s.DebugJwt(context.Background(), &apiv1.DebugJwtRequest{Token: "valid"})
+	_, invalidErr := s.DebugJwt(context.Background(), &apiv1.DebugJwtRequest{Token: "invalid"})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, `{"alg":"HS256"}`, resp.Header)
+	assert.Equal(t, `{"sub":"1"}`, resp.Payload)
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(invalidErr))
+	assert.Equal(t, "invalid token: malformed", status.Convert(invalidErr).Message())
+}
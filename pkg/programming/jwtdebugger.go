@@ -7,8 +7,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/logging"
 	"github.com/renato0307/learning-go-lib/programming"
-	"github.com/rs/zerolog/log"
 )
 
 // postJwtDebuggerOutput is the output of the "POST /programming/jwt" action
@@ -23,13 +23,14 @@ type postJwtDebuggerOutput struct {
 // Returns HTTP 400 if the token is not valid.
 func postJwtDebugger(p programming.Interface) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		logger := logging.FromContext(c.Request.Context())
 
-		log.Debug().Msg("running jwt debugger")
+		logger.Debug().Msg("running jwt debugger")
 
 		tokenBytes, err := ioutil.ReadAll(c.Request.Body)
 		if err != nil {
 			msg := "error reading body"
-			c.JSON(http.StatusBadRequest, apierror.New(msg))
+			apierror.Respond(c, apierror.New(c, apierror.CodeInvalidBody, msg))
 			return
 		}
 
@@ -37,7 +38,7 @@ func postJwtDebugger(p programming.Interface) gin.HandlerFunc {
 		header, payload, err := p.DebugJWT(tokenString)
 		if err != nil {
 			msg := fmt.Sprintf("invalid token: %s", err.Error())
-			c.JSON(http.StatusBadRequest, apierror.New(msg))
+			apierror.Respond(c, apierror.New(c, apierror.CodeInvalidBody, msg))
 			return
 		}
 
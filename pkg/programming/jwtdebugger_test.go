@@ -64,5 +64,5 @@ func TestPostJwtDebugWithInvalidToken(t *testing.T) {
 	assert.Contains(t, w.Body.String(), err.Error())
 
 	mockInterface.AssertExpectations(t)
-	apierror.AssertIsValid(t, w.Body.Bytes())
+	apierror.AssertIsProblem(t, w, apierror.CodeInvalidBody)
 }
@@ -2,10 +2,46 @@ package programming
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/apierror"
+	"github.com/renato0307/learning-go-api/internal/middleware"
+	"github.com/renato0307/learning-go-api/internal/openapi"
 	"github.com/renato0307/learning-go-lib/programming"
 	"github.com/rs/zerolog/log"
 )
 
+// RoutePolicies defines the scopes required by the programming functions, with
+// paths relative to the router group
+var RoutePolicies = []middleware.RoutePolicy{
+	{Method: "POST", Path: "/uuid", Scopes: []string{"programming-uuid"}},
+	{Method: "POST", Path: "/jwt", Scopes: []string{"programming-jwt"}},
+}
+
+// RouteDocs documents the programming functions in the OpenAPI document, with
+// paths relative to the router group
+var RouteDocs = []openapi.Route{
+	{
+		Method:  "POST",
+		Path:    "/uuid",
+		Summary: "Generates a random UUID",
+		Tags:    []string{"programming"},
+		Parameters: []openapi.Parameter{
+			openapi.QueryParameter("no-hyphens",
+				openapi.Boolean("Removes the hyphens from the UUID"), false),
+		},
+		Output: postUuidOutput{},
+	},
+	{
+		Method:      "POST",
+		Path:        "/jwt",
+		Summary:     "Decodes a JWT",
+		Description: "Returns the header and the payload of the token, without validating its signature.",
+		Tags:        []string{"programming"},
+		RequestBody: openapi.TextBody("The encoded JWT"),
+		Output:      postJwtDebuggerOutput{},
+		Errors:      []apierror.Code{apierror.CodeInvalidBody},
+	},
+}
+
 // SetRouterGroup defines all the routes for the programming functions
 func SetRouterGroup(p programming.Interface, base *gin.RouterGroup) *gin.RouterGroup {
 	log.Debug().Msg("setting router group for: programming")
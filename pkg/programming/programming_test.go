@@ -1,8 +1,15 @@
 package programming
 
 import (
+	"os"
+	"testing"
+
 	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/openapi"
+	"github.com/renato0307/learning-go-api/internal/registry"
 	programminglib "github.com/renato0307/learning-go-lib/programming"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 )
 
 func setupGin(mockInterface *programminglib.MockInterface) *gin.Engine {
@@ -12,3 +19,33 @@ func setupGin(mockInterface *programminglib.MockInterface) *gin.Engine {
 
 	return r
 }
+
+func TestRouteDocs(t *testing.T) {
+	// arrange
+	r := gin.New()
+	pg := SetRouterGroup(&programminglib.MockInterface{}, r.Group("/v1"))
+	doc := openapi.NewDocument(openapi.Info{Title: "test", Version: "1"})
+
+	// act
+	doc.Register(pg.BasePath(), RouteDocs...)
+
+	// assert
+	assert.NoError(t, doc.CheckRoutes(r.Routes()))
+}
+
+func TestCategory(t *testing.T) {
+	// arrange
+	categories, err := registry.Enabled("programming")
+	assert.NoError(t, err)
+	r := gin.New()
+	services := grpc.NewServer()
+
+	// act
+	mounted, err := registry.Mount(categories, r.Group("/v1"), services, os.LookupEnv)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "/v1/programming", mounted[0].Group.BasePath())
+	assert.Nil(t, mounted[0].HealthCheck)
+	assert.Contains(t, services.GetServiceInfo(), "learninggoapi.v1.ProgrammingService")
+}
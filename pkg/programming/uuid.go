@@ -4,8 +4,8 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/renato0307/learning-go-api/internal/logging"
 	"github.com/renato0307/learning-go-lib/programming"
-	"github.com/rs/zerolog/log"
 )
 
 // postUuidOutput is the output of the "POST /programming/uuid" action
@@ -21,10 +21,12 @@ type postUuidOutput struct {
 // It returns HTTP 200 on success.
 func postUuid(p programming.Interface) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		logger := logging.FromContext(c.Request.Context())
+
 		noHyphensParamValue := c.Query("no-hyphens")
 		withoutHyphens := noHyphensParamValue == "true"
 
-		log.Debug().
+		logger.Debug().
 			Str("no-hyphens", noHyphensParamValue).
 			Msg("running uuid generator")
 